@@ -16,96 +16,344 @@ package main
 
 import (
 	"context"
-	"io"
+	"encoding/json"
+	"fmt"
 	"log"
-	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	grpccredentials "google.golang.org/grpc/credentials"
 
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/config"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/peer"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/prefetch"
+	cacherepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/cache"
+	grpcrepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/grpc"
+	storerepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/store"
+	"github.com/sigrpc/sigrpcd/pkg/infra/admin"
+	pagecache "github.com/sigrpc/sigrpcd/pkg/infra/cache/x64"
+	configloader "github.com/sigrpc/sigrpcd/pkg/infra/config"
+	"github.com/sigrpc/sigrpcd/pkg/infra/credentials"
 	grpcclient "github.com/sigrpc/sigrpcd/pkg/infra/grpc/x64"
+	"github.com/sigrpc/sigrpcd/pkg/infra/listener"
+	x64mapping "github.com/sigrpc/sigrpcd/pkg/infra/mapping/x64"
+	x64memory "github.com/sigrpc/sigrpcd/pkg/infra/memory/x64"
 	"github.com/sigrpc/sigrpcd/pkg/infra/msg/x64"
+	pagecodec "github.com/sigrpc/sigrpcd/pkg/infra/page/x64"
+	peercredentials "github.com/sigrpc/sigrpcd/pkg/infra/peer"
+	x64prefetch "github.com/sigrpc/sigrpcd/pkg/infra/prefetch/x64"
+	profilewriter "github.com/sigrpc/sigrpcd/pkg/infra/profile"
+	x64profile "github.com/sigrpc/sigrpcd/pkg/infra/profile/x64"
+	"github.com/sigrpc/sigrpcd/pkg/infra/shm"
+	"github.com/sigrpc/sigrpcd/pkg/infra/store"
 	"github.com/sigrpc/sigrpcd/pkg/usecase"
 )
 
-func run(sock net.Listener, cc *grpc.ClientConn) {
-	codec, err := x64.NewX64MsgCodec()
-	if err != nil {
-		log.Println(err)
-		return
+func stubCredentials(conf config.TLS) (grpccredentials.TransportCredentials, error) {
+	return credentials.NewStubCredentials(credentials.TLSConfig{
+		Enabled:    conf.Enabled,
+		CAFile:     conf.CAFile,
+		CertFile:   conf.CertFile,
+		KeyFile:    conf.KeyFile,
+		ServerName: conf.ServerName,
+	})
+}
+
+func listenerName(l config.Listener) string {
+	if len(l.Name) > 0 {
+		return l.Name
 	}
-	for {
-		conn, err := sock.Accept()
-		if err != nil {
-			log.Println(err)
+	return listener.Name(l.Network, l.Addr)
+}
+
+/* socket-activated listeners are served even when they are not configured */
+func withActivatedListeners(listeners []config.Listener, activated []*listener.Named) []config.Listener {
+	merged := make([]config.Listener, 0, len(listeners)+len(activated))
+	for _, l := range listeners {
+		if len(l.Name) == 0 && len(l.Addr) == 0 {
 			continue
 		}
-		go func() {
-			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-			defer conn.Close()
-			defer cancel()
-			grpcClient := grpcclient.NewClient(cc, ctx)
-			sigRPCClient := usecase.NewGRPCClient(grpcClient, codec)
-		read_next:
-			resp, err := sigRPCClient.InvokeRPC(conn)
-			if err != nil && err != io.EOF {
-				log.Println(err)
-				return
-			}
-			_, err = conn.Write(resp)
-			if err != nil {
-				return
-			}
-			if sigRPCClient.IsStreaming() {
-				goto read_next
+		merged = append(merged, l)
+	}
+	matched := make([]bool, len(merged))
+	for _, sock := range activated {
+		configured := false
+		for i, l := range merged[:len(matched)] {
+			if !matched[i] && sock.Matches(listenerName(l), l.Network, l.Addr) {
+				matched[i] = true
+				configured = true
+				break
 			}
-		}()
+		}
+		if configured {
+			continue
+		}
+		merged = append(merged, config.Listener{
+			Name:    sock.Name,
+			Network: sock.Addr().Network(),
+		})
 	}
+	return merged
 }
 
-func main() {
-	clientAddr := os.Getenv("RPC_CLIENT_ADDR")
-	if len(clientAddr) == 0 {
-		log.Println("RPC_CLIENT_ADDR is empty")
+func logStats(pageCache *usecase.PageCache, contentStore storerepository.Content, prefetcher *usecase.Prefetcher) {
+	if pageCache != nil {
+		log.Println("page cache:", pageCache.Stats())
+	}
+	if contentStore != nil {
+		log.Println("content store:", contentStore.Stats())
+	}
+	if prefetcher != nil {
+		log.Println("prefetch:", prefetcher.Stats())
+	}
+}
+
+func writeProfile(profiler *usecase.Profiler, path string) {
+	if profiler == nil || len(path) == 0 {
 		return
 	}
-	clientNetwork := os.Getenv("RPC_CLIENT_NETWORK")
-	if len(clientNetwork) == 0 {
-		log.Println("RPC_CLIENT_NETWORK is empty")
-		clientNetwork = "unix"
+	if err := profilewriter.WriteFile(path, profiler.Snapshot()); err != nil {
+		log.Println(err)
 	}
-	if _, err := os.Stat(clientAddr); err == nil {
-		if err := os.RemoveAll(clientAddr); err != nil {
+}
+
+/* the profile is still written when the admin endpoint cannot be served */
+func serveAdmin(profiler *usecase.Profiler, sock *listener.Named) *http.Server {
+	adminServer := &http.Server{
+		Handler: admin.NewHandler(profiler.Snapshot),
+	}
+	go func() {
+		if err := adminServer.Serve(sock); err != nil && err != http.ErrServerClosed {
 			log.Println(err)
-			return
 		}
+	}()
+	return adminServer
+}
+
+func checkConfig(args []string) {
+	conf, err := configloader.Load("sigrpcd check-config", args)
+	if err != nil {
+		log.Println(err)
+		os.Exit(2)
+	}
+	byteConf, err := json.MarshalIndent(conf, "", "    ")
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
 	}
-	addr := os.Getenv("RPC_STUB_ADDR")
-	if len(addr) == 0 {
-		log.Println("RPC_STUB_ADDR is empty")
+	fmt.Println(string(byteConf))
+	if err := configloader.Validate(conf); err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "check-config" {
+		checkConfig(os.Args[2:])
 		return
 	}
-	sock, err := net.Listen(clientNetwork, clientAddr)
+	conf, err := configloader.Load("sigrpcd", os.Args[1:])
+	if err != nil {
+		log.Println(err)
+		os.Exit(2)
+	}
+	inherited, err := listener.Inherited()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	activated, err := listener.Activated()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if len(activated) > 0 {
+		conf.Listeners = withActivatedListeners(conf.Listeners, activated)
+	}
+	if err := configloader.Validate(conf); err != nil {
+		log.Println(err)
+		return
+	}
+	if err := configloader.SetupLog(conf.Log); err != nil {
+		log.Println(err)
+		return
+	}
+	available := append(inherited, activated...)
+	socks := make([]*listener.Named, 0, len(conf.Listeners))
+	for _, l := range conf.Listeners {
+		name := listenerName(l)
+		sock := listener.Take(&available, name, l.Network, l.Addr)
+		if sock == nil {
+			if len(l.Addr) == 0 {
+				log.Println(name, "is not socket-activated")
+				return
+			}
+			sock, err = listener.Listen(name, l.Network, l.Addr, listener.Permissions{
+				Mode:  l.Mode,
+				Owner: l.Owner,
+				Group: l.Group,
+			})
+			if err != nil {
+				log.Println(err)
+				return
+			}
+		}
+		/* closing removes the socket path unless systemd or a new sigrpcd owns it */
+		defer sock.Close()
+		socks = append(socks, sock)
+	}
+	/* the admin socket is handed over on upgrade too so that closing it leaves the path to the new process */
+	handover := socks[:len(socks):len(socks)]
+	var adminSock *listener.Named
+	if conf.Profile.Enabled && len(conf.Profile.AdminAddr) > 0 {
+		adminSock, err = admin.Listen(&available, conf.Profile.AdminNetwork, conf.Profile.AdminAddr)
+		if err != nil {
+			log.Println(err)
+		} else {
+			defer adminSock.Close()
+			handover = append(handover, adminSock)
+		}
+	}
+	for _, sock := range available {
+		log.Println(sock.Name, "is no longer configured")
+		sock.Close()
+	}
+	creds, err := stubCredentials(conf.Stub.TLS)
 	if err != nil {
 		log.Println(err)
 		return
 	}
-	defer sock.Close()
 	cc, err := grpc.NewClient(
-		addr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithDefaultCallOptions(grpc.MaxRecvMsgSizeCallOption{MaxRecvMsgSize: 0x7ffffffff}),
-		grpc.WithDefaultCallOptions(grpc.MaxSendMsgSizeCallOption{MaxSendMsgSize: 0x7fffffff}))
+		conf.Stub.Addr,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.MaxRecvMsgSizeCallOption{MaxRecvMsgSize: conf.Stub.MaxRecvMsgSize}),
+		grpc.WithDefaultCallOptions(grpc.MaxSendMsgSizeCallOption{MaxSendMsgSize: conf.Stub.MaxSendMsgSize}))
 	if err != nil {
 		log.Println(err)
 		return
 	}
 	defer cc.Close()
-	run(sock, cc)
-	if err := os.RemoveAll(clientAddr); err != nil {
+	codec, err := x64.NewX64MsgCodec(conf.Limits)
+	if err != nil {
 		log.Println(err)
 		return
 	}
+	var contentStore storerepository.Content
+	if conf.ContentStore.Enabled {
+		contentStore, err = store.NewContentStore(conf.ContentStore)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		defer contentStore.Close()
+	}
+	var pageCache *usecase.PageCache
+	if conf.PageCache.Enabled {
+		pageCache = usecase.NewPageCache(pagecache.NewPageCache(conf.PageCache, pagecodec.NewXORRLEDelta()))
+	}
+	var prefetcher *usecase.Prefetcher
+	if conf.Prefetch.Policy != prefetch.POLICYNONE {
+		prefetcher = usecase.NewPrefetcher(x64prefetch.NewPrefetcher(conf.Prefetch, uint64(os.Getpagesize())))
+	}
+	var profiler *usecase.Profiler
+	var profileTicks <-chan time.Time
+	if conf.Profile.Enabled {
+		profiler = usecase.NewProfiler(x64profile.NewProfiler(conf.Profile.MaxFunctions, conf.Profile.MaxPagesPerFunction))
+		if adminSock != nil {
+			defer serveAdmin(profiler, adminSock).Close()
+		}
+		if len(conf.Profile.File) > 0 {
+			ticker := time.NewTicker(time.Duration(conf.Profile.Interval))
+			defer ticker.Stop()
+			profileTicks = ticker.C
+		}
+	}
+	var mappings *usecase.Mappings
+	if conf.Stub.PageMappings {
+		mappings = usecase.NewMappings(x64mapping.NewMappings())
+	}
+	var memory *usecase.Memory
+	if conf.DirectMemory.Enabled {
+		memory = usecase.NewMemory(x64memory.NewMemory(conf.DirectMemory, uint64(os.Getpagesize()), conf.Limits))
+	}
+	var sharedMemory *usecase.SharedMemory
+	if conf.SharedMemory.Enabled {
+		sharedMemory = usecase.NewSharedMemory(shm.NewSharedMemory(conf.Limits))
+	}
+	var bases cacherepository.Bases
+	if pageCache != nil && conf.PageCache.Deltas {
+		bases = pageCache
+	}
+	server := usecase.NewServer(
+		func(ctx context.Context, peer *peer.Peer) grpcrepository.GRPCClient {
+			if bases != nil {
+				ctx = grpcclient.WithDeltaPages(ctx)
+			}
+			return grpcclient.NewClient(cc, ctx, peer, grpcclient.Options{
+				Limits:      conf.Limits,
+				PageElision: conf.Stub.PageElision,
+				Store:       contentStore,
+				Bases:       bases,
+			})
+		},
+		peercredentials.NewCredentials(),
+		conf.Auth,
+		pageCache,
+		prefetcher,
+		profiler,
+		mappings,
+		memory,
+		sharedMemory,
+		codec,
+		conf.Limits,
+		time.Duration(conf.Timeout.Request))
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGINT, syscall.SIGUSR1, syscall.SIGUSR2)
+	for _, sock := range socks {
+		go func() {
+			if err := server.Serve(sock); err != nil {
+				log.Println(err)
+			}
+		}()
+	}
+	if err := listener.NotifyReady(); err != nil {
+		log.Println(err)
+	}
+	handedOver := false
+	for !handedOver {
+		var sig os.Signal
+		select {
+		case sig = <-sigs:
+		case <-profileTicks:
+			writeProfile(profiler, conf.Profile.File)
+			continue
+		}
+		if sig == syscall.SIGUSR1 {
+			logStats(pageCache, contentStore, prefetcher)
+			continue
+		}
+		if sig != syscall.SIGUSR2 {
+			break
+		}
+		log.Println("upgrading")
+		if err := listener.Upgrade(handover, time.Duration(conf.Timeout.Upgrade)); err != nil {
+			log.Println(err)
+			continue
+		}
+		handedOver = true
+	}
+	signal.Stop(sigs)
+	log.Println("shutting down")
+	graceCtx, cancel := context.WithTimeout(context.Background(), time.Duration(conf.Timeout.ShutdownGrace))
+	defer cancel()
+	if err := server.Shutdown(graceCtx); err != nil {
+		log.Println(err)
+	}
+	logStats(pageCache, contentStore, prefetcher)
+	writeProfile(profiler, conf.Profile.File)
 }
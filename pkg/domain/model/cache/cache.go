@@ -0,0 +1,51 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import "fmt"
+
+type Config struct {
+	Enabled           bool   `json:"enabled"`
+	MaxClients        uint64 `json:"max_clients"`
+	MaxBytesPerClient uint64 `json:"max_bytes_per_client"`
+	MaxBytes          uint64 `json:"max_bytes"`
+	/* pages are exchanged with the stub as deltas against revisions held here */
+	Deltas bool `json:"deltas"`
+}
+
+func NewDefaultConfig() Config {
+	return Config{
+		Enabled:           false,
+		MaxClients:        64,
+		MaxBytesPerClient: 0x1000000,
+		MaxBytes:          0x10000000,
+		Deltas:            false,
+	}
+}
+
+type Stats struct {
+	Hits          uint64
+	Misses        uint64
+	Evictions     uint64
+	Invalidations uint64
+	Clients       uint64
+	Pages         uint64
+	Bytes         uint64
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("hits=%d misses=%d evictions=%d invalidations=%d clients=%d pages=%d bytes=%d",
+		s.Hits, s.Misses, s.Evictions, s.Invalidations, s.Clients, s.Pages, s.Bytes)
+}
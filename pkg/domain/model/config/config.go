@@ -0,0 +1,150 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/cache"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/memory"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/peer"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/prefetch"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/store"
+)
+
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(str)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+type Listener struct {
+	Name    string `json:"name,omitempty"`
+	Network string `json:"network"`
+	Addr    string `json:"addr"`
+	Mode    string `json:"mode,omitempty"`
+	Owner   string `json:"owner,omitempty"`
+	Group   string `json:"group,omitempty"`
+}
+
+type TLS struct {
+	Enabled    bool   `json:"enabled"`
+	CAFile     string `json:"ca_file"`
+	CertFile   string `json:"cert_file"`
+	KeyFile    string `json:"key_file"`
+	ServerName string `json:"server_name"`
+}
+
+type Stub struct {
+	Addr           string `json:"addr"`
+	MaxRecvMsgSize int    `json:"max_recv_msg_size"`
+	MaxSendMsgSize int    `json:"max_send_msg_size"`
+	PageElision    bool   `json:"page_elision"`
+	PageMappings   bool   `json:"page_mappings"`
+	TLS            TLS    `json:"tls"`
+}
+
+type Timeout struct {
+	Request       Duration `json:"request"`
+	ShutdownGrace Duration `json:"shutdown_grace"`
+	Upgrade       Duration `json:"upgrade"`
+}
+
+/*
+ * working sets per function, exported to a file and an admin endpoint
+ * that is unauthenticated and so only served to the owner or on loopback
+ */
+type Profile struct {
+	Enabled             bool     `json:"enabled"`
+	File                string   `json:"file"`
+	Interval            Duration `json:"interval"`
+	AdminNetwork        string   `json:"admin_network"`
+	AdminAddr           string   `json:"admin_addr"`
+	MaxFunctions        uint64   `json:"max_functions"`
+	MaxPagesPerFunction uint64   `json:"max_pages_per_function"`
+}
+
+/* page contents of unix socket clients go through a memfd they pass with HELLO */
+type SharedMemory struct {
+	Enabled bool `json:"enabled"`
+}
+
+type Log struct {
+	File  string `json:"file"`
+	Flags string `json:"flags"`
+}
+
+type Config struct {
+	Listeners    []Listener      `json:"listeners"`
+	Stub         Stub            `json:"stub"`
+	Timeout      Timeout         `json:"timeout"`
+	Limits       limit.Limits    `json:"limits"`
+	Auth         peer.Policy     `json:"auth"`
+	PageCache    cache.Config    `json:"page_cache"`
+	ContentStore store.Config    `json:"content_store"`
+	Prefetch     prefetch.Config `json:"prefetch"`
+	Profile      Profile         `json:"profile"`
+	DirectMemory memory.Config   `json:"direct_memory"`
+	SharedMemory SharedMemory    `json:"shared_memory"`
+	Log          Log             `json:"log"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Listeners: []Listener{
+			{
+				Network: "unix",
+			},
+		},
+		Stub: Stub{
+			MaxRecvMsgSize: 0x7ffffffff,
+			MaxSendMsgSize: 0x7fffffff,
+		},
+		Timeout: Timeout{
+			Request:       Duration(30 * time.Second),
+			ShutdownGrace: Duration(10 * time.Second),
+			Upgrade:       Duration(30 * time.Second),
+		},
+		Limits:       limit.NewDefaultLimits(),
+		PageCache:    cache.NewDefaultConfig(),
+		ContentStore: store.NewDefaultConfig(),
+		Prefetch:     prefetch.NewDefaultConfig(),
+		Profile: Profile{
+			Interval:            Duration(time.Minute),
+			AdminNetwork:        "unix",
+			MaxFunctions:        1024,
+			MaxPagesPerFunction: 4096,
+		},
+		DirectMemory: memory.NewDefaultConfig(),
+		Log: Log{
+			Flags: "date,time",
+		},
+	}
+}
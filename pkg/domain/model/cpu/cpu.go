@@ -42,6 +42,12 @@ const (
 	CR2
 )
 
+/* XSAVE area of a signal frame, the FXSAVE area and the xstate header included */
+const (
+	XSTATEMINSIZE = 512 + 64
+	XSTATEMAXSIZE = 0x10000
+)
+
 type CPU struct {
 	X64 *x64.CPUState
 }
@@ -0,0 +1,85 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package limit
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+var ErrMemoryExhausted = errors.New("memory limit exceeded")
+
+/*
+ * memory held for decoded messages and what they expand to.
+ * A reservation is charged to the budget and all of its parents,
+ * a message to its connection and a connection to the daemon.
+ * A nil budget charges nothing.
+ */
+type Budget struct {
+	max    uint64
+	used   atomic.Uint64
+	parent *Budget
+}
+
+func NewBudget(max uint64, parent *Budget) *Budget {
+	return &Budget{
+		max:    max,
+		parent: parent,
+	}
+}
+
+func (b *Budget) Reserve(size uint64) error {
+	if b == nil {
+		return nil
+	}
+	for {
+		used := b.used.Load()
+		if used+size < used || used+size > b.max {
+			return fmt.Errorf("%w: %d bytes more than %d of %d", ErrMemoryExhausted, size, used, b.max)
+		}
+		if b.used.CompareAndSwap(used, used+size) {
+			break
+		}
+	}
+	if err := b.parent.Reserve(size); err != nil {
+		b.used.Add(-size)
+		return err
+	}
+	return nil
+}
+
+func (b *Budget) Release(size uint64) {
+	if b == nil {
+		return
+	}
+	b.used.Add(-size)
+	b.parent.Release(size)
+}
+
+func (b *Budget) Used() uint64 {
+	if b == nil {
+		return 0
+	}
+	return b.used.Load()
+}
+
+/* gives everything reserved back to the parents at once */
+func (b *Budget) Close() {
+	if b == nil {
+		return
+	}
+	b.parent.Release(b.used.Swap(0))
+}
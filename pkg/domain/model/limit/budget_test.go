@@ -0,0 +1,78 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package limit
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBudgetChargesParents(t *testing.T) {
+	daemon := NewBudget(100, nil)
+	conn := NewBudget(60, daemon)
+	frame := NewBudget(40, conn)
+
+	if err := frame.Reserve(30); err != nil {
+		t.Fatal(err)
+	}
+	if frame.Used() != 30 || conn.Used() != 30 || daemon.Used() != 30 {
+		t.Fatalf("used %d/%d/%d, want 30 everywhere", frame.Used(), conn.Used(), daemon.Used())
+	}
+	if err := frame.Reserve(20); !errors.Is(err, ErrMemoryExhausted) {
+		t.Fatalf("reserve beyond the frame budget: %v", err)
+	}
+	if err := conn.Reserve(40); !errors.Is(err, ErrMemoryExhausted) {
+		t.Fatalf("reserve beyond the connection budget: %v", err)
+	}
+	frame.Release(10)
+	if frame.Used() != 20 || conn.Used() != 20 || daemon.Used() != 20 {
+		t.Fatalf("used %d/%d/%d after release, want 20 everywhere", frame.Used(), conn.Used(), daemon.Used())
+	}
+	frame.Close()
+	if frame.Used() != 0 || conn.Used() != 0 || daemon.Used() != 0 {
+		t.Fatalf("used %d/%d/%d after close, want 0 everywhere", frame.Used(), conn.Used(), daemon.Used())
+	}
+}
+
+func TestBudgetRollsBackWhenParentIsExhausted(t *testing.T) {
+	daemon := NewBudget(50, nil)
+	first := NewBudget(40, daemon)
+	second := NewBudget(40, daemon)
+
+	if err := first.Reserve(40); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.Reserve(20); !errors.Is(err, ErrMemoryExhausted) {
+		t.Fatalf("reserve beyond the daemon budget: %v", err)
+	}
+	if second.Used() != 0 || daemon.Used() != 40 {
+		t.Fatalf("used %d/%d after a failed reserve, want 0/40", second.Used(), daemon.Used())
+	}
+	if err := second.Reserve(^uint64(0)); !errors.Is(err, ErrMemoryExhausted) {
+		t.Fatalf("overflowing reserve: %v", err)
+	}
+}
+
+func TestNilBudget(t *testing.T) {
+	var budget *Budget
+	if err := budget.Reserve(^uint64(0)); err != nil {
+		t.Fatal(err)
+	}
+	budget.Release(1)
+	budget.Close()
+	if budget.Used() != 0 {
+		t.Fatalf("nil budget used %d", budget.Used())
+	}
+}
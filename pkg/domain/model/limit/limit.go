@@ -0,0 +1,40 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package limit
+
+type Limits struct {
+	MaxFrameSize       uint64 `json:"max_frame_size"`
+	MaxPageCount       uint64 `json:"max_page_count"`
+	MaxPageContentSize uint32 `json:"max_page_content_size"`
+	MaxLibraryNameSize uint64 `json:"max_library_name_size"`
+	MaxConnMemory      uint64 `json:"max_conn_memory"`
+	MaxMemory          uint64 `json:"max_memory"`
+}
+
+/*
+ * a frame decodes to at most MaxFrameSize bytes whatever it expands to,
+ * a connection holds its frame, the reply and its shared memory in MaxConnMemory
+ * and all connections together hold MaxMemory.
+ */
+func NewDefaultLimits() Limits {
+	return Limits{
+		MaxFrameSize:       0x10000000,
+		MaxPageCount:       0x100000,
+		MaxPageContentSize: 0x200000,
+		MaxLibraryNameSize: 4096,
+		MaxConnMemory:      0x40000000,
+		MaxMemory:          0x100000000,
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapping
+
+import "github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+
+/* protection of Mapping */
+const (
+	PROTREAD uint32 = 1 << iota
+	PROTWRITE
+	PROTEXEC
+)
+
+/* a line of /proc/<pid>/maps, Offset is that of Start in the file */
+type Region struct {
+	Start uint64
+	End   uint64
+	X64   *x64.Mapping
+}
@@ -0,0 +1,35 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import "github.com/sigrpc/sigrpcd/pkg/domain/model/peer"
+
+/*
+ * the daemon answers page faults of the stub and writes pages back
+ * by accessing the memory of clients allowed here and traceable by the daemon
+ */
+type Config struct {
+	Enabled bool        `json:"enabled"`
+	Allow   peer.Policy `json:"allow"`
+	/* replies with faults answered within one invocation */
+	MaxFaultRounds uint64 `json:"max_fault_rounds"`
+}
+
+func NewDefaultConfig() Config {
+	return Config{
+		Enabled:        false,
+		MaxFaultRounds: 4096,
+	}
+}
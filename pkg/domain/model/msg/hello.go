@@ -0,0 +1,52 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import "github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+
+const (
+	MINPROTOCOLVERSION uint32 = 1
+	PROTOCOLVERSION    uint32 = 1
+)
+
+const (
+	ARCHX64 uint32 = iota + 1
+)
+
+const (
+	FEATUREERRORREPLY uint64 = 1 << iota
+	FEATUREPAGEELISION
+	FEATUREPAGERANGES
+	FEATUREDIRTYRANGES
+	FEATUREDIRECTMEMORY
+	FEATURESHAREDMEMORY
+	FEATUREXSAVE
+	FEATUREPREFETCH
+	FEATUREMAPPINGS
+)
+
+const SUPPORTEDFEATURES = FEATUREERRORREPLY |
+	FEATUREPAGEELISION |
+	FEATUREPAGERANGES |
+	FEATUREDIRTYRANGES |
+	FEATUREDIRECTMEMORY |
+	FEATURESHAREDMEMORY |
+	FEATUREXSAVE |
+	FEATUREPREFETCH |
+	FEATUREMAPPINGS
+
+type HelloMsg struct {
+	X64 *x64.HelloMsg
+}
@@ -14,14 +14,58 @@
 
 package msg
 
-import "github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+import (
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/page"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+)
 
 const (
 	LOADLIB uint32 = iota
 	INVOKEFUNC
 	PULLPAGE
+	HELLO
+)
+
+/* status codes share their values with gRPC status codes */
+const (
+	STATUSOK uint32 = iota
+	STATUSCANCELED
+	STATUSUNKNOWN
+	STATUSINVALIDARGUMENT
+	STATUSDEADLINEEXCEEDED
+	STATUSNOTFOUND
+	STATUSALREADYEXISTS
+	STATUSPERMISSIONDENIED
+	STATUSRESOURCEEXHAUSTED
+	STATUSFAILEDPRECONDITION
+	STATUSABORTED
+	STATUSOUTOFRANGE
+	STATUSUNIMPLEMENTED
+	STATUSINTERNAL
+	STATUSUNAVAILABLE
+	STATUSDATALOSS
+	STATUSUNAUTHENTICATED
 )
 
 type RPCHeader struct {
 	X64 *x64.RPCHeader
+	Pid uint32
+	/* layout of the pages following the header */
+	Layout page.Layout
+}
+
+type RPCError struct {
+	Status  uint32
+	Message string
+}
+
+func NewRPCError(status uint32, err error) *RPCError {
+	return &RPCError{
+		Status:  status,
+		Message: err.Error(),
+	}
+}
+
+func (e *RPCError) Error() string {
+	return e.Message
 }
@@ -14,7 +14,77 @@
 
 package page
 
-import "github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+import (
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+)
+
+/*
+ * flags in content_size of the client wire, only with FEATUREPAGEELISION.
+ * Both set mark dirty ranges, only with FEATUREDIRTYRANGES.
+ */
+const (
+	CONTENTZERO      uint32 = 0x80000000
+	CONTENTDUPLICATE uint32 = 0x40000000
+	CONTENTDIRTY     uint32 = CONTENTZERO | CONTENTDUPLICATE
+	CONTENTSIZEMASK  uint32 = 0x3fffffff
+)
+
+const HASHSIZE = 32
+
+/* ranges of the client wire start and end on this boundary, only with FEATUREPAGERANGES */
+const RANGEALIGNMENT uint64 = 64
+
+/*
+ * each page of the client wire carries a mapping kind, only with FEATUREMAPPINGS.
+ * Kind 0 leaves the mapping to the daemon, any other one is followed by
+ * protection with MAPPINGSHARED, the offset in the file and the path of a file mapping.
+ */
+const (
+	MAPPINGSHARED      uint32 = 0x80000000
+	MAXMAPPINGPATHSIZE uint32 = 4096
+)
+
+/*
+ * how pages of a session are laid out on the client wire.
+ * Without ranges every page is one PageSize page, otherwise
+ * each page carries the length of the range it describes.
+ */
+type Layout struct {
+	PageSize uint64
+	Ranges   bool
+	/* content_size flags are only accepted with the features negotiated for them */
+	Elision     bool
+	DirtyRanges bool
+	Mappings    bool
+	/* PULLPAGE starts with the invokefunc_id it faults for, only with FEATUREPREFETCH */
+	Invocations bool
+	Shared      *Shared
+	/* size of the XSAVE area in user contexts, 0 if only the FXSAVE area is sent */
+	XStateSize uint32
+	/* charged with what decoding a message allocates */
+	Budget *limit.Budget
+	/* collects the pooled buffers contents are decoded into, contents are allocated if nil */
+	Contents *Contents
+}
+
+/* pooled content buffers of a request, released once its reply no longer refers to them */
+type Contents struct {
+	Buffers [][]byte
+}
+
+/* an offset in place of content that follows inline, only with FEATURESHAREDMEMORY */
+const SHAREDINLINE = ^uint64(0)
+
+/*
+ * memory shared with the client, contents are referenced by offset.
+ * The client owns it while sending a request and the daemon while replying.
+ */
+type Shared struct {
+	Region []byte
+	/* end of the contents of the message being encoded or decoded, they never overlap */
+	Used uint64
+}
 
 type Page struct {
 	X64 *x64.Page
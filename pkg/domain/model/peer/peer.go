@@ -0,0 +1,61 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package peer
+
+type Peer struct {
+	Pid          int32
+	Uid          uint32
+	Gid          uint32
+	NsPid        int32
+	PidNamespace uint64
+	StartTime    uint64
+}
+
+/* pid as seen by the peer itself inside its pid namespace */
+func (p *Peer) ClientPid() int32 {
+	if p.NsPid == 0 {
+		return p.Pid
+	}
+	return p.NsPid
+}
+
+type Policy struct {
+	AllowUIDs []uint32 `json:"allow_uids"`
+	AllowGIDs []uint32 `json:"allow_gids"`
+}
+
+func (p *Policy) Restricted() bool {
+	return len(p.AllowUIDs) > 0 || len(p.AllowGIDs) > 0
+}
+
+func (p *Policy) Allows(peer *Peer) bool {
+	if !p.Restricted() {
+		return true
+	}
+	if peer == nil {
+		return false
+	}
+	for _, uid := range p.AllowUIDs {
+		if peer.Uid == uid {
+			return true
+		}
+	}
+	for _, gid := range p.AllowGIDs {
+		if peer.Gid == gid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prefetch
+
+import "fmt"
+
+const (
+	POLICYNONE       = "none"
+	POLICYSEQUENTIAL = "sequential"
+	POLICYHISTORY    = "history"
+)
+
+type Config struct {
+	Policy          string `json:"policy"`
+	Window          uint64 `json:"window"`
+	MaxHistoryPages uint64 `json:"max_history_pages"`
+	MaxHistories    uint64 `json:"max_histories"`
+	MaxClients      uint64 `json:"max_clients"`
+}
+
+func NewDefaultConfig() Config {
+	return Config{
+		Policy:          POLICYNONE,
+		Window:          8,
+		MaxHistoryPages: 256,
+		MaxHistories:    64,
+		MaxClients:      64,
+	}
+}
+
+/*
+ * a prefetched page is refaulted when the client pulls it again before its invocation ends.
+ * Accesses to resident pages never reach the daemon, so a page that is not refaulted
+ * may as well never have been touched.
+ */
+type Stats struct {
+	Requests     uint64
+	DemandPages  uint64
+	Prefetched   uint64
+	NotRefaulted uint64
+	Refaulted    uint64
+	Failed       uint64
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("requests=%d demand=%d prefetched=%d not_refaulted=%d refaulted=%d failed=%d",
+		s.Requests, s.DemandPages, s.Prefetched, s.NotRefaulted, s.Refaulted, s.Failed)
+}
@@ -0,0 +1,42 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+type Page struct {
+	Address       uint64 `json:"address"`
+	Sent          uint64 `json:"sent"`
+	SentBytes     uint64 `json:"sent_bytes"`
+	Pulled        uint64 `json:"pulled"`
+	PulledBytes   uint64 `json:"pulled_bytes"`
+	Returned      uint64 `json:"returned"`
+	ReturnedBytes uint64 `json:"returned_bytes"`
+}
+
+type Function struct {
+	InvokeFuncID uint64 `json:"invokefunc_id"`
+	Symbol       string `json:"symbol,omitempty"`
+	Invocations  uint64 `json:"invocations"`
+	Calls        uint64 `json:"calls"`
+	CallNanos    uint64 `json:"call_ns"`
+	Pulls        uint64 `json:"pulls"`
+	PullNanos    uint64 `json:"pull_ns"`
+	DroppedPages uint64 `json:"dropped_pages"`
+	Pages        []Page `json:"pages"`
+}
+
+type Profile struct {
+	Functions        []Function `json:"functions"`
+	DroppedFunctions uint64     `json:"dropped_functions"`
+}
@@ -0,0 +1,46 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/page"
+)
+
+type Session struct {
+	Version     uint32
+	Arch        uint32
+	PageSize    uint32
+	Features    uint64
+	XStateSize  uint32
+	Legacy      bool
+	Established bool
+}
+
+func (s *Session) HasFeature(feature uint64) bool {
+	return s.Features&feature != 0
+}
+
+func (s *Session) PageLayout() page.Layout {
+	return page.Layout{
+		PageSize:    uint64(s.PageSize),
+		Ranges:      s.HasFeature(msg.FEATUREPAGERANGES),
+		Elision:     s.HasFeature(msg.FEATUREPAGEELISION),
+		DirtyRanges: s.HasFeature(msg.FEATUREDIRTYRANGES),
+		Mappings:    s.HasFeature(msg.FEATUREMAPPINGS),
+		Invocations: s.HasFeature(msg.FEATUREPREFETCH),
+		XStateSize:  s.XStateSize,
+	}
+}
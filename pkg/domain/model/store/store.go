@@ -0,0 +1,47 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import "fmt"
+
+type Config struct {
+	Enabled   bool   `json:"enabled"`
+	MaxMemory uint64 `json:"max_memory"`
+	Dir       string `json:"dir"`
+	MaxDisk   uint64 `json:"max_disk"`
+}
+
+func NewDefaultConfig() Config {
+	return Config{
+		Enabled:   false,
+		MaxMemory: 0x10000000,
+		MaxDisk:   0x40000000,
+	}
+}
+
+type Stats struct {
+	Hits          uint64
+	Misses        uint64
+	MemoryEntries uint64
+	MemoryBytes   uint64
+	DiskEntries   uint64
+	DiskBytes     uint64
+	RemoteHashes  uint64
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("hits=%d misses=%d memory=%d/%dB disk=%d/%dB remote=%d",
+		s.Hits, s.Misses, s.MemoryEntries, s.MemoryBytes, s.DiskEntries, s.DiskBytes, s.RemoteHashes)
+}
@@ -0,0 +1,34 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/cache"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+)
+
+/* contents the stub sent at a revision, the bases of pages sent to it as deltas */
+type Bases interface {
+	Base(clientID string, address uint64, revision uint64, length uint64) ([]byte, bool)
+}
+
+type PageCache interface {
+	Bases
+	Pull(*msg.PullPageMsg, func(*msg.PullPageMsg) (*msg.PullPageMsg, error)) (*msg.PullPageMsg, error)
+	Update(string, *msg.InvokeFuncMsg, func(*msg.PullPageMsg) (*msg.PullPageMsg, error)) error
+	Attach(string)
+	Detach(string)
+	Stats() cache.Stats
+}
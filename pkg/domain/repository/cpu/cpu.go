@@ -21,6 +21,7 @@ import (
 )
 
 type CPU interface {
-	Encode(*cpu.CPU) []byte
-	Decode(io.Reader) *cpu.CPU
+	/* the uint32 is the size of the XSAVE area, 0 for the FXSAVE area alone */
+	Encode(*cpu.CPU, uint32) []byte
+	Decode(io.Reader, uint32) (*cpu.CPU, error)
 }
@@ -23,5 +23,8 @@ type GRPCClient interface {
 	InvokeFunc(*msg.InvokeFuncMsg) (*msg.InvokeFuncMsg, error)
 	PullPage(*msg.PullPageMsg) (*msg.PullPageMsg, error)
 	GetRPCType(*msg.RPCHeader) uint32
+	GetRPCStatus(error) uint32
+	GetArch() uint32
 	IsStreaming() bool
+	SetFeatures(uint64)
 }
@@ -0,0 +1,31 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/peer"
+)
+
+type Memory interface {
+	Open(*peer.Peer) (AddressSpace, error)
+}
+
+/* the memory of the process a client connected from, opened at HELLO and closed with the connection */
+type AddressSpace interface {
+	Invoke(*msg.InvokeFuncMsg, func(*msg.InvokeFuncMsg) (*msg.InvokeFuncMsg, error)) (*msg.InvokeFuncMsg, error)
+	WriteBack(*msg.InvokeFuncMsg) error
+	Close() error
+}
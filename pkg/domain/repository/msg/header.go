@@ -18,9 +18,12 @@ import (
 	"net"
 
 	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/peer"
 )
 
 type RPCHeader interface {
 	Encode(*msg.RPCHeader) []byte
+	EncodeError(*msg.RPCHeader) []byte
 	Decode(net.Conn) (*msg.RPCHeader, error)
+	BindPeer(*msg.RPCHeader, *peer.Peer)
 }
@@ -16,11 +16,17 @@ package msg
 
 import (
 	"io"
+	"net"
 
 	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/page"
 )
 
 type InvokeFunc interface {
-	Encode(*msg.InvokeFuncMsg) []byte
+	Encode(*msg.InvokeFuncMsg, page.Layout) net.Buffers
 	Decode(io.Reader, *msg.RPCHeader) (*msg.InvokeFuncMsg, error)
+	Elide(*msg.InvokeFuncMsg)
+	Contents(*msg.InvokeFuncMsg) map[uint64][]byte
+	Diff(*msg.InvokeFuncMsg, map[uint64][]byte)
+	Release(*page.Contents)
 }
@@ -16,11 +16,14 @@ package msg
 
 import (
 	"io"
+	"net"
 
 	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/page"
 )
 
 type PullPage interface {
-	Encode(*msg.PullPageMsg) []byte
+	Encode(*msg.PullPageMsg, page.Layout) net.Buffers
 	Decode(io.Reader, *msg.RPCHeader) (*msg.PullPageMsg, error)
+	Elide(*msg.PullPageMsg)
 }
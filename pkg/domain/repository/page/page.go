@@ -16,11 +16,12 @@ package page
 
 import (
 	"io"
+	"net"
 
 	"github.com/sigrpc/sigrpcd/pkg/domain/model/page"
 )
 
 type Page interface {
-	Encode(*page.Page) []byte
-	Decode(io.Reader) *page.Page
+	Encode(*page.Page, page.Layout) net.Buffers
+	Decode(io.Reader, page.Layout) (*page.Page, error)
 }
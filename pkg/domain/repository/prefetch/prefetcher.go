@@ -0,0 +1,26 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prefetch
+
+import (
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/prefetch"
+)
+
+type Prefetcher interface {
+	Invoke(*msg.InvokeFuncMsg)
+	Pull(*msg.PullPageMsg, func(*msg.PullPageMsg) (*msg.PullPageMsg, error)) (*msg.PullPageMsg, error)
+	Stats() prefetch.Stats
+}
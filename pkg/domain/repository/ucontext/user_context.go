@@ -21,6 +21,6 @@ import (
 )
 
 type UserContext interface {
-	Encode(*ucontext.UserContext) []byte
-	Decode(io.Reader) *ucontext.UserContext
+	Encode(*ucontext.UserContext, uint32) []byte
+	Decode(io.Reader, uint32) (*ucontext.UserContext, error)
 }
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.28.1
+// 	protoc-gen-go v1.36.4
 // 	protoc        v4.25.1
 // source: message.proto
 
@@ -11,6 +11,7 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -20,23 +21,133 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type PageEncoding int32
+
+const (
+	PageEncoding_PAGE_ENCODING_FULL      PageEncoding = 0
+	PageEncoding_PAGE_ENCODING_XOR_RLE   PageEncoding = 1
+	PageEncoding_PAGE_ENCODING_ZERO      PageEncoding = 2
+	PageEncoding_PAGE_ENCODING_DUPLICATE PageEncoding = 3
+	PageEncoding_PAGE_ENCODING_HASH      PageEncoding = 4
+	PageEncoding_PAGE_ENCODING_DIRTY     PageEncoding = 5
+)
+
+// Enum value maps for PageEncoding.
+var (
+	PageEncoding_name = map[int32]string{
+		0: "PAGE_ENCODING_FULL",
+		1: "PAGE_ENCODING_XOR_RLE",
+		2: "PAGE_ENCODING_ZERO",
+		3: "PAGE_ENCODING_DUPLICATE",
+		4: "PAGE_ENCODING_HASH",
+		5: "PAGE_ENCODING_DIRTY",
+	}
+	PageEncoding_value = map[string]int32{
+		"PAGE_ENCODING_FULL":      0,
+		"PAGE_ENCODING_XOR_RLE":   1,
+		"PAGE_ENCODING_ZERO":      2,
+		"PAGE_ENCODING_DUPLICATE": 3,
+		"PAGE_ENCODING_HASH":      4,
+		"PAGE_ENCODING_DIRTY":     5,
+	}
+)
+
+func (x PageEncoding) Enum() *PageEncoding {
+	p := new(PageEncoding)
+	*p = x
+	return p
+}
+
+func (x PageEncoding) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PageEncoding) Descriptor() protoreflect.EnumDescriptor {
+	return file_message_proto_enumTypes[0].Descriptor()
+}
+
+func (PageEncoding) Type() protoreflect.EnumType {
+	return &file_message_proto_enumTypes[0]
+}
+
+func (x PageEncoding) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PageEncoding.Descriptor instead.
+func (PageEncoding) EnumDescriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{0}
+}
+
+type MappingKind int32
+
+const (
+	MappingKind_MAPPING_KIND_UNKNOWN   MappingKind = 0
+	MappingKind_MAPPING_KIND_ANONYMOUS MappingKind = 1
+	MappingKind_MAPPING_KIND_HEAP      MappingKind = 2
+	MappingKind_MAPPING_KIND_STACK     MappingKind = 3
+	MappingKind_MAPPING_KIND_FILE      MappingKind = 4
+)
+
+// Enum value maps for MappingKind.
+var (
+	MappingKind_name = map[int32]string{
+		0: "MAPPING_KIND_UNKNOWN",
+		1: "MAPPING_KIND_ANONYMOUS",
+		2: "MAPPING_KIND_HEAP",
+		3: "MAPPING_KIND_STACK",
+		4: "MAPPING_KIND_FILE",
+	}
+	MappingKind_value = map[string]int32{
+		"MAPPING_KIND_UNKNOWN":   0,
+		"MAPPING_KIND_ANONYMOUS": 1,
+		"MAPPING_KIND_HEAP":      2,
+		"MAPPING_KIND_STACK":     3,
+		"MAPPING_KIND_FILE":      4,
+	}
+)
+
+func (x MappingKind) Enum() *MappingKind {
+	p := new(MappingKind)
+	*p = x
+	return p
+}
+
+func (x MappingKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MappingKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_message_proto_enumTypes[1].Descriptor()
+}
+
+func (MappingKind) Type() protoreflect.EnumType {
+	return &file_message_proto_enumTypes[1]
+}
+
+func (x MappingKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MappingKind.Descriptor instead.
+func (MappingKind) EnumDescriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{1}
+}
+
 type X64FPXReg struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Significand   []uint32               `protobuf:"varint,1,rep,packed,name=significand,proto3" json:"significand,omitempty"`
+	Exponent      uint32                 `protobuf:"varint,2,opt,name=exponent,proto3" json:"exponent,omitempty"`
+	Reserved      []uint32               `protobuf:"varint,3,rep,packed,name=reserved,proto3" json:"reserved,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Significand []uint32 `protobuf:"varint,1,rep,packed,name=significand,proto3" json:"significand,omitempty"`
-	Exponent    uint32   `protobuf:"varint,2,opt,name=exponent,proto3" json:"exponent,omitempty"`
-	Reserved    []uint32 `protobuf:"varint,3,rep,packed,name=reserved,proto3" json:"reserved,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *X64FPXReg) Reset() {
 	*x = X64FPXReg{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[0]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_message_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *X64FPXReg) String() string {
@@ -47,7 +158,7 @@ func (*X64FPXReg) ProtoMessage() {}
 
 func (x *X64FPXReg) ProtoReflect() protoreflect.Message {
 	mi := &file_message_proto_msgTypes[0]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -84,20 +195,17 @@ func (x *X64FPXReg) GetReserved() []uint32 {
 }
 
 type X64XMMReg struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Element       []uint32               `protobuf:"varint,1,rep,packed,name=element,proto3" json:"element,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Element []uint32 `protobuf:"varint,1,rep,packed,name=element,proto3" json:"element,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *X64XMMReg) Reset() {
 	*x = X64XMMReg{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[1]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_message_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *X64XMMReg) String() string {
@@ -108,7 +216,7 @@ func (*X64XMMReg) ProtoMessage() {}
 
 func (x *X64XMMReg) ProtoReflect() protoreflect.Message {
 	mi := &file_message_proto_msgTypes[1]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -131,30 +239,27 @@ func (x *X64XMMReg) GetElement() []uint32 {
 }
 
 type X64FPRegs struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cwd           uint32                 `protobuf:"varint,1,opt,name=cwd,proto3" json:"cwd,omitempty"`
+	Swd           uint32                 `protobuf:"varint,2,opt,name=swd,proto3" json:"swd,omitempty"`
+	Ftw           uint32                 `protobuf:"varint,3,opt,name=ftw,proto3" json:"ftw,omitempty"`
+	Fop           uint32                 `protobuf:"varint,4,opt,name=fop,proto3" json:"fop,omitempty"`
+	Rip           uint64                 `protobuf:"varint,5,opt,name=rip,proto3" json:"rip,omitempty"`
+	Rdp           uint64                 `protobuf:"varint,6,opt,name=rdp,proto3" json:"rdp,omitempty"`
+	Mxcsr         uint32                 `protobuf:"varint,7,opt,name=mxcsr,proto3" json:"mxcsr,omitempty"`
+	MxcrMask      uint32                 `protobuf:"varint,8,opt,name=mxcr_mask,json=mxcrMask,proto3" json:"mxcr_mask,omitempty"`
+	St            []*X64FPXReg           `protobuf:"bytes,9,rep,name=st,proto3" json:"st,omitempty"`
+	Xmm           []*X64XMMReg           `protobuf:"bytes,10,rep,name=xmm,proto3" json:"xmm,omitempty"`
+	Reserved      []uint32               `protobuf:"varint,11,rep,packed,name=reserved,proto3" json:"reserved,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Cwd      uint32       `protobuf:"varint,1,opt,name=cwd,proto3" json:"cwd,omitempty"`
-	Swd      uint32       `protobuf:"varint,2,opt,name=swd,proto3" json:"swd,omitempty"`
-	Ftw      uint32       `protobuf:"varint,3,opt,name=ftw,proto3" json:"ftw,omitempty"`
-	Fop      uint32       `protobuf:"varint,4,opt,name=fop,proto3" json:"fop,omitempty"`
-	Rip      uint64       `protobuf:"varint,5,opt,name=rip,proto3" json:"rip,omitempty"`
-	Rdp      uint64       `protobuf:"varint,6,opt,name=rdp,proto3" json:"rdp,omitempty"`
-	Mxcsr    uint32       `protobuf:"varint,7,opt,name=mxcsr,proto3" json:"mxcsr,omitempty"`
-	MxcrMask uint32       `protobuf:"varint,8,opt,name=mxcr_mask,json=mxcrMask,proto3" json:"mxcr_mask,omitempty"`
-	St       []*X64FPXReg `protobuf:"bytes,9,rep,name=st,proto3" json:"st,omitempty"`
-	Xmm      []*X64XMMReg `protobuf:"bytes,10,rep,name=xmm,proto3" json:"xmm,omitempty"`
-	Reserved []uint32     `protobuf:"varint,11,rep,packed,name=reserved,proto3" json:"reserved,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *X64FPRegs) Reset() {
 	*x = X64FPRegs{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[2]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_message_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *X64FPRegs) String() string {
@@ -165,7 +270,7 @@ func (*X64FPRegs) ProtoMessage() {}
 
 func (x *X64FPRegs) ProtoReflect() protoreflect.Message {
 	mi := &file_message_proto_msgTypes[2]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -257,22 +362,112 @@ func (x *X64FPRegs) GetReserved() []uint32 {
 	return nil
 }
 
-type CPUState struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+type X64XState struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Size          uint32                 `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	XstateBv      uint64                 `protobuf:"varint,2,opt,name=xstate_bv,json=xstateBv,proto3" json:"xstate_bv,omitempty"`
+	YmmHi128      []byte                 `protobuf:"bytes,3,opt,name=ymm_hi128,json=ymmHi128,proto3" json:"ymm_hi128,omitempty"`
+	Opmask        []byte                 `protobuf:"bytes,4,opt,name=opmask,proto3" json:"opmask,omitempty"`
+	ZmmHi256      []byte                 `protobuf:"bytes,5,opt,name=zmm_hi256,json=zmmHi256,proto3" json:"zmm_hi256,omitempty"`
+	Hi16Zmm       []byte                 `protobuf:"bytes,6,opt,name=hi16_zmm,json=hi16Zmm,proto3" json:"hi16_zmm,omitempty"`
+	Extended      []byte                 `protobuf:"bytes,7,opt,name=extended,proto3" json:"extended,omitempty"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-	Gregs  []uint64   `protobuf:"varint,1,rep,packed,name=gregs,proto3" json:"gregs,omitempty"`
-	Fpregs *X64FPRegs `protobuf:"bytes,2,opt,name=fpregs,proto3" json:"fpregs,omitempty"`
+func (x *X64XState) Reset() {
+	*x = X64XState{}
+	mi := &file_message_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *CPUState) Reset() {
-	*x = CPUState{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[3]
+func (x *X64XState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*X64XState) ProtoMessage() {}
+
+func (x *X64XState) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[3]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use X64XState.ProtoReflect.Descriptor instead.
+func (*X64XState) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *X64XState) GetSize() uint32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *X64XState) GetXstateBv() uint64 {
+	if x != nil {
+		return x.XstateBv
+	}
+	return 0
+}
+
+func (x *X64XState) GetYmmHi128() []byte {
+	if x != nil {
+		return x.YmmHi128
+	}
+	return nil
+}
+
+func (x *X64XState) GetOpmask() []byte {
+	if x != nil {
+		return x.Opmask
+	}
+	return nil
+}
+
+func (x *X64XState) GetZmmHi256() []byte {
+	if x != nil {
+		return x.ZmmHi256
 	}
+	return nil
+}
+
+func (x *X64XState) GetHi16Zmm() []byte {
+	if x != nil {
+		return x.Hi16Zmm
+	}
+	return nil
+}
+
+func (x *X64XState) GetExtended() []byte {
+	if x != nil {
+		return x.Extended
+	}
+	return nil
+}
+
+type CPUState struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Gregs         []uint64               `protobuf:"varint,1,rep,packed,name=gregs,proto3" json:"gregs,omitempty"`
+	Fpregs        *X64FPRegs             `protobuf:"bytes,2,opt,name=fpregs,proto3" json:"fpregs,omitempty"`
+	Xstate        *X64XState             `protobuf:"bytes,3,opt,name=xstate,proto3" json:"xstate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CPUState) Reset() {
+	*x = CPUState{}
+	mi := &file_message_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *CPUState) String() string {
@@ -282,8 +477,8 @@ func (x *CPUState) String() string {
 func (*CPUState) ProtoMessage() {}
 
 func (x *CPUState) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[3]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_message_proto_msgTypes[4]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -295,7 +490,7 @@ func (x *CPUState) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CPUState.ProtoReflect.Descriptor instead.
 func (*CPUState) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{3}
+	return file_message_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *CPUState) GetGregs() []uint64 {
@@ -312,24 +507,29 @@ func (x *CPUState) GetFpregs() *X64FPRegs {
 	return nil
 }
 
+func (x *CPUState) GetXstate() *X64XState {
+	if x != nil {
+		return x.Xstate
+	}
+	return nil
+}
+
 type RPCHeader struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MsgType       uint32                 `protobuf:"varint,1,opt,name=msg_type,json=msgType,proto3" json:"msg_type,omitempty"`
+	Status        uint32                 `protobuf:"varint,2,opt,name=status,proto3" json:"status,omitempty"`
+	ClientId      string                 `protobuf:"bytes,3,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	PayloadSize   uint64                 `protobuf:"varint,4,opt,name=payload_size,json=payloadSize,proto3" json:"payload_size,omitempty"`
+	ErrorMessage  string                 `protobuf:"bytes,5,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	MsgType     uint32 `protobuf:"varint,1,opt,name=msg_type,json=msgType,proto3" json:"msg_type,omitempty"`
-	Status      uint32 `protobuf:"varint,2,opt,name=status,proto3" json:"status,omitempty"`
-	ClientId    string `protobuf:"bytes,3,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
-	PayloadSize uint64 `protobuf:"varint,4,opt,name=payload_size,json=payloadSize,proto3" json:"payload_size,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *RPCHeader) Reset() {
 	*x = RPCHeader{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[4]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_message_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *RPCHeader) String() string {
@@ -339,8 +539,8 @@ func (x *RPCHeader) String() string {
 func (*RPCHeader) ProtoMessage() {}
 
 func (x *RPCHeader) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[4]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_message_proto_msgTypes[5]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -352,7 +552,7 @@ func (x *RPCHeader) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RPCHeader.ProtoReflect.Descriptor instead.
 func (*RPCHeader) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{4}
+	return file_message_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *RPCHeader) GetMsgType() uint32 {
@@ -383,22 +583,110 @@ func (x *RPCHeader) GetPayloadSize() uint64 {
 	return 0
 }
 
-type Addr2Sym struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+func (x *RPCHeader) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+type HelloMsg struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Header        *RPCHeader             `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	Version       uint32                 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	Arch          uint32                 `protobuf:"varint,3,opt,name=arch,proto3" json:"arch,omitempty"`
+	PageSize      uint32                 `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Features      uint64                 `protobuf:"varint,5,opt,name=features,proto3" json:"features,omitempty"`
+	XstateSize    uint32                 `protobuf:"varint,6,opt,name=xstate_size,json=xstateSize,proto3" json:"xstate_size,omitempty"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-	Address uint64 `protobuf:"varint,1,opt,name=address,proto3" json:"address,omitempty"`
-	Name    string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+func (x *HelloMsg) Reset() {
+	*x = HelloMsg{}
+	mi := &file_message_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *Addr2Sym) Reset() {
-	*x = Addr2Sym{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[5]
+func (x *HelloMsg) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HelloMsg) ProtoMessage() {}
+
+func (x *HelloMsg) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[6]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HelloMsg.ProtoReflect.Descriptor instead.
+func (*HelloMsg) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *HelloMsg) GetHeader() *RPCHeader {
+	if x != nil {
+		return x.Header
+	}
+	return nil
+}
+
+func (x *HelloMsg) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *HelloMsg) GetArch() uint32 {
+	if x != nil {
+		return x.Arch
+	}
+	return 0
+}
+
+func (x *HelloMsg) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
 	}
+	return 0
+}
+
+func (x *HelloMsg) GetFeatures() uint64 {
+	if x != nil {
+		return x.Features
+	}
+	return 0
+}
+
+func (x *HelloMsg) GetXstateSize() uint32 {
+	if x != nil {
+		return x.XstateSize
+	}
+	return 0
+}
+
+type Addr2Sym struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       uint64                 `protobuf:"varint,1,opt,name=address,proto3" json:"address,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Addr2Sym) Reset() {
+	*x = Addr2Sym{}
+	mi := &file_message_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *Addr2Sym) String() string {
@@ -408,8 +696,8 @@ func (x *Addr2Sym) String() string {
 func (*Addr2Sym) ProtoMessage() {}
 
 func (x *Addr2Sym) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[5]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_message_proto_msgTypes[7]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -421,7 +709,7 @@ func (x *Addr2Sym) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Addr2Sym.ProtoReflect.Descriptor instead.
 func (*Addr2Sym) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{5}
+	return file_message_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *Addr2Sym) GetAddress() uint64 {
@@ -438,25 +726,156 @@ func (x *Addr2Sym) GetName() string {
 	return ""
 }
 
-type Page struct {
-	state         protoimpl.MessageState
+type DirtyRange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Offset        uint32                 `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`
+	Content       []byte                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DirtyRange) Reset() {
+	*x = DirtyRange{}
+	mi := &file_message_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DirtyRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DirtyRange) ProtoMessage() {}
+
+func (x *DirtyRange) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DirtyRange.ProtoReflect.Descriptor instead.
+func (*DirtyRange) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DirtyRange) GetOffset() uint32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *DirtyRange) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+type Mapping struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Protection    uint32                 `protobuf:"varint,1,opt,name=protection,proto3" json:"protection,omitempty"`
+	Shared        bool                   `protobuf:"varint,2,opt,name=shared,proto3" json:"shared,omitempty"`
+	Kind          MappingKind            `protobuf:"varint,3,opt,name=kind,proto3,enum=x64.MappingKind" json:"kind,omitempty"`
+	Path          string                 `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`
+	Offset        uint64                 `protobuf:"varint,5,opt,name=offset,proto3" json:"offset,omitempty"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-	Address         uint64 `protobuf:"varint,1,opt,name=address,proto3" json:"address,omitempty"`
-	RuntimeRevision uint64 `protobuf:"varint,2,opt,name=runtime_revision,json=runtimeRevision,proto3" json:"runtime_revision,omitempty"`
-	ClientRevision  uint64 `protobuf:"varint,3,opt,name=client_revision,json=clientRevision,proto3" json:"client_revision,omitempty"`
-	ContentSize     uint32 `protobuf:"varint,4,opt,name=content_size,json=contentSize,proto3" json:"content_size,omitempty"`
-	Content         []byte `protobuf:"bytes,5,opt,name=content,proto3" json:"content,omitempty"`
+func (x *Mapping) Reset() {
+	*x = Mapping{}
+	mi := &file_message_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *Page) Reset() {
-	*x = Page{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[6]
+func (x *Mapping) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Mapping) ProtoMessage() {}
+
+func (x *Mapping) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[9]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Mapping.ProtoReflect.Descriptor instead.
+func (*Mapping) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Mapping) GetProtection() uint32 {
+	if x != nil {
+		return x.Protection
+	}
+	return 0
+}
+
+func (x *Mapping) GetShared() bool {
+	if x != nil {
+		return x.Shared
+	}
+	return false
+}
+
+func (x *Mapping) GetKind() MappingKind {
+	if x != nil {
+		return x.Kind
+	}
+	return MappingKind_MAPPING_KIND_UNKNOWN
+}
+
+func (x *Mapping) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Mapping) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
 	}
+	return 0
+}
+
+type Page struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Address         uint64                 `protobuf:"varint,1,opt,name=address,proto3" json:"address,omitempty"`
+	RuntimeRevision uint64                 `protobuf:"varint,2,opt,name=runtime_revision,json=runtimeRevision,proto3" json:"runtime_revision,omitempty"`
+	ClientRevision  uint64                 `protobuf:"varint,3,opt,name=client_revision,json=clientRevision,proto3" json:"client_revision,omitempty"`
+	ContentSize     uint32                 `protobuf:"varint,4,opt,name=content_size,json=contentSize,proto3" json:"content_size,omitempty"`
+	Content         []byte                 `protobuf:"bytes,5,opt,name=content,proto3" json:"content,omitempty"`
+	BaseRevision    uint64                 `protobuf:"varint,6,opt,name=base_revision,json=baseRevision,proto3" json:"base_revision,omitempty"`
+	Encoding        PageEncoding           `protobuf:"varint,7,opt,name=encoding,proto3,enum=x64.PageEncoding" json:"encoding,omitempty"`
+	ContentHash     []byte                 `protobuf:"bytes,8,opt,name=content_hash,json=contentHash,proto3" json:"content_hash,omitempty"`
+	Length          uint64                 `protobuf:"varint,9,opt,name=length,proto3" json:"length,omitempty"`
+	Dirty           []*DirtyRange          `protobuf:"bytes,10,rep,name=dirty,proto3" json:"dirty,omitempty"`
+	Mapping         *Mapping               `protobuf:"bytes,11,opt,name=mapping,proto3" json:"mapping,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Page) Reset() {
+	*x = Page{}
+	mi := &file_message_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *Page) String() string {
@@ -466,8 +885,8 @@ func (x *Page) String() string {
 func (*Page) ProtoMessage() {}
 
 func (x *Page) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[6]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_message_proto_msgTypes[10]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -479,7 +898,7 @@ func (x *Page) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Page.ProtoReflect.Descriptor instead.
 func (*Page) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{6}
+	return file_message_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Page) GetAddress() uint64 {
@@ -517,23 +936,62 @@ func (x *Page) GetContent() []byte {
 	return nil
 }
 
+func (x *Page) GetBaseRevision() uint64 {
+	if x != nil {
+		return x.BaseRevision
+	}
+	return 0
+}
+
+func (x *Page) GetEncoding() PageEncoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return PageEncoding_PAGE_ENCODING_FULL
+}
+
+func (x *Page) GetContentHash() []byte {
+	if x != nil {
+		return x.ContentHash
+	}
+	return nil
+}
+
+func (x *Page) GetLength() uint64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *Page) GetDirty() []*DirtyRange {
+	if x != nil {
+		return x.Dirty
+	}
+	return nil
+}
+
+func (x *Page) GetMapping() *Mapping {
+	if x != nil {
+		return x.Mapping
+	}
+	return nil
+}
+
 type LoadLibMsg struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Header        *RPCHeader             `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	LibraryName   string                 `protobuf:"bytes,2,opt,name=library_name,json=libraryName,proto3" json:"library_name,omitempty"`
+	Addr2Sym      []*Addr2Sym            `protobuf:"bytes,3,rep,name=addr2sym,proto3" json:"addr2sym,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Header      *RPCHeader  `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
-	LibraryName string      `protobuf:"bytes,2,opt,name=library_name,json=libraryName,proto3" json:"library_name,omitempty"`
-	Addr2Sym    []*Addr2Sym `protobuf:"bytes,3,rep,name=addr2sym,proto3" json:"addr2sym,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *LoadLibMsg) Reset() {
 	*x = LoadLibMsg{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[7]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_message_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *LoadLibMsg) String() string {
@@ -543,8 +1001,8 @@ func (x *LoadLibMsg) String() string {
 func (*LoadLibMsg) ProtoMessage() {}
 
 func (x *LoadLibMsg) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[7]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_message_proto_msgTypes[11]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -556,7 +1014,7 @@ func (x *LoadLibMsg) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LoadLibMsg.ProtoReflect.Descriptor instead.
 func (*LoadLibMsg) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{7}
+	return file_message_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *LoadLibMsg) GetHeader() *RPCHeader {
@@ -581,21 +1039,18 @@ func (x *LoadLibMsg) GetAddr2Sym() []*Addr2Sym {
 }
 
 type UserContext struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cpu           *CPUState              `protobuf:"bytes,1,opt,name=cpu,proto3" json:"cpu,omitempty"`
+	StackBottom   uint64                 `protobuf:"varint,2,opt,name=stack_bottom,json=stackBottom,proto3" json:"stack_bottom,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Cpu         *CPUState `protobuf:"bytes,1,opt,name=cpu,proto3" json:"cpu,omitempty"`
-	StackBottom uint64    `protobuf:"varint,2,opt,name=stack_bottom,json=stackBottom,proto3" json:"stack_bottom,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UserContext) Reset() {
 	*x = UserContext{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[8]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_message_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *UserContext) String() string {
@@ -605,8 +1060,8 @@ func (x *UserContext) String() string {
 func (*UserContext) ProtoMessage() {}
 
 func (x *UserContext) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[8]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_message_proto_msgTypes[12]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -618,7 +1073,7 @@ func (x *UserContext) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UserContext.ProtoReflect.Descriptor instead.
 func (*UserContext) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{8}
+	return file_message_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *UserContext) GetCpu() *CPUState {
@@ -636,24 +1091,22 @@ func (x *UserContext) GetStackBottom() uint64 {
 }
 
 type InvokeFuncMsg struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Header        *RPCHeader             `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	InvokefuncId  uint64                 `protobuf:"varint,2,opt,name=invokefunc_id,json=invokefuncId,proto3" json:"invokefunc_id,omitempty"`
+	RespId        uint64                 `protobuf:"varint,3,opt,name=resp_id,json=respId,proto3" json:"resp_id,omitempty"`
+	Ctx           *UserContext           `protobuf:"bytes,4,opt,name=ctx,proto3" json:"ctx,omitempty"`
+	Page          []*Page                `protobuf:"bytes,5,rep,name=page,proto3" json:"page,omitempty"`
+	Fault         []*Page                `protobuf:"bytes,6,rep,name=fault,proto3" json:"fault,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Header       *RPCHeader   `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
-	InvokefuncId uint64       `protobuf:"varint,2,opt,name=invokefunc_id,json=invokefuncId,proto3" json:"invokefunc_id,omitempty"`
-	RespId       uint64       `protobuf:"varint,3,opt,name=resp_id,json=respId,proto3" json:"resp_id,omitempty"`
-	Ctx          *UserContext `protobuf:"bytes,4,opt,name=ctx,proto3" json:"ctx,omitempty"`
-	Page         []*Page      `protobuf:"bytes,5,rep,name=page,proto3" json:"page,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *InvokeFuncMsg) Reset() {
 	*x = InvokeFuncMsg{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[9]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_message_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *InvokeFuncMsg) String() string {
@@ -663,8 +1116,8 @@ func (x *InvokeFuncMsg) String() string {
 func (*InvokeFuncMsg) ProtoMessage() {}
 
 func (x *InvokeFuncMsg) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[9]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_message_proto_msgTypes[13]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -676,7 +1129,7 @@ func (x *InvokeFuncMsg) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use InvokeFuncMsg.ProtoReflect.Descriptor instead.
 func (*InvokeFuncMsg) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{9}
+	return file_message_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *InvokeFuncMsg) GetHeader() *RPCHeader {
@@ -714,22 +1167,27 @@ func (x *InvokeFuncMsg) GetPage() []*Page {
 	return nil
 }
 
+func (x *InvokeFuncMsg) GetFault() []*Page {
+	if x != nil {
+		return x.Fault
+	}
+	return nil
+}
+
 type PullPageMsg struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Header        *RPCHeader             `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	Page          []*Page                `protobuf:"bytes,2,rep,name=page,proto3" json:"page,omitempty"`
+	InvokefuncId  uint64                 `protobuf:"varint,3,opt,name=invokefunc_id,json=invokefuncId,proto3" json:"invokefunc_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Header *RPCHeader `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
-	Page   []*Page    `protobuf:"bytes,2,rep,name=page,proto3" json:"page,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *PullPageMsg) Reset() {
 	*x = PullPageMsg{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_message_proto_msgTypes[10]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_message_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *PullPageMsg) String() string {
@@ -739,8 +1197,8 @@ func (x *PullPageMsg) String() string {
 func (*PullPageMsg) ProtoMessage() {}
 
 func (x *PullPageMsg) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[10]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_message_proto_msgTypes[14]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -752,7 +1210,7 @@ func (x *PullPageMsg) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PullPageMsg.ProtoReflect.Descriptor instead.
 func (*PullPageMsg) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{10}
+	return file_message_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *PullPageMsg) GetHeader() *RPCHeader {
@@ -769,9 +1227,68 @@ func (x *PullPageMsg) GetPage() []*Page {
 	return nil
 }
 
+func (x *PullPageMsg) GetInvokefuncId() uint64 {
+	if x != nil {
+		return x.InvokefuncId
+	}
+	return 0
+}
+
+type ContentHashes struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Header        *RPCHeader             `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	Hash          [][]byte               `protobuf:"bytes,2,rep,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContentHashes) Reset() {
+	*x = ContentHashes{}
+	mi := &file_message_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContentHashes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContentHashes) ProtoMessage() {}
+
+func (x *ContentHashes) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContentHashes.ProtoReflect.Descriptor instead.
+func (*ContentHashes) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ContentHashes) GetHeader() *RPCHeader {
+	if x != nil {
+		return x.Header
+	}
+	return nil
+}
+
+func (x *ContentHashes) GetHash() [][]byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
 var File_message_proto protoreflect.FileDescriptor
 
-var file_message_proto_rawDesc = []byte{
+var file_message_proto_rawDesc = string([]byte{
 	0x0a, 0x0d, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
 	0x03, 0x78, 0x36, 0x34, 0x22, 0x65, 0x0a, 0x09, 0x58, 0x36, 0x34, 0x46, 0x50, 0x58, 0x52, 0x65,
 	0x67, 0x12, 0x20, 0x0a, 0x0b, 0x73, 0x69, 0x67, 0x6e, 0x69, 0x66, 0x69, 0x63, 0x61, 0x6e, 0x64,
@@ -798,65 +1315,150 @@ var file_message_proto_rawDesc = []byte{
 	0x20, 0x0a, 0x03, 0x78, 0x6d, 0x6d, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x78,
 	0x36, 0x34, 0x2e, 0x58, 0x36, 0x34, 0x58, 0x4d, 0x4d, 0x52, 0x65, 0x67, 0x52, 0x03, 0x78, 0x6d,
 	0x6d, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x18, 0x0b, 0x20,
-	0x03, 0x28, 0x0d, 0x52, 0x08, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x22, 0x48, 0x0a,
-	0x08, 0x43, 0x50, 0x55, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x67, 0x72, 0x65,
-	0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x04, 0x52, 0x05, 0x67, 0x72, 0x65, 0x67, 0x73, 0x12,
-	0x26, 0x0a, 0x06, 0x66, 0x70, 0x72, 0x65, 0x67, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x0e, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x58, 0x36, 0x34, 0x46, 0x50, 0x52, 0x65, 0x67, 0x73, 0x52,
-	0x06, 0x66, 0x70, 0x72, 0x65, 0x67, 0x73, 0x22, 0x7e, 0x0a, 0x09, 0x52, 0x50, 0x43, 0x48, 0x65,
-	0x61, 0x64, 0x65, 0x72, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x73, 0x67, 0x5f, 0x74, 0x79, 0x70, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x6d, 0x73, 0x67, 0x54, 0x79, 0x70, 0x65, 0x12,
-	0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52,
-	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e,
-	0x74, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6c, 0x69, 0x65,
-	0x6e, 0x74, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x5f,
-	0x73, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x70, 0x61, 0x79, 0x6c,
-	0x6f, 0x61, 0x64, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x38, 0x0a, 0x08, 0x41, 0x64, 0x64, 0x72, 0x32,
-	0x53, 0x79, 0x6d, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x12, 0x0a,
-	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
-	0x65, 0x22, 0xb1, 0x01, 0x0a, 0x04, 0x50, 0x61, 0x67, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64,
-	0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x61, 0x64, 0x64,
-	0x72, 0x65, 0x73, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x72, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x5f,
-	0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f,
-	0x72, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12,
-	0x27, 0x0a, 0x0f, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69,
-	0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0e, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74,
-	0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6e, 0x74,
-	0x65, 0x6e, 0x74, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b,
-	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63,
-	0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x63, 0x6f,
-	0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0x82, 0x01, 0x0a, 0x0a, 0x4c, 0x6f, 0x61, 0x64, 0x4c, 0x69,
-	0x62, 0x4d, 0x73, 0x67, 0x12, 0x26, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x52, 0x50, 0x43, 0x48, 0x65,
-	0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x21, 0x0a, 0x0c,
-	0x6c, 0x69, 0x62, 0x72, 0x61, 0x72, 0x79, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x0b, 0x6c, 0x69, 0x62, 0x72, 0x61, 0x72, 0x79, 0x4e, 0x61, 0x6d, 0x65, 0x12,
-	0x29, 0x0a, 0x08, 0x61, 0x64, 0x64, 0x72, 0x32, 0x73, 0x79, 0x6d, 0x18, 0x03, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x0d, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x41, 0x64, 0x64, 0x72, 0x32, 0x53, 0x79, 0x6d,
-	0x52, 0x08, 0x61, 0x64, 0x64, 0x72, 0x32, 0x73, 0x79, 0x6d, 0x22, 0x51, 0x0a, 0x0b, 0x55, 0x73,
-	0x65, 0x72, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x12, 0x1f, 0x0a, 0x03, 0x63, 0x70, 0x75,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x43, 0x50, 0x55,
-	0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x03, 0x63, 0x70, 0x75, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x74,
-	0x61, 0x63, 0x6b, 0x5f, 0x62, 0x6f, 0x74, 0x74, 0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04,
-	0x52, 0x0b, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x42, 0x6f, 0x74, 0x74, 0x6f, 0x6d, 0x22, 0xb8, 0x01,
-	0x0a, 0x0d, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x46, 0x75, 0x6e, 0x63, 0x4d, 0x73, 0x67, 0x12,
+	0x03, 0x28, 0x0d, 0x52, 0x08, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x22, 0xc5, 0x01,
+	0x0a, 0x09, 0x58, 0x36, 0x34, 0x58, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x73,
+	0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12,
+	0x1b, 0x0a, 0x09, 0x78, 0x73, 0x74, 0x61, 0x74, 0x65, 0x5f, 0x62, 0x76, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x08, 0x78, 0x73, 0x74, 0x61, 0x74, 0x65, 0x42, 0x76, 0x12, 0x1b, 0x0a, 0x09,
+	0x79, 0x6d, 0x6d, 0x5f, 0x68, 0x69, 0x31, 0x32, 0x38, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x08, 0x79, 0x6d, 0x6d, 0x48, 0x69, 0x31, 0x32, 0x38, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x70, 0x6d,
+	0x61, 0x73, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x6f, 0x70, 0x6d, 0x61, 0x73,
+	0x6b, 0x12, 0x1b, 0x0a, 0x09, 0x7a, 0x6d, 0x6d, 0x5f, 0x68, 0x69, 0x32, 0x35, 0x36, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x7a, 0x6d, 0x6d, 0x48, 0x69, 0x32, 0x35, 0x36, 0x12, 0x19,
+	0x0a, 0x08, 0x68, 0x69, 0x31, 0x36, 0x5f, 0x7a, 0x6d, 0x6d, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x07, 0x68, 0x69, 0x31, 0x36, 0x5a, 0x6d, 0x6d, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x78, 0x74,
+	0x65, 0x6e, 0x64, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x65, 0x78, 0x74,
+	0x65, 0x6e, 0x64, 0x65, 0x64, 0x22, 0x70, 0x0a, 0x08, 0x43, 0x50, 0x55, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x67, 0x72, 0x65, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x04,
+	0x52, 0x05, 0x67, 0x72, 0x65, 0x67, 0x73, 0x12, 0x26, 0x0a, 0x06, 0x66, 0x70, 0x72, 0x65, 0x67,
+	0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x58, 0x36,
+	0x34, 0x46, 0x50, 0x52, 0x65, 0x67, 0x73, 0x52, 0x06, 0x66, 0x70, 0x72, 0x65, 0x67, 0x73, 0x12,
+	0x26, 0x0a, 0x06, 0x78, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0e, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x58, 0x36, 0x34, 0x58, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
+	0x06, 0x78, 0x73, 0x74, 0x61, 0x74, 0x65, 0x22, 0xa3, 0x01, 0x0a, 0x09, 0x52, 0x50, 0x43, 0x48,
+	0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x73, 0x67, 0x5f, 0x74, 0x79, 0x70,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x6d, 0x73, 0x67, 0x54, 0x79, 0x70, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6c, 0x69,
+	0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64,
+	0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x70, 0x61, 0x79,
+	0x6c, 0x6f, 0x61, 0x64, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0c, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0xba, 0x01,
+	0x0a, 0x08, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x4d, 0x73, 0x67, 0x12, 0x26, 0x0a, 0x06, 0x68, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x78, 0x36, 0x34,
+	0x2e, 0x52, 0x50, 0x43, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04,
+	0x61, 0x72, 0x63, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x61, 0x72, 0x63, 0x68,
+	0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x66, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x08, 0x66, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x78, 0x73, 0x74,
+	0x61, 0x74, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a,
+	0x78, 0x73, 0x74, 0x61, 0x74, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x38, 0x0a, 0x08, 0x41, 0x64,
+	0x64, 0x72, 0x32, 0x53, 0x79, 0x6d, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x22, 0x3e, 0x0a, 0x0a, 0x44, 0x69, 0x72, 0x74, 0x79, 0x52, 0x61, 0x6e,
+	0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f,
+	0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x63, 0x6f, 0x6e,
+	0x74, 0x65, 0x6e, 0x74, 0x22, 0x93, 0x01, 0x0a, 0x07, 0x4d, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67,
+	0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x72, 0x6f, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x70, 0x72, 0x6f, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x16, 0x0a, 0x06, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x06, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x12, 0x24, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x10, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x4d, 0x61, 0x70,
+	0x70, 0x69, 0x6e, 0x67, 0x4b, 0x69, 0x6e, 0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x12,
+	0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61,
+	0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x22, 0x8f, 0x03, 0x0a, 0x04, 0x50,
+	0x61, 0x67, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x29, 0x0a,
+	0x10, 0x72, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f, 0x72, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65,
+	0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x27, 0x0a, 0x0f, 0x63, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x0e, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x5f, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74,
+	0x53, 0x69, 0x7a, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x23,
+	0x0a, 0x0d, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x62, 0x61, 0x73, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x2d, 0x0a, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x50, 0x61, 0x67, 0x65,
+	0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69,
+	0x6e, 0x67, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x5f, 0x68, 0x61,
+	0x73, 0x68, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e,
+	0x74, 0x48, 0x61, 0x73, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18,
+	0x09, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x25, 0x0a,
+	0x05, 0x64, 0x69, 0x72, 0x74, 0x79, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x78,
+	0x36, 0x34, 0x2e, 0x44, 0x69, 0x72, 0x74, 0x79, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x05, 0x64,
+	0x69, 0x72, 0x74, 0x79, 0x12, 0x26, 0x0a, 0x07, 0x6d, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x4d, 0x61, 0x70, 0x70,
+	0x69, 0x6e, 0x67, 0x52, 0x07, 0x6d, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x22, 0x82, 0x01, 0x0a,
+	0x0a, 0x4c, 0x6f, 0x61, 0x64, 0x4c, 0x69, 0x62, 0x4d, 0x73, 0x67, 0x12, 0x26, 0x0a, 0x06, 0x68,
+	0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x78, 0x36,
+	0x34, 0x2e, 0x52, 0x50, 0x43, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x12, 0x21, 0x0a, 0x0c, 0x6c, 0x69, 0x62, 0x72, 0x61, 0x72, 0x79, 0x5f, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6c, 0x69, 0x62, 0x72, 0x61,
+	0x72, 0x79, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x29, 0x0a, 0x08, 0x61, 0x64, 0x64, 0x72, 0x32, 0x73,
+	0x79, 0x6d, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x41,
+	0x64, 0x64, 0x72, 0x32, 0x53, 0x79, 0x6d, 0x52, 0x08, 0x61, 0x64, 0x64, 0x72, 0x32, 0x73, 0x79,
+	0x6d, 0x22, 0x51, 0x0a, 0x0b, 0x55, 0x73, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74,
+	0x12, 0x1f, 0x0a, 0x03, 0x63, 0x70, 0x75, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e,
+	0x78, 0x36, 0x34, 0x2e, 0x43, 0x50, 0x55, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x03, 0x63, 0x70,
+	0x75, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x5f, 0x62, 0x6f, 0x74, 0x74, 0x6f,
+	0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x42, 0x6f,
+	0x74, 0x74, 0x6f, 0x6d, 0x22, 0xd9, 0x01, 0x0a, 0x0d, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x46,
+	0x75, 0x6e, 0x63, 0x4d, 0x73, 0x67, 0x12, 0x26, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x52, 0x50, 0x43,
+	0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x23,
+	0x0a, 0x0d, 0x69, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x66, 0x75, 0x6e, 0x63, 0x5f, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x69, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x66, 0x75, 0x6e,
+	0x63, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x72, 0x65, 0x73, 0x70, 0x49, 0x64, 0x12, 0x22, 0x0a, 0x03,
+	0x63, 0x74, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x78, 0x36, 0x34, 0x2e,
+	0x55, 0x73, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x03, 0x63, 0x74, 0x78,
+	0x12, 0x1d, 0x0a, 0x04, 0x70, 0x61, 0x67, 0x65, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x09,
+	0x2e, 0x78, 0x36, 0x34, 0x2e, 0x50, 0x61, 0x67, 0x65, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x12,
+	0x1f, 0x0a, 0x05, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x09,
+	0x2e, 0x78, 0x36, 0x34, 0x2e, 0x50, 0x61, 0x67, 0x65, 0x52, 0x05, 0x66, 0x61, 0x75, 0x6c, 0x74,
+	0x22, 0x79, 0x0a, 0x0b, 0x50, 0x75, 0x6c, 0x6c, 0x50, 0x61, 0x67, 0x65, 0x4d, 0x73, 0x67, 0x12,
 	0x26, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
 	0x0e, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x52, 0x50, 0x43, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52,
-	0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x23, 0x0a, 0x0d, 0x69, 0x6e, 0x76, 0x6f, 0x6b,
-	0x65, 0x66, 0x75, 0x6e, 0x63, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c,
-	0x69, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x66, 0x75, 0x6e, 0x63, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07,
-	0x72, 0x65, 0x73, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x72,
-	0x65, 0x73, 0x70, 0x49, 0x64, 0x12, 0x22, 0x0a, 0x03, 0x63, 0x74, 0x78, 0x18, 0x04, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x10, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x43, 0x6f, 0x6e,
-	0x74, 0x65, 0x78, 0x74, 0x52, 0x03, 0x63, 0x74, 0x78, 0x12, 0x1d, 0x0a, 0x04, 0x70, 0x61, 0x67,
-	0x65, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x50, 0x61,
-	0x67, 0x65, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x22, 0x54, 0x0a, 0x0b, 0x50, 0x75, 0x6c, 0x6c,
-	0x50, 0x61, 0x67, 0x65, 0x4d, 0x73, 0x67, 0x12, 0x26, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65,
-	0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x52, 0x50,
-	0x43, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12,
-	0x1d, 0x0a, 0x04, 0x70, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x09, 0x2e,
-	0x78, 0x36, 0x34, 0x2e, 0x50, 0x61, 0x67, 0x65, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x32, 0xa5,
+	0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x1d, 0x0a, 0x04, 0x70, 0x61, 0x67, 0x65, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x50, 0x61, 0x67, 0x65,
+	0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x69, 0x6e, 0x76, 0x6f, 0x6b, 0x65,
+	0x66, 0x75, 0x6e, 0x63, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x69,
+	0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x66, 0x75, 0x6e, 0x63, 0x49, 0x64, 0x22, 0x4b, 0x0a, 0x0d, 0x43,
+	0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x12, 0x26, 0x0a, 0x06,
+	0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x78,
+	0x36, 0x34, 0x2e, 0x52, 0x50, 0x43, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0c, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x2a, 0xa7, 0x01, 0x0a, 0x0c, 0x50, 0x61, 0x67,
+	0x65, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x16, 0x0a, 0x12, 0x50, 0x41, 0x47,
+	0x45, 0x5f, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x46, 0x55, 0x4c, 0x4c, 0x10,
+	0x00, 0x12, 0x19, 0x0a, 0x15, 0x50, 0x41, 0x47, 0x45, 0x5f, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49,
+	0x4e, 0x47, 0x5f, 0x58, 0x4f, 0x52, 0x5f, 0x52, 0x4c, 0x45, 0x10, 0x01, 0x12, 0x16, 0x0a, 0x12,
+	0x50, 0x41, 0x47, 0x45, 0x5f, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x5a, 0x45,
+	0x52, 0x4f, 0x10, 0x02, 0x12, 0x1b, 0x0a, 0x17, 0x50, 0x41, 0x47, 0x45, 0x5f, 0x45, 0x4e, 0x43,
+	0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x44, 0x55, 0x50, 0x4c, 0x49, 0x43, 0x41, 0x54, 0x45, 0x10,
+	0x03, 0x12, 0x16, 0x0a, 0x12, 0x50, 0x41, 0x47, 0x45, 0x5f, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49,
+	0x4e, 0x47, 0x5f, 0x48, 0x41, 0x53, 0x48, 0x10, 0x04, 0x12, 0x17, 0x0a, 0x13, 0x50, 0x41, 0x47,
+	0x45, 0x5f, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x44, 0x49, 0x52, 0x54, 0x59,
+	0x10, 0x05, 0x2a, 0x89, 0x01, 0x0a, 0x0b, 0x4d, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x4b, 0x69,
+	0x6e, 0x64, 0x12, 0x18, 0x0a, 0x14, 0x4d, 0x41, 0x50, 0x50, 0x49, 0x4e, 0x47, 0x5f, 0x4b, 0x49,
+	0x4e, 0x44, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x1a, 0x0a, 0x16,
+	0x4d, 0x41, 0x50, 0x50, 0x49, 0x4e, 0x47, 0x5f, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x41, 0x4e, 0x4f,
+	0x4e, 0x59, 0x4d, 0x4f, 0x55, 0x53, 0x10, 0x01, 0x12, 0x15, 0x0a, 0x11, 0x4d, 0x41, 0x50, 0x50,
+	0x49, 0x4e, 0x47, 0x5f, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x48, 0x45, 0x41, 0x50, 0x10, 0x02, 0x12,
+	0x16, 0x0a, 0x12, 0x4d, 0x41, 0x50, 0x50, 0x49, 0x4e, 0x47, 0x5f, 0x4b, 0x49, 0x4e, 0x44, 0x5f,
+	0x53, 0x54, 0x41, 0x43, 0x4b, 0x10, 0x03, 0x12, 0x15, 0x0a, 0x11, 0x4d, 0x41, 0x50, 0x50, 0x49,
+	0x4e, 0x47, 0x5f, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x46, 0x49, 0x4c, 0x45, 0x10, 0x04, 0x32, 0xdd,
 	0x01, 0x0a, 0x06, 0x53, 0x69, 0x67, 0x52, 0x50, 0x43, 0x12, 0x2d, 0x0a, 0x07, 0x4c, 0x6f, 0x61,
 	0x64, 0x4c, 0x69, 0x62, 0x12, 0x0f, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x4c, 0x6f, 0x61, 0x64, 0x4c,
 	0x69, 0x62, 0x4d, 0x73, 0x67, 0x1a, 0x0f, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x4c, 0x6f, 0x61, 0x64,
@@ -867,61 +1469,83 @@ var file_message_proto_rawDesc = []byte{
 	0x28, 0x01, 0x30, 0x01, 0x12, 0x30, 0x0a, 0x08, 0x50, 0x75, 0x6c, 0x6c, 0x50, 0x61, 0x67, 0x65,
 	0x12, 0x10, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x50, 0x75, 0x6c, 0x6c, 0x50, 0x61, 0x67, 0x65, 0x4d,
 	0x73, 0x67, 0x1a, 0x10, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x50, 0x75, 0x6c, 0x6c, 0x50, 0x61, 0x67,
-	0x65, 0x4d, 0x73, 0x67, 0x22, 0x00, 0x42, 0x2c, 0x5a, 0x2a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
-	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x69, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x73, 0x69, 0x67, 0x72,
-	0x70, 0x63, 0x64, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x78, 0x36, 0x34,
-	0x3b, 0x78, 0x36, 0x34, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
-}
+	0x65, 0x4d, 0x73, 0x67, 0x22, 0x00, 0x12, 0x36, 0x0a, 0x0a, 0x48, 0x61, 0x73, 0x43, 0x6f, 0x6e,
+	0x74, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x65,
+	0x6e, 0x74, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x1a, 0x12, 0x2e, 0x78, 0x36, 0x34, 0x2e, 0x43,
+	0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x22, 0x00, 0x42, 0x2c,
+	0x5a, 0x2a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x69, 0x67,
+	0x72, 0x70, 0x63, 0x2f, 0x73, 0x69, 0x67, 0x72, 0x70, 0x63, 0x64, 0x2f, 0x70, 0x6b, 0x67, 0x2f,
+	0x67, 0x72, 0x70, 0x63, 0x2f, 0x78, 0x36, 0x34, 0x3b, 0x78, 0x36, 0x34, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
+})
 
 var (
 	file_message_proto_rawDescOnce sync.Once
-	file_message_proto_rawDescData = file_message_proto_rawDesc
+	file_message_proto_rawDescData []byte
 )
 
 func file_message_proto_rawDescGZIP() []byte {
 	file_message_proto_rawDescOnce.Do(func() {
-		file_message_proto_rawDescData = protoimpl.X.CompressGZIP(file_message_proto_rawDescData)
+		file_message_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_message_proto_rawDesc), len(file_message_proto_rawDesc)))
 	})
 	return file_message_proto_rawDescData
 }
 
-var file_message_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
-var file_message_proto_goTypes = []interface{}{
-	(*X64FPXReg)(nil),     // 0: x64.X64FPXReg
-	(*X64XMMReg)(nil),     // 1: x64.X64XMMReg
-	(*X64FPRegs)(nil),     // 2: x64.X64FPRegs
-	(*CPUState)(nil),      // 3: x64.CPUState
-	(*RPCHeader)(nil),     // 4: x64.RPCHeader
-	(*Addr2Sym)(nil),      // 5: x64.Addr2Sym
-	(*Page)(nil),          // 6: x64.Page
-	(*LoadLibMsg)(nil),    // 7: x64.LoadLibMsg
-	(*UserContext)(nil),   // 8: x64.UserContext
-	(*InvokeFuncMsg)(nil), // 9: x64.InvokeFuncMsg
-	(*PullPageMsg)(nil),   // 10: x64.PullPageMsg
+var file_message_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_message_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_message_proto_goTypes = []any{
+	(PageEncoding)(0),     // 0: x64.PageEncoding
+	(MappingKind)(0),      // 1: x64.MappingKind
+	(*X64FPXReg)(nil),     // 2: x64.X64FPXReg
+	(*X64XMMReg)(nil),     // 3: x64.X64XMMReg
+	(*X64FPRegs)(nil),     // 4: x64.X64FPRegs
+	(*X64XState)(nil),     // 5: x64.X64XState
+	(*CPUState)(nil),      // 6: x64.CPUState
+	(*RPCHeader)(nil),     // 7: x64.RPCHeader
+	(*HelloMsg)(nil),      // 8: x64.HelloMsg
+	(*Addr2Sym)(nil),      // 9: x64.Addr2Sym
+	(*DirtyRange)(nil),    // 10: x64.DirtyRange
+	(*Mapping)(nil),       // 11: x64.Mapping
+	(*Page)(nil),          // 12: x64.Page
+	(*LoadLibMsg)(nil),    // 13: x64.LoadLibMsg
+	(*UserContext)(nil),   // 14: x64.UserContext
+	(*InvokeFuncMsg)(nil), // 15: x64.InvokeFuncMsg
+	(*PullPageMsg)(nil),   // 16: x64.PullPageMsg
+	(*ContentHashes)(nil), // 17: x64.ContentHashes
 }
 var file_message_proto_depIdxs = []int32{
-	0,  // 0: x64.X64FPRegs.st:type_name -> x64.X64FPXReg
-	1,  // 1: x64.X64FPRegs.xmm:type_name -> x64.X64XMMReg
-	2,  // 2: x64.CPUState.fpregs:type_name -> x64.X64FPRegs
-	4,  // 3: x64.LoadLibMsg.header:type_name -> x64.RPCHeader
-	5,  // 4: x64.LoadLibMsg.addr2sym:type_name -> x64.Addr2Sym
-	3,  // 5: x64.UserContext.cpu:type_name -> x64.CPUState
-	4,  // 6: x64.InvokeFuncMsg.header:type_name -> x64.RPCHeader
-	8,  // 7: x64.InvokeFuncMsg.ctx:type_name -> x64.UserContext
-	6,  // 8: x64.InvokeFuncMsg.page:type_name -> x64.Page
-	4,  // 9: x64.PullPageMsg.header:type_name -> x64.RPCHeader
-	6,  // 10: x64.PullPageMsg.page:type_name -> x64.Page
-	7,  // 11: x64.SigRPC.LoadLib:input_type -> x64.LoadLibMsg
-	9,  // 12: x64.SigRPC.InvokeFunc:input_type -> x64.InvokeFuncMsg
-	10, // 13: x64.SigRPC.PullPage:input_type -> x64.PullPageMsg
-	7,  // 14: x64.SigRPC.LoadLib:output_type -> x64.LoadLibMsg
-	9,  // 15: x64.SigRPC.InvokeFunc:output_type -> x64.InvokeFuncMsg
-	10, // 16: x64.SigRPC.PullPage:output_type -> x64.PullPageMsg
-	14, // [14:17] is the sub-list for method output_type
-	11, // [11:14] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	2,  // 0: x64.X64FPRegs.st:type_name -> x64.X64FPXReg
+	3,  // 1: x64.X64FPRegs.xmm:type_name -> x64.X64XMMReg
+	4,  // 2: x64.CPUState.fpregs:type_name -> x64.X64FPRegs
+	5,  // 3: x64.CPUState.xstate:type_name -> x64.X64XState
+	7,  // 4: x64.HelloMsg.header:type_name -> x64.RPCHeader
+	1,  // 5: x64.Mapping.kind:type_name -> x64.MappingKind
+	0,  // 6: x64.Page.encoding:type_name -> x64.PageEncoding
+	10, // 7: x64.Page.dirty:type_name -> x64.DirtyRange
+	11, // 8: x64.Page.mapping:type_name -> x64.Mapping
+	7,  // 9: x64.LoadLibMsg.header:type_name -> x64.RPCHeader
+	9,  // 10: x64.LoadLibMsg.addr2sym:type_name -> x64.Addr2Sym
+	6,  // 11: x64.UserContext.cpu:type_name -> x64.CPUState
+	7,  // 12: x64.InvokeFuncMsg.header:type_name -> x64.RPCHeader
+	14, // 13: x64.InvokeFuncMsg.ctx:type_name -> x64.UserContext
+	12, // 14: x64.InvokeFuncMsg.page:type_name -> x64.Page
+	12, // 15: x64.InvokeFuncMsg.fault:type_name -> x64.Page
+	7,  // 16: x64.PullPageMsg.header:type_name -> x64.RPCHeader
+	12, // 17: x64.PullPageMsg.page:type_name -> x64.Page
+	7,  // 18: x64.ContentHashes.header:type_name -> x64.RPCHeader
+	13, // 19: x64.SigRPC.LoadLib:input_type -> x64.LoadLibMsg
+	15, // 20: x64.SigRPC.InvokeFunc:input_type -> x64.InvokeFuncMsg
+	16, // 21: x64.SigRPC.PullPage:input_type -> x64.PullPageMsg
+	17, // 22: x64.SigRPC.HasContent:input_type -> x64.ContentHashes
+	13, // 23: x64.SigRPC.LoadLib:output_type -> x64.LoadLibMsg
+	15, // 24: x64.SigRPC.InvokeFunc:output_type -> x64.InvokeFuncMsg
+	16, // 25: x64.SigRPC.PullPage:output_type -> x64.PullPageMsg
+	17, // 26: x64.SigRPC.HasContent:output_type -> x64.ContentHashes
+	23, // [23:27] is the sub-list for method output_type
+	19, // [19:23] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
 }
 
 func init() { file_message_proto_init() }
@@ -929,156 +1553,22 @@ func file_message_proto_init() {
 	if File_message_proto != nil {
 		return
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_message_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*X64FPXReg); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_message_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*X64XMMReg); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_message_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*X64FPRegs); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_message_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CPUState); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_message_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RPCHeader); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_message_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Addr2Sym); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_message_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Page); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_message_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*LoadLibMsg); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_message_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UserContext); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_message_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*InvokeFuncMsg); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_message_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PullPageMsg); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_message_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   11,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_message_proto_rawDesc), len(file_message_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   16,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_message_proto_goTypes,
 		DependencyIndexes: file_message_proto_depIdxs,
+		EnumInfos:         file_message_proto_enumTypes,
 		MessageInfos:      file_message_proto_msgTypes,
 	}.Build()
 	File_message_proto = out.File
-	file_message_proto_rawDesc = nil
 	file_message_proto_goTypes = nil
 	file_message_proto_depIdxs = nil
 }
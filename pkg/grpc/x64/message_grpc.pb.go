@@ -25,6 +25,7 @@ type SigRPCClient interface {
 	LoadLib(ctx context.Context, in *LoadLibMsg, opts ...grpc.CallOption) (*LoadLibMsg, error)
 	InvokeFunc(ctx context.Context, opts ...grpc.CallOption) (SigRPC_InvokeFuncClient, error)
 	PullPage(ctx context.Context, in *PullPageMsg, opts ...grpc.CallOption) (*PullPageMsg, error)
+	HasContent(ctx context.Context, in *ContentHashes, opts ...grpc.CallOption) (*ContentHashes, error)
 }
 
 type sigRPCClient struct {
@@ -84,6 +85,15 @@ func (c *sigRPCClient) PullPage(ctx context.Context, in *PullPageMsg, opts ...gr
 	return out, nil
 }
 
+func (c *sigRPCClient) HasContent(ctx context.Context, in *ContentHashes, opts ...grpc.CallOption) (*ContentHashes, error) {
+	out := new(ContentHashes)
+	err := c.cc.Invoke(ctx, "/x64.SigRPC/HasContent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SigRPCServer is the server API for SigRPC service.
 // All implementations must embed UnimplementedSigRPCServer
 // for forward compatibility
@@ -91,6 +101,7 @@ type SigRPCServer interface {
 	LoadLib(context.Context, *LoadLibMsg) (*LoadLibMsg, error)
 	InvokeFunc(SigRPC_InvokeFuncServer) error
 	PullPage(context.Context, *PullPageMsg) (*PullPageMsg, error)
+	HasContent(context.Context, *ContentHashes) (*ContentHashes, error)
 	mustEmbedUnimplementedSigRPCServer()
 }
 
@@ -107,6 +118,9 @@ func (UnimplementedSigRPCServer) InvokeFunc(SigRPC_InvokeFuncServer) error {
 func (UnimplementedSigRPCServer) PullPage(context.Context, *PullPageMsg) (*PullPageMsg, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PullPage not implemented")
 }
+func (UnimplementedSigRPCServer) HasContent(context.Context, *ContentHashes) (*ContentHashes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HasContent not implemented")
+}
 func (UnimplementedSigRPCServer) mustEmbedUnimplementedSigRPCServer() {}
 
 // UnsafeSigRPCServer may be embedded to opt out of forward compatibility for this service.
@@ -182,6 +196,24 @@ func _SigRPC_PullPage_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SigRPC_HasContent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContentHashes)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigRPCServer).HasContent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/x64.SigRPC/HasContent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigRPCServer).HasContent(ctx, req.(*ContentHashes))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SigRPC_ServiceDesc is the grpc.ServiceDesc for SigRPC service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -197,6 +229,10 @@ var SigRPC_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "PullPage",
 			Handler:    _SigRPC_PullPage_Handler,
 		},
+		{
+			MethodName: "HasContent",
+			Handler:    _SigRPC_HasContent_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
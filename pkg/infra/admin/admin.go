@@ -0,0 +1,76 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/profile"
+	"github.com/sigrpc/sigrpcd/pkg/infra/listener"
+)
+
+/*
+ * the endpoint is for the owner alone.
+ * A socket handed over by a previous sigrpcd is taken from available as it is.
+ */
+func Listen(available *[]*listener.Named, network string, addr string) (*listener.Named, error) {
+	name := listener.Name(network, addr)
+	if sock := listener.Take(available, name, network, addr); sock != nil {
+		return sock, nil
+	}
+	return listener.Listen(name, network, addr, listener.Permissions{Mode: "0600"})
+}
+
+/*
+ * GET /profile returns the working sets of all functions,
+ * narrowed by ?invokefunc_id= and ?symbol= if given
+ */
+func NewHandler(snapshot func() *profile.Profile) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/profile", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		p := snapshot()
+		query := r.URL.Query()
+		if query.Has("invokefunc_id") || query.Has("symbol") {
+			id, err := strconv.ParseUint(query.Get("invokefunc_id"), 0, 64)
+			if query.Has("invokefunc_id") && err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			functions := make([]profile.Function, 0)
+			for _, f := range p.Functions {
+				if query.Has("invokefunc_id") && f.InvokeFuncID != id {
+					continue
+				}
+				if query.Has("symbol") && f.Symbol != query.Get("symbol") {
+					continue
+				}
+				functions = append(functions, f)
+			}
+			p.Functions = functions
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(p); err != nil {
+			log.Println(err)
+		}
+	})
+	return mux
+}
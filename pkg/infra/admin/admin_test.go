@@ -0,0 +1,64 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/config"
+	"github.com/sigrpc/sigrpcd/pkg/infra/listener"
+)
+
+func TestListenOwnerOnly(t *testing.T) {
+	umask := syscall.Umask(0)
+	defer syscall.Umask(umask)
+	path := filepath.Join(t.TempDir(), "admin.sock")
+	var available []*listener.Named
+	sock, err := Listen(&available, config.NewDefaultConfig().Profile.AdminNetwork, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sock.Close()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Type() != os.ModeSocket {
+		t.Fatalf("admin endpoint is %v", info.Mode())
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Fatalf("admin socket is created with mode %o", mode)
+	}
+}
+
+func TestListenTakesHandedOver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "admin.sock")
+	handedOver, err := listener.Listen(listener.Name("unix", path), "unix", path, listener.Permissions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handedOver.Close()
+	available := []*listener.Named{handedOver}
+	sock, err := Listen(&available, "unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sock != handedOver || len(available) != 0 {
+		t.Fatalf("handed over socket is not taken, %d left", len(available))
+	}
+}
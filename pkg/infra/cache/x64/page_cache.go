@@ -0,0 +1,427 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/cache"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	cacherepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/cache"
+	pagecodec "github.com/sigrpc/sigrpcd/pkg/domain/repository/page"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+)
+
+type cachedPage struct {
+	address  uint64
+	length   uint64
+	revision uint64
+	content  []byte
+	/* a newer revision exists, the content is only a delta base */
+	stale bool
+}
+
+type clientPages struct {
+	clientID string
+	pages    map[uint64]*list.Element
+	lru      *list.List
+	bytes    uint64
+}
+
+type PageCache struct {
+	mu      sync.Mutex
+	config  cache.Config
+	delta   pagecodec.Delta
+	clients map[string]*list.Element
+	lru     *list.List
+	stats   cache.Stats
+	/* connections open per client, the pages of a client are dropped with its last one */
+	conns map[string]int
+}
+
+func NewPageCache(config cache.Config, delta pagecodec.Delta) cacherepository.PageCache {
+	return &PageCache{
+		config:  config,
+		delta:   delta,
+		clients: make(map[string]*list.Element),
+		lru:     list.New(),
+		conns:   make(map[string]int),
+	}
+}
+
+func (c *PageCache) client(clientID string, create bool) *clientPages {
+	if elem, ok := c.clients[clientID]; ok {
+		c.lru.MoveToFront(elem)
+		return elem.Value.(*clientPages)
+	}
+	if !create {
+		return nil
+	}
+	for uint64(c.lru.Len()) >= c.config.MaxClients {
+		c.evictClient(c.lru.Back())
+	}
+	client := &clientPages{
+		clientID: clientID,
+		pages:    make(map[uint64]*list.Element),
+		lru:      list.New(),
+	}
+	c.clients[clientID] = c.lru.PushFront(client)
+	c.stats.Clients++
+	return client
+}
+
+func (c *PageCache) removeClient(elem *list.Element) *clientPages {
+	client := c.lru.Remove(elem).(*clientPages)
+	delete(c.clients, client.clientID)
+	c.stats.Clients--
+	c.stats.Pages -= uint64(client.lru.Len())
+	c.stats.Bytes -= client.bytes
+	return client
+}
+
+func (c *PageCache) evictClient(elem *list.Element) {
+	client := c.removeClient(elem)
+	c.stats.Evictions += uint64(client.lru.Len())
+}
+
+func (c *PageCache) remove(client *clientPages, elem *list.Element) {
+	page := client.lru.Remove(elem).(*cachedPage)
+	delete(client.pages, page.address)
+	client.bytes -= uint64(len(page.content))
+	c.stats.Pages--
+	c.stats.Bytes -= uint64(len(page.content))
+}
+
+/* evicts pages of the least recently used clients until size more bytes fit, the client itself last */
+func (c *PageCache) makeRoom(client *clientPages, size uint64) {
+	for client.bytes+size > c.config.MaxBytesPerClient {
+		c.remove(client, client.lru.Back())
+		c.stats.Evictions++
+	}
+	for c.stats.Bytes+size > c.config.MaxBytes {
+		back := c.lru.Back()
+		victim := back.Value.(*clientPages)
+		if victim.lru.Len() == 0 {
+			c.removeClient(back)
+			continue
+		}
+		c.remove(victim, victim.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *PageCache) lookup(client *clientPages, address uint64) *cachedPage {
+	if client == nil {
+		return nil
+	}
+	elem, ok := client.pages[address]
+	if !ok {
+		return nil
+	}
+	client.lru.MoveToFront(elem)
+	return elem.Value.(*cachedPage)
+}
+
+func (c *PageCache) invalidate(page *cachedPage, revision uint64) {
+	if page.revision < revision && !page.stale {
+		page.stale = true
+		c.stats.Invalidations++
+	}
+}
+
+func (c *PageCache) put(client *clientPages, page *x64.Page) {
+	if elem, ok := client.pages[page.Address]; ok {
+		if elem.Value.(*cachedPage).revision > page.RuntimeRevision {
+			return
+		}
+		c.remove(client, elem)
+	}
+	size := uint64(len(page.Content))
+	if size > c.config.MaxBytesPerClient || size > c.config.MaxBytes {
+		return
+	}
+	c.makeRoom(client, size)
+	/* stubs unaware of ranges leave the length to the content */
+	length := page.Length
+	if length == 0 {
+		length = uint64(len(page.Content))
+	}
+	/* the content is shared with encoded replies and never modified */
+	client.pages[page.Address] = client.lru.PushFront(&cachedPage{
+		address:  page.Address,
+		length:   length,
+		revision: page.RuntimeRevision,
+		content:  append([]byte(nil), page.Content...),
+	})
+	client.bytes += size
+	c.stats.Pages++
+	c.stats.Bytes += size
+}
+
+/* expands delta pages and caches full ones, returns pages whose base is missing */
+func (c *PageCache) resolve(client *clientPages, pages []*x64.Page) []*x64.Page {
+	missing := make([]*x64.Page, 0)
+	for _, page := range pages {
+		switch page.Encoding {
+		case x64.PageEncoding_PAGE_ENCODING_XOR_RLE:
+			base := c.lookup(client, page.Address)
+			if base == nil || base.revision != page.BaseRevision {
+				missing = append(missing, page)
+				continue
+			}
+			content, err := c.delta.Decode(base.content, page.Content)
+			if err != nil {
+				missing = append(missing, page)
+				continue
+			}
+			page.Content = content
+			page.ContentSize = uint32(len(content))
+			page.Encoding = x64.PageEncoding_PAGE_ENCODING_FULL
+			page.BaseRevision = 0
+		case x64.PageEncoding_PAGE_ENCODING_FULL:
+			if len(page.Content) == 0 {
+				if base := c.lookup(client, page.Address); base != nil {
+					c.invalidate(base, page.RuntimeRevision)
+				}
+				continue
+			}
+		default:
+			missing = append(missing, page)
+			continue
+		}
+		c.put(client, page)
+	}
+	return missing
+}
+
+/* pulls the full content of pages whose delta could not be applied */
+func (c *PageCache) refetch(
+	header *x64.RPCHeader,
+	missing []*x64.Page,
+	fetch func(*msg.PullPageMsg) (*msg.PullPageMsg, error)) error {
+	if len(missing) == 0 {
+		return nil
+	}
+	req := &msg.PullPageMsg{
+		X64: &x64.PullPageMsg{
+			Header: header,
+			Page:   make([]*x64.Page, 0, len(missing)),
+		},
+	}
+	byAddress := make(map[uint64]*x64.Page, len(missing))
+	for _, page := range missing {
+		req.X64.Page = append(req.X64.Page, &x64.Page{
+			Address:         page.Address,
+			RuntimeRevision: page.RuntimeRevision,
+			ClientRevision:  page.ClientRevision,
+			Length:          page.Length,
+		})
+		byAddress[page.Address] = page
+	}
+	resp, err := fetch(req)
+	if err != nil {
+		return err
+	}
+	if resp.X64.Header != nil && resp.X64.Header.Status != msg.STATUSOK {
+		return msg.NewRPCError(resp.X64.Header.Status, fmt.Errorf(
+			"refetching pages: %s", resp.X64.Header.ErrorMessage))
+	}
+	for _, full := range resp.X64.Page {
+		page, ok := byAddress[full.Address]
+		if !ok {
+			continue
+		}
+		if full.Encoding != x64.PageEncoding_PAGE_ENCODING_FULL {
+			return fmt.Errorf("page %#x is not sent in full content", full.Address)
+		}
+		page.RuntimeRevision = full.RuntimeRevision
+		page.Content = full.Content
+		page.ContentSize = uint32(len(full.Content))
+		page.Encoding = x64.PageEncoding_PAGE_ENCODING_FULL
+		page.BaseRevision = 0
+		delete(byAddress, full.Address)
+	}
+	if len(byAddress) > 0 {
+		return fmt.Errorf("%d pages are missing in the full content", len(byAddress))
+	}
+	return nil
+}
+
+func (c *PageCache) store(clientID string, pages []*x64.Page) []*x64.Page {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.resolve(c.client(clientID, true), pages)
+}
+
+func (c *PageCache) Pull(
+	req *msg.PullPageMsg,
+	fetch func(*msg.PullPageMsg) (*msg.PullPageMsg, error)) (*msg.PullPageMsg, error) {
+	clientID := req.X64.Header.ClientId
+	hits := make([]*x64.Page, len(req.X64.Page))
+	missing := make([]*x64.Page, 0, len(req.X64.Page))
+	c.mu.Lock()
+	client := c.client(clientID, false)
+	for i, reqPage := range req.X64.Page {
+		page := c.lookup(client, reqPage.Address)
+		/* a range of another length is never answered from the cache or used as a delta base */
+		if page != nil && page.length != reqPage.Length {
+			c.remove(client, client.pages[page.address])
+			page = nil
+		}
+		if page == nil || page.revision != reqPage.RuntimeRevision || page.stale {
+			if page != nil {
+				c.invalidate(page, reqPage.RuntimeRevision)
+			}
+			missPage := &x64.Page{
+				Address:         reqPage.Address,
+				RuntimeRevision: reqPage.RuntimeRevision,
+				ClientRevision:  reqPage.ClientRevision,
+				Length:          reqPage.Length,
+			}
+			/* the stub may answer with a delta against the revision held here */
+			if page != nil && page.revision < reqPage.RuntimeRevision {
+				missPage.BaseRevision = page.revision
+			}
+			missing = append(missing, missPage)
+			c.stats.Misses++
+			continue
+		}
+		hits[i] = &x64.Page{
+			Address:         page.address,
+			RuntimeRevision: page.revision,
+			ClientRevision:  reqPage.ClientRevision,
+			Length:          page.length,
+			ContentSize:     uint32(len(page.content)),
+			Content:         page.content,
+		}
+		c.stats.Hits++
+	}
+	c.mu.Unlock()
+
+	resp := &msg.PullPageMsg{
+		X64: &x64.PullPageMsg{
+			Header: req.X64.Header,
+			Page:   make([]*x64.Page, 0, len(req.X64.Page)),
+		},
+	}
+	fetched := make(map[uint64][]*x64.Page)
+	var rest []*x64.Page
+	if len(missing) > 0 || len(req.X64.Page) == 0 {
+		fetchResp, err := fetch(&msg.PullPageMsg{
+			X64: &x64.PullPageMsg{
+				Header: req.X64.Header,
+				Page:   missing,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if fetchResp.X64.Header != nil && fetchResp.X64.Header.Status != msg.STATUSOK {
+			return fetchResp, nil
+		}
+		if fetchResp.X64.Header != nil {
+			resp.X64.Header = fetchResp.X64.Header
+		}
+		unresolved := c.store(clientID, fetchResp.X64.Page)
+		if err := c.refetch(req.X64.Header, unresolved, fetch); err != nil {
+			return nil, err
+		}
+		c.store(clientID, unresolved)
+		for _, page := range fetchResp.X64.Page {
+			fetched[page.Address] = append(fetched[page.Address], page)
+		}
+		rest = fetchResp.X64.Page
+	}
+	/* keep the order of the request */
+	used := make(map[*x64.Page]bool)
+	for i, reqPage := range req.X64.Page {
+		if hits[i] != nil {
+			resp.X64.Page = append(resp.X64.Page, hits[i])
+			continue
+		}
+		queue := fetched[reqPage.Address]
+		if len(queue) == 0 {
+			continue
+		}
+		resp.X64.Page = append(resp.X64.Page, queue[0])
+		used[queue[0]] = true
+		fetched[reqPage.Address] = queue[1:]
+	}
+	for _, page := range rest {
+		if !used[page] {
+			resp.X64.Page = append(resp.X64.Page, page)
+		}
+	}
+	return resp, nil
+}
+
+/* applies pages of an InvokeFunc reply, which may be deltas against revisions sent before */
+func (c *PageCache) Update(
+	clientID string,
+	invokeFunc *msg.InvokeFuncMsg,
+	fetch func(*msg.PullPageMsg) (*msg.PullPageMsg, error)) error {
+	if invokeFunc == nil || invokeFunc.X64 == nil {
+		return nil
+	}
+	unresolved := c.store(clientID, invokeFunc.X64.Page)
+	header := &x64.RPCHeader{
+		MsgType:  msg.PULLPAGE,
+		ClientId: clientID,
+	}
+	if err := c.refetch(header, unresolved, fetch); err != nil {
+		return err
+	}
+	c.store(clientID, unresolved)
+	return nil
+}
+
+/* only the revision the stub holds as it is, never a stale one or a range of another length */
+func (c *PageCache) Base(clientID string, address uint64, revision uint64, length uint64) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	page := c.lookup(c.client(clientID, false), address)
+	if page == nil || page.stale || page.revision != revision || page.length != length {
+		return nil, false
+	}
+	return page.content, true
+}
+
+func (c *PageCache) Attach(clientID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conns[clientID]++
+}
+
+/* the last connection of a client leaves nothing behind, a later one refetches what it needs */
+func (c *PageCache) Detach(clientID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conns[clientID] > 1 {
+		c.conns[clientID]--
+		return
+	}
+	delete(c.conns, clientID)
+	if elem, ok := c.clients[clientID]; ok {
+		c.removeClient(elem)
+	}
+}
+
+func (c *PageCache) Stats() cache.Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
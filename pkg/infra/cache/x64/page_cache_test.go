@@ -0,0 +1,180 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/cache"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+	pagecodec "github.com/sigrpc/sigrpcd/pkg/infra/page/x64"
+)
+
+const TESTPAGESIZE = 0x1000
+
+/* the stub answers every pull with full pages of the requested revision */
+type testStub struct {
+	pulls int
+}
+
+func (s *testStub) fetch(req *msg.PullPageMsg) (*msg.PullPageMsg, error) {
+	s.pulls++
+	resp := &msg.PullPageMsg{X64: &x64.PullPageMsg{Header: req.X64.Header}}
+	for _, page := range req.X64.Page {
+		resp.X64.Page = append(resp.X64.Page, &x64.Page{
+			Address:         page.Address,
+			RuntimeRevision: page.RuntimeRevision,
+			Length:          page.Length,
+			ContentSize:     TESTPAGESIZE,
+			Content:         bytes.Repeat([]byte{byte(page.Address >> 12)}, TESTPAGESIZE),
+		})
+	}
+	return resp, nil
+}
+
+func pull(t *testing.T, c *PageCache, stub *testStub, clientID string, addresses ...uint64) {
+	t.Helper()
+	req := &msg.PullPageMsg{X64: &x64.PullPageMsg{Header: &x64.RPCHeader{MsgType: msg.PULLPAGE, ClientId: clientID}}}
+	for _, address := range addresses {
+		req.X64.Page = append(req.X64.Page, &x64.Page{Address: address, RuntimeRevision: 1, Length: TESTPAGESIZE})
+	}
+	resp, err := c.Pull(req, stub.fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.X64.Page) != len(addresses) {
+		t.Fatalf("got %d pages, want %d", len(resp.X64.Page), len(addresses))
+	}
+}
+
+func newTestCache(config cache.Config) *PageCache {
+	config.Enabled = true
+	return NewPageCache(config, pagecodec.NewXORRLEDelta()).(*PageCache)
+}
+
+func TestPageCacheBoundsBytesPerClient(t *testing.T) {
+	c := newTestCache(cache.Config{MaxClients: 4, MaxBytesPerClient: 2 * TESTPAGESIZE, MaxBytes: 8 * TESTPAGESIZE})
+	stub := &testStub{}
+	pull(t, c, stub, "a", 0x1000, 0x2000, 0x3000)
+	stats := c.Stats()
+	if stats.Bytes != 2*TESTPAGESIZE || stats.Pages != 2 || stats.Evictions != 1 {
+		t.Fatalf("stats %s", stats)
+	}
+	/* the most recent pages stay */
+	pull(t, c, stub, "a", 0x2000, 0x3000)
+	if stub.pulls != 1 {
+		t.Fatalf("%d pulls reached the stub, want 1", stub.pulls)
+	}
+}
+
+func TestPageCacheBoundsBytes(t *testing.T) {
+	c := newTestCache(cache.Config{MaxClients: 4, MaxBytesPerClient: 2 * TESTPAGESIZE, MaxBytes: 3 * TESTPAGESIZE})
+	stub := &testStub{}
+	pull(t, c, stub, "a", 0x1000, 0x2000)
+	pull(t, c, stub, "b", 0x1000, 0x2000)
+	stats := c.Stats()
+	if stats.Bytes != 3*TESTPAGESIZE || stats.Pages != 3 || stats.Clients != 2 {
+		t.Fatalf("stats %s", stats)
+	}
+	/* the least recently used client lost its oldest page */
+	pull(t, c, stub, "a", 0x2000)
+	if stub.pulls != 2 {
+		t.Fatalf("%d pulls reached the stub, want 2", stub.pulls)
+	}
+	pull(t, c, stub, "a", 0x1000)
+	if stub.pulls != 3 {
+		t.Fatalf("%d pulls reached the stub, want 3", stub.pulls)
+	}
+}
+
+func TestPageCacheSkipsPagesOverBound(t *testing.T) {
+	c := newTestCache(cache.Config{MaxClients: 4, MaxBytesPerClient: TESTPAGESIZE - 1, MaxBytes: TESTPAGESIZE - 1})
+	pull(t, c, &testStub{}, "a", 0x1000)
+	if stats := c.Stats(); stats.Bytes != 0 || stats.Pages != 0 {
+		t.Fatalf("stats %s", stats)
+	}
+}
+
+func TestPageCacheBase(t *testing.T) {
+	c := newTestCache(cache.NewDefaultConfig())
+	pull(t, c, &testStub{}, "a", 0x1000)
+	base, ok := c.Base("a", 0x1000, 1, TESTPAGESIZE)
+	if !ok || !bytes.Equal(base, bytes.Repeat([]byte{1}, TESTPAGESIZE)) {
+		t.Fatal("the pulled revision is not a base")
+	}
+	tests := []struct {
+		clientID string
+		address  uint64
+		revision uint64
+		length   uint64
+	}{
+		{"b", 0x1000, 1, TESTPAGESIZE},
+		{"a", 0x2000, 1, TESTPAGESIZE},
+		{"a", 0x1000, 2, TESTPAGESIZE},
+		{"a", 0x1000, 1, TESTPAGESIZE / 2},
+	}
+	for _, tt := range tests {
+		if _, ok := c.Base(tt.clientID, tt.address, tt.revision, tt.length); ok {
+			t.Errorf("%s %#x revision %d length %d is a base", tt.clientID, tt.address, tt.revision, tt.length)
+		}
+	}
+	/* a newer revision exists on the stub */
+	c.Update("a", &msg.InvokeFuncMsg{X64: &x64.InvokeFuncMsg{
+		Page: []*x64.Page{{Address: 0x1000, RuntimeRevision: 2}},
+	}}, nil)
+	if _, ok := c.Base("a", 0x1000, 1, TESTPAGESIZE); ok {
+		t.Fatal("a stale revision is a base")
+	}
+}
+
+func TestPageCacheDetach(t *testing.T) {
+	c := newTestCache(cache.Config{MaxClients: 4, MaxBytesPerClient: 4 * TESTPAGESIZE, MaxBytes: 8 * TESTPAGESIZE})
+	stub := &testStub{}
+	c.Attach("a")
+	c.Attach("b")
+	pull(t, c, stub, "a", 0x1000, 0x2000)
+	pull(t, c, stub, "b", 0x1000)
+	c.Detach("a")
+	c.Detach("unknown")
+	stats := c.Stats()
+	if stats.Bytes != TESTPAGESIZE || stats.Pages != 1 || stats.Clients != 1 || stats.Evictions != 0 {
+		t.Fatalf("stats %s", stats)
+	}
+	pull(t, c, stub, "a", 0x1000)
+	pull(t, c, stub, "b", 0x1000)
+	if stub.pulls != 3 {
+		t.Fatalf("%d pulls reached the stub, want 3", stub.pulls)
+	}
+}
+
+/* a client pulling on short connections keeps its pages while its invocation stream is open */
+func TestPageCacheSharedByConnections(t *testing.T) {
+	c := newTestCache(cache.Config{MaxClients: 4, MaxBytesPerClient: 4 * TESTPAGESIZE, MaxBytes: 8 * TESTPAGESIZE})
+	stub := &testStub{}
+	c.Attach("a")
+	c.Attach("a")
+	pull(t, c, stub, "a", 0x1000)
+	c.Detach("a")
+	pull(t, c, stub, "a", 0x1000)
+	if stats := c.Stats(); stub.pulls != 1 || stats.Hits != 1 {
+		t.Fatalf("%d pulls reached the stub, stats %s", stub.pulls, stats)
+	}
+	c.Detach("a")
+	if stats := c.Stats(); stats.Clients != 0 || stats.Bytes != 0 {
+		t.Fatalf("stats %s after the last connection, want none", stats)
+	}
+}
@@ -0,0 +1,463 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	model "github.com/sigrpc/sigrpcd/pkg/domain/model/config"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/page"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/prefetch"
+)
+
+type option struct {
+	flag  string
+	env   string
+	usage string
+	set   func(*model.Config, string) error
+}
+
+var logFlags = map[string]int{
+	"date":         log.Ldate,
+	"time":         log.Ltime,
+	"microseconds": log.Lmicroseconds,
+	"longfile":     log.Llongfile,
+	"shortfile":    log.Lshortfile,
+	"utc":          log.LUTC,
+	"msgprefix":    log.Lmsgprefix,
+}
+
+var listenNetworks = map[string]bool{
+	"unix":       true,
+	"unixpacket": true,
+	"tcp":        true,
+	"tcp4":       true,
+	"tcp6":       true,
+}
+
+/* the admin endpoint has no authentication, tcp is only served to the host itself */
+func loopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func firstListener(conf *model.Config) *model.Listener {
+	if len(conf.Listeners) == 0 {
+		conf.Listeners = append(conf.Listeners, model.Listener{})
+	}
+	return &conf.Listeners[0]
+}
+
+func setString(field func(*model.Config) *string) func(*model.Config, string) error {
+	return func(conf *model.Config, value string) error {
+		*field(conf) = value
+		return nil
+	}
+}
+
+func setBool(field func(*model.Config) *bool) func(*model.Config, string) error {
+	return func(conf *model.Config, value string) error {
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*field(conf) = v
+		return nil
+	}
+}
+
+func setInt(field func(*model.Config) *int) func(*model.Config, string) error {
+	return func(conf *model.Config, value string) error {
+		v, err := strconv.ParseInt(value, 0, 0)
+		if err != nil {
+			return err
+		}
+		*field(conf) = int(v)
+		return nil
+	}
+}
+
+func setUint64(field func(*model.Config) *uint64) func(*model.Config, string) error {
+	return func(conf *model.Config, value string) error {
+		v, err := strconv.ParseUint(value, 0, 64)
+		if err != nil {
+			return err
+		}
+		*field(conf) = v
+		return nil
+	}
+}
+
+func setUint32(field func(*model.Config) *uint32) func(*model.Config, string) error {
+	return func(conf *model.Config, value string) error {
+		v, err := strconv.ParseUint(value, 0, 32)
+		if err != nil {
+			return err
+		}
+		*field(conf) = uint32(v)
+		return nil
+	}
+}
+
+func setUint32List(field func(*model.Config) *[]uint32) func(*model.Config, string) error {
+	return func(conf *model.Config, value string) error {
+		list := make([]uint32, 0)
+		for _, str := range strings.Split(value, ",") {
+			str = strings.TrimSpace(str)
+			if len(str) == 0 {
+				continue
+			}
+			v, err := strconv.ParseUint(str, 0, 32)
+			if err != nil {
+				return err
+			}
+			list = append(list, uint32(v))
+		}
+		*field(conf) = list
+		return nil
+	}
+}
+
+func setDuration(field func(*model.Config) *model.Duration) func(*model.Config, string) error {
+	return func(conf *model.Config, value string) error {
+		v, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field(conf) = model.Duration(v)
+		return nil
+	}
+}
+
+var options = []option{
+	{"listen-name", "RPC_CLIENT_NAME", "name of a socket-activated client listener",
+		setString(func(c *model.Config) *string { return &firstListener(c).Name })},
+	{"listen-network", "RPC_CLIENT_NETWORK", "network of the client listener",
+		setString(func(c *model.Config) *string { return &firstListener(c).Network })},
+	{"listen-addr", "RPC_CLIENT_ADDR", "address of the client listener",
+		setString(func(c *model.Config) *string { return &firstListener(c).Addr })},
+	{"listen-mode", "RPC_CLIENT_MODE", "octal file mode of the client socket",
+		setString(func(c *model.Config) *string { return &firstListener(c).Mode })},
+	{"listen-owner", "RPC_CLIENT_OWNER", "owner (name or uid) of the client socket",
+		setString(func(c *model.Config) *string { return &firstListener(c).Owner })},
+	{"listen-group", "RPC_CLIENT_GROUP", "group (name or gid) of the client socket",
+		setString(func(c *model.Config) *string { return &firstListener(c).Group })},
+	{"stub-addr", "RPC_STUB_ADDR", "address of the SigRPC stub",
+		setString(func(c *model.Config) *string { return &c.Stub.Addr })},
+	{"stub-max-recv-msg-size", "RPC_STUB_MAX_RECV_MSG_SIZE", "max gRPC message size received from the stub",
+		setInt(func(c *model.Config) *int { return &c.Stub.MaxRecvMsgSize })},
+	{"stub-max-send-msg-size", "RPC_STUB_MAX_SEND_MSG_SIZE", "max gRPC message size sent to the stub",
+		setInt(func(c *model.Config) *int { return &c.Stub.MaxSendMsgSize })},
+	{"stub-page-elision", "RPC_STUB_PAGE_ELISION", "send zero and duplicate pages to the stub in their short forms",
+		setBool(func(c *model.Config) *bool { return &c.Stub.PageElision })},
+	{"stub-page-mappings", "RPC_STUB_PAGE_MAPPINGS", "send the protection and mapping of pages read from /proc/<pid>/maps to the stub",
+		setBool(func(c *model.Config) *bool { return &c.Stub.PageMappings })},
+	{"stub-tls", "RPC_STUB_TLS", "use TLS for the stub connection",
+		setBool(func(c *model.Config) *bool { return &c.Stub.TLS.Enabled })},
+	{"stub-tls-ca", "RPC_STUB_TLS_CA", "CA bundle verifying the stub certificate",
+		setString(func(c *model.Config) *string { return &c.Stub.TLS.CAFile })},
+	{"stub-tls-cert", "RPC_STUB_TLS_CERT", "client certificate presented to the stub",
+		setString(func(c *model.Config) *string { return &c.Stub.TLS.CertFile })},
+	{"stub-tls-key", "RPC_STUB_TLS_KEY", "key of the client certificate",
+		setString(func(c *model.Config) *string { return &c.Stub.TLS.KeyFile })},
+	{"stub-tls-server-name", "RPC_STUB_TLS_SERVER_NAME", "server name expected from the stub certificate",
+		setString(func(c *model.Config) *string { return &c.Stub.TLS.ServerName })},
+	{"request-timeout", "RPC_REQUEST_TIMEOUT", "timeout of a client connection",
+		setDuration(func(c *model.Config) *model.Duration { return &c.Timeout.Request })},
+	{"shutdown-grace", "RPC_SHUTDOWN_GRACE", "grace period for in-flight requests on shutdown",
+		setDuration(func(c *model.Config) *model.Duration { return &c.Timeout.ShutdownGrace })},
+	{"upgrade-timeout", "RPC_UPGRADE_TIMEOUT", "time to wait for a new sigrpcd to take over the listeners on SIGUSR2",
+		setDuration(func(c *model.Config) *model.Duration { return &c.Timeout.Upgrade })},
+	{"max-frame-size", "RPC_MAX_FRAME_SIZE", "max payload size of a client frame",
+		setUint64(func(c *model.Config) *uint64 { return &c.Limits.MaxFrameSize })},
+	{"max-page-count", "RPC_MAX_PAGE_COUNT", "max page count of a client frame",
+		setUint64(func(c *model.Config) *uint64 { return &c.Limits.MaxPageCount })},
+	{"max-page-content-size", "RPC_MAX_PAGE_CONTENT_SIZE", "max content size of a page",
+		setUint32(func(c *model.Config) *uint32 { return &c.Limits.MaxPageContentSize })},
+	{"max-library-name-size", "RPC_MAX_LIBRARY_NAME_SIZE", "max length of a library name",
+		setUint64(func(c *model.Config) *uint64 { return &c.Limits.MaxLibraryNameSize })},
+	{"max-conn-memory", "RPC_MAX_CONN_MEMORY", "max memory held by a client connection",
+		setUint64(func(c *model.Config) *uint64 { return &c.Limits.MaxConnMemory })},
+	{"max-memory", "RPC_MAX_MEMORY", "max memory held by all client connections",
+		setUint64(func(c *model.Config) *uint64 { return &c.Limits.MaxMemory })},
+	{"allow-uids", "RPC_ALLOW_UIDS", "comma separated uids allowed to connect (all if both lists are empty)",
+		setUint32List(func(c *model.Config) *[]uint32 { return &c.Auth.AllowUIDs })},
+	{"allow-gids", "RPC_ALLOW_GIDS", "comma separated gids allowed to connect (all if both lists are empty)",
+		setUint32List(func(c *model.Config) *[]uint32 { return &c.Auth.AllowGIDs })},
+	{"page-cache", "RPC_PAGE_CACHE", "cache pulled pages per client",
+		setBool(func(c *model.Config) *bool { return &c.PageCache.Enabled })},
+	{"page-cache-max-clients", "RPC_PAGE_CACHE_MAX_CLIENTS", "max clients holding cached pages",
+		setUint64(func(c *model.Config) *uint64 { return &c.PageCache.MaxClients })},
+	{"page-cache-max-bytes-per-client", "RPC_PAGE_CACHE_MAX_BYTES_PER_CLIENT", "max bytes of cached pages of a client",
+		setUint64(func(c *model.Config) *uint64 { return &c.PageCache.MaxBytesPerClient })},
+	{"page-cache-max-bytes", "RPC_PAGE_CACHE_MAX_BYTES", "max bytes of cached pages of all clients",
+		setUint64(func(c *model.Config) *uint64 { return &c.PageCache.MaxBytes })},
+	{"page-cache-deltas", "RPC_PAGE_CACHE_DELTAS", "exchange pages with the stub as deltas against cached revisions (requires page-cache)",
+		setBool(func(c *model.Config) *bool { return &c.PageCache.Deltas })},
+	{"content-store", "RPC_CONTENT_STORE", "keep contents of read-only file pages by hash and exchange hash references with the stub (pages need a mapping, see stub-page-mappings)",
+		setBool(func(c *model.Config) *bool { return &c.ContentStore.Enabled })},
+	{"content-store-max-memory", "RPC_CONTENT_STORE_MAX_MEMORY", "max bytes of contents kept in memory",
+		setUint64(func(c *model.Config) *uint64 { return &c.ContentStore.MaxMemory })},
+	{"content-store-dir", "RPC_CONTENT_STORE_DIR", "directory keeping contents on disk (memory only if empty)",
+		setString(func(c *model.Config) *string { return &c.ContentStore.Dir })},
+	{"content-store-max-disk", "RPC_CONTENT_STORE_MAX_DISK", "max bytes of contents kept on disk",
+		setUint64(func(c *model.Config) *uint64 { return &c.ContentStore.MaxDisk })},
+	{"prefetch-policy", "RPC_PREFETCH_POLICY", "pages returned along with a pulled page (none, sequential, history)",
+		setString(func(c *model.Config) *string { return &c.Prefetch.Policy })},
+	{"prefetch-window", "RPC_PREFETCH_WINDOW", "max pages prefetched by a pull",
+		setUint64(func(c *model.Config) *uint64 { return &c.Prefetch.Window })},
+	{"prefetch-max-history-pages", "RPC_PREFETCH_MAX_HISTORY_PAGES", "max pages remembered per function of a client",
+		setUint64(func(c *model.Config) *uint64 { return &c.Prefetch.MaxHistoryPages })},
+	{"prefetch-max-histories", "RPC_PREFETCH_MAX_HISTORIES", "max functions whose history is remembered per client",
+		setUint64(func(c *model.Config) *uint64 { return &c.Prefetch.MaxHistories })},
+	{"prefetch-max-clients", "RPC_PREFETCH_MAX_CLIENTS", "max clients whose history is remembered",
+		setUint64(func(c *model.Config) *uint64 { return &c.Prefetch.MaxClients })},
+	{"profile", "RPC_PROFILE", "record pages used by each function",
+		setBool(func(c *model.Config) *bool { return &c.Profile.Enabled })},
+	{"profile-file", "RPC_PROFILE_FILE", "file the profile is written to (not written if empty)",
+		setString(func(c *model.Config) *string { return &c.Profile.File })},
+	{"profile-interval", "RPC_PROFILE_INTERVAL", "interval of writing the profile file",
+		setDuration(func(c *model.Config) *model.Duration { return &c.Profile.Interval })},
+	{"profile-admin-network", "RPC_PROFILE_ADMIN_NETWORK", "network of the admin endpoint serving the profile (tcp only on loopback)",
+		setString(func(c *model.Config) *string { return &c.Profile.AdminNetwork })},
+	{"profile-admin-addr", "RPC_PROFILE_ADMIN_ADDR", "address of the admin endpoint serving the profile (not served if empty)",
+		setString(func(c *model.Config) *string { return &c.Profile.AdminAddr })},
+	{"profile-max-functions", "RPC_PROFILE_MAX_FUNCTIONS", "max functions profiled",
+		setUint64(func(c *model.Config) *uint64 { return &c.Profile.MaxFunctions })},
+	{"profile-max-pages-per-function", "RPC_PROFILE_MAX_PAGES_PER_FUNCTION", "max pages profiled per function",
+		setUint64(func(c *model.Config) *uint64 { return &c.Profile.MaxPagesPerFunction })},
+	{"direct-memory", "RPC_DIRECT_MEMORY", "let clients negotiate that the daemon reads and writes their memory for the stub",
+		setBool(func(c *model.Config) *bool { return &c.DirectMemory.Enabled })},
+	{"direct-memory-allow-uids", "RPC_DIRECT_MEMORY_ALLOW_UIDS", "comma separated uids allowed direct memory access (all if both lists are empty)",
+		setUint32List(func(c *model.Config) *[]uint32 { return &c.DirectMemory.Allow.AllowUIDs })},
+	{"direct-memory-allow-gids", "RPC_DIRECT_MEMORY_ALLOW_GIDS", "comma separated gids allowed direct memory access (all if both lists are empty)",
+		setUint32List(func(c *model.Config) *[]uint32 { return &c.DirectMemory.Allow.AllowGIDs })},
+	{"direct-memory-max-fault-rounds", "RPC_DIRECT_MEMORY_MAX_FAULT_ROUNDS", "max replies with faults answered within one invocation",
+		setUint64(func(c *model.Config) *uint64 { return &c.DirectMemory.MaxFaultRounds })},
+	{"shared-memory", "RPC_SHARED_MEMORY", "let unix socket clients negotiate passing page contents through a memfd",
+		setBool(func(c *model.Config) *bool { return &c.SharedMemory.Enabled })},
+	{"log-file", "RPC_LOG_FILE", "log file (stderr if empty)",
+		setString(func(c *model.Config) *string { return &c.Log.File })},
+	{"log-flags", "RPC_LOG_FLAGS", "comma separated log flags (date,time,microseconds,longfile,shortfile,utc,msgprefix)",
+		setString(func(c *model.Config) *string { return &c.Log.Flags })},
+}
+
+/* precedence: defaults < config file < environment variables < flags */
+func Load(name string, args []string) (*model.Config, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	path := fs.String("config", os.Getenv("RPC_CONFIG"), "JSON configuration file (env RPC_CONFIG)")
+	byFlag := make(map[string]option, len(options))
+	for _, opt := range options {
+		fs.String(opt.flag, "", opt.usage+" (env "+opt.env+")")
+		byFlag[opt.flag] = opt
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	conf := model.NewDefaultConfig()
+	if len(*path) > 0 {
+		byteConf, err := os.ReadFile(*path)
+		if err != nil {
+			return nil, err
+		}
+		decoder := json.NewDecoder(bytes.NewReader(byteConf))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(conf); err != nil {
+			return nil, fmt.Errorf("%s: %w", *path, err)
+		}
+	}
+	for _, opt := range options {
+		value := os.Getenv(opt.env)
+		if len(value) == 0 {
+			continue
+		}
+		if err := opt.set(conf, value); err != nil {
+			return nil, fmt.Errorf("%s: %w", opt.env, err)
+		}
+	}
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		opt, ok := byFlag[f.Name]
+		if !ok || err != nil {
+			return
+		}
+		if setErr := opt.set(conf, f.Value.String()); setErr != nil {
+			err = fmt.Errorf("-%s: %w", f.Name, setErr)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range conf.Listeners {
+		if len(conf.Listeners[i].Network) == 0 {
+			conf.Listeners[i].Network = "unix"
+		}
+	}
+	return conf, nil
+}
+
+func parseLogFlags(str string) (int, error) {
+	flags := 0
+	for _, name := range strings.Split(str, ",") {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 {
+			continue
+		}
+		logFlag, ok := logFlags[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown log flag %q", name)
+		}
+		flags |= logFlag
+	}
+	return flags, nil
+}
+
+func Validate(conf *model.Config) error {
+	var errs []error
+	if len(conf.Listeners) == 0 {
+		errs = append(errs, errors.New("no listener is configured"))
+	}
+	for i, listener := range conf.Listeners {
+		if !listenNetworks[listener.Network] {
+			errs = append(errs, fmt.Errorf("listeners[%d]: unsupported network %q", i, listener.Network))
+		}
+		if len(listener.Addr) == 0 && len(listener.Name) == 0 {
+			errs = append(errs, fmt.Errorf("listeners[%d]: neither addr nor name is set", i))
+		}
+		if len(listener.Mode) > 0 {
+			if _, err := strconv.ParseUint(listener.Mode, 8, 32); err != nil {
+				errs = append(errs, fmt.Errorf("listeners[%d]: invalid mode %q", i, listener.Mode))
+			}
+		}
+	}
+	if len(conf.Stub.Addr) == 0 {
+		errs = append(errs, errors.New("stub.addr is empty"))
+	}
+	if conf.Stub.MaxRecvMsgSize <= 0 || conf.Stub.MaxSendMsgSize <= 0 {
+		errs = append(errs, errors.New("stub message sizes must be positive"))
+	}
+	if (len(conf.Stub.TLS.CertFile) == 0) != (len(conf.Stub.TLS.KeyFile) == 0) {
+		errs = append(errs, errors.New("stub.tls.cert_file and stub.tls.key_file must be set together"))
+	}
+	if conf.Timeout.Request <= 0 {
+		errs = append(errs, errors.New("timeout.request must be positive"))
+	}
+	if conf.Timeout.ShutdownGrace < 0 {
+		errs = append(errs, errors.New("timeout.shutdown_grace must not be negative"))
+	}
+	if conf.Timeout.Upgrade <= 0 {
+		errs = append(errs, errors.New("timeout.upgrade must be positive"))
+	}
+	if conf.Limits.MaxFrameSize == 0 ||
+		conf.Limits.MaxPageCount == 0 ||
+		conf.Limits.MaxPageContentSize == 0 ||
+		conf.Limits.MaxLibraryNameSize == 0 ||
+		conf.Limits.MaxConnMemory == 0 ||
+		conf.Limits.MaxMemory == 0 {
+		errs = append(errs, errors.New("limits must be positive"))
+	}
+	/* larger sizes would run into the flag bits of the content size on the wire */
+	if conf.Limits.MaxPageContentSize > page.CONTENTSIZEMASK ||
+		conf.Limits.MaxPageContentSize < uint32(os.Getpagesize()) {
+		errs = append(errs, fmt.Errorf("limits.max_page_content_size must be within %d-%d",
+			os.Getpagesize(), page.CONTENTSIZEMASK))
+	}
+	if conf.Limits.MaxFrameSize > conf.Limits.MaxConnMemory || conf.Limits.MaxConnMemory > conf.Limits.MaxMemory {
+		errs = append(errs, errors.New("limits must grow from max_frame_size to max_conn_memory to max_memory"))
+	}
+	if conf.PageCache.Enabled && (conf.PageCache.MaxClients == 0 ||
+		conf.PageCache.MaxBytesPerClient == 0 || conf.PageCache.MaxBytes == 0) {
+		errs = append(errs, errors.New("page_cache limits must be positive"))
+	}
+	if conf.PageCache.Deltas && !conf.PageCache.Enabled {
+		errs = append(errs, errors.New("page_cache.deltas requires page_cache.enabled"))
+	}
+	if conf.PageCache.Enabled && conf.PageCache.MaxBytesPerClient > conf.PageCache.MaxBytes {
+		errs = append(errs, errors.New("page_cache.max_bytes_per_client must not exceed max_bytes"))
+	}
+	if conf.ContentStore.Enabled && conf.ContentStore.MaxMemory == 0 {
+		errs = append(errs, errors.New("content_store.max_memory must be positive"))
+	}
+	if conf.ContentStore.Enabled && len(conf.ContentStore.Dir) > 0 && conf.ContentStore.MaxDisk == 0 {
+		errs = append(errs, errors.New("content_store.max_disk must be positive"))
+	}
+	if conf.DirectMemory.Enabled && conf.DirectMemory.MaxFaultRounds == 0 {
+		errs = append(errs, errors.New("direct_memory.max_fault_rounds must be positive"))
+	}
+	switch conf.Prefetch.Policy {
+	case prefetch.POLICYNONE:
+	case prefetch.POLICYSEQUENTIAL, prefetch.POLICYHISTORY:
+		if conf.Prefetch.Window == 0 || conf.Prefetch.MaxHistoryPages == 0 ||
+			conf.Prefetch.MaxHistories == 0 || conf.Prefetch.MaxClients == 0 {
+			errs = append(errs, errors.New("prefetch limits must be positive"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown prefetch policy %q", conf.Prefetch.Policy))
+	}
+	if conf.Profile.Enabled {
+		if conf.Profile.MaxFunctions == 0 || conf.Profile.MaxPagesPerFunction == 0 {
+			errs = append(errs, errors.New("profile limits must be positive"))
+		}
+		if len(conf.Profile.File) > 0 && conf.Profile.Interval <= 0 {
+			errs = append(errs, errors.New("profile.interval must be positive"))
+		}
+		if len(conf.Profile.AdminAddr) > 0 && !listenNetworks[conf.Profile.AdminNetwork] {
+			errs = append(errs, fmt.Errorf("profile: unsupported admin network %q", conf.Profile.AdminNetwork))
+		} else if len(conf.Profile.AdminAddr) > 0 && strings.HasPrefix(conf.Profile.AdminNetwork, "tcp") &&
+			!loopback(conf.Profile.AdminAddr) {
+			errs = append(errs, fmt.Errorf("profile: admin address %q is not on loopback", conf.Profile.AdminAddr))
+		}
+	}
+	if _, err := parseLogFlags(conf.Log.Flags); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func SetupLog(conf model.Log) error {
+	flags, err := parseLogFlags(conf.Flags)
+	if err != nil {
+		return err
+	}
+	if len(conf.File) > 0 {
+		file, err := os.OpenFile(conf.File, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			return err
+		}
+		log.SetOutput(file)
+	}
+	log.SetFlags(flags)
+	return nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	model "github.com/sigrpc/sigrpcd/pkg/domain/model/config"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/page"
+)
+
+func TestLoadPrecedence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "sigrpcd.json")
+	if err := os.WriteFile(file,
+		[]byte(`{"stub": {"addr": "file:1"}, "timeout": {"request": "5s"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		env     map[string]string
+		args    []string
+		addr    string
+		request time.Duration
+	}{
+		{"defaults", nil, nil, "", 30 * time.Second},
+		{"file over defaults", nil, []string{"-config", file}, "file:1", 5 * time.Second},
+		{"file named by the environment", map[string]string{"RPC_CONFIG": file}, nil, "file:1", 5 * time.Second},
+		{"environment over file", map[string]string{"RPC_STUB_ADDR": "env:1"},
+			[]string{"-config", file}, "env:1", 5 * time.Second},
+		{"flags over environment", map[string]string{"RPC_STUB_ADDR": "env:1", "RPC_REQUEST_TIMEOUT": "7s"},
+			[]string{"-config", file, "-stub-addr", "flag:1"}, "flag:1", 7 * time.Second},
+		{"flags over defaults", nil, []string{"-request-timeout", "9s"}, "", 9 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			/* empty variables are the same as unset ones */
+			for _, env := range []string{"RPC_CONFIG", "RPC_STUB_ADDR", "RPC_REQUEST_TIMEOUT"} {
+				t.Setenv(env, tt.env[env])
+			}
+			conf, err := Load("sigrpcd", tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if conf.Stub.Addr != tt.addr || time.Duration(conf.Timeout.Request) != tt.request {
+				t.Fatalf("stub.addr %q and timeout.request %v, want %q and %v",
+					conf.Stub.Addr, time.Duration(conf.Timeout.Request), tt.addr, tt.request)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	unknown := filepath.Join(t.TempDir(), "unknown.json")
+	if err := os.WriteFile(unknown, []byte(`{"stub": {"address": "127.0.0.1:1"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+	}{
+		{"missing file", nil, []string{"-config", filepath.Join(t.TempDir(), "missing.json")}},
+		{"unknown field in file", nil, []string{"-config", unknown}},
+		{"malformed environment", map[string]string{"RPC_MAX_FRAME_SIZE": "large"}, nil},
+		{"malformed flag", nil, []string{"-request-timeout", "soon"}},
+		{"unknown flag", nil, []string{"-no-such-flag"}},
+		{"argument", nil, []string{"extra"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, env := range []string{"RPC_CONFIG", "RPC_MAX_FRAME_SIZE"} {
+				t.Setenv(env, tt.env[env])
+			}
+			if _, err := Load("sigrpcd", tt.args); err == nil {
+				t.Fatal("loaded without an error")
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*model.Config)
+		want   string
+	}{
+		{"valid", func(*model.Config) {}, ""},
+		{"no listener", func(c *model.Config) { c.Listeners = nil }, "no listener"},
+		{"unsupported network", func(c *model.Config) { c.Listeners[0].Network = "udp" }, "unsupported network"},
+		{"listener without address", func(c *model.Config) { c.Listeners[0].Addr = "" }, "neither addr nor name"},
+		{"invalid mode", func(c *model.Config) { c.Listeners[0].Mode = "0999" }, "invalid mode"},
+		{"no stub", func(c *model.Config) { c.Stub.Addr = "" }, "stub.addr"},
+		{"certificate without key", func(c *model.Config) { c.Stub.TLS.CertFile = "cert.pem" }, "key_file"},
+		{"no request timeout", func(c *model.Config) { c.Timeout.Request = 0 }, "timeout.request"},
+		{"page content over the size mask", func(c *model.Config) {
+			c.Limits.MaxPageContentSize = page.CONTENTSIZEMASK + 1
+		}, "max_page_content_size"},
+		{"page content below a page", func(c *model.Config) {
+			c.Limits.MaxPageContentSize = uint32(os.Getpagesize()) - 1
+		}, "max_page_content_size"},
+		{"page content of the size mask", func(c *model.Config) {
+			c.Limits.MaxPageContentSize = page.CONTENTSIZEMASK
+		}, ""},
+		{"frame over connection memory", func(c *model.Config) {
+			c.Limits.MaxFrameSize = c.Limits.MaxConnMemory + 1
+		}, "max_conn_memory"},
+		{"deltas without page cache", func(c *model.Config) {
+			c.PageCache.Enabled = false
+			c.PageCache.Deltas = true
+		}, "page_cache.deltas"},
+		{"unknown prefetch policy", func(c *model.Config) { c.Prefetch.Policy = "random" }, "prefetch policy"},
+		{"unknown log flag", func(c *model.Config) { c.Log.Flags = "date,nanoseconds" }, "log flag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := model.NewDefaultConfig()
+			conf.Listeners[0].Addr = "/run/sigrpcd/sigrpcd.sock"
+			conf.Stub.Addr = "127.0.0.1:50051"
+			tt.modify(conf)
+			err := Validate(conf)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("%v, want an error about %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdminEndpointOnlyLocal(t *testing.T) {
+	tests := []struct {
+		network string
+		addr    string
+		valid   bool
+	}{
+		{"unix", "/run/sigrpcd/admin.sock", true},
+		{"tcp", "127.0.0.1:8080", true},
+		{"tcp", "localhost:8080", true},
+		{"tcp6", "[::1]:8080", true},
+		{"tcp", ":8080", false},
+		{"tcp", "0.0.0.0:8080", false},
+		{"tcp4", "192.0.2.1:8080", false},
+		{"tcp", "example.com:8080", false},
+	}
+	for _, tt := range tests {
+		conf := model.NewDefaultConfig()
+		conf.Profile.Enabled = true
+		conf.Profile.AdminNetwork = tt.network
+		conf.Profile.AdminAddr = tt.addr
+		err := Validate(conf)
+		rejected := err != nil && strings.Contains(err.Error(), "admin address")
+		if rejected == tt.valid {
+			t.Errorf("%s %s: %v", tt.network, tt.addr, err)
+		}
+	}
+}
@@ -16,131 +16,186 @@ package x64
 
 import (
 	"encoding/binary"
+	"fmt"
 	"io"
-	"unsafe"
 
 	"github.com/sigrpc/sigrpcd/pkg/domain/model/cpu"
 	codec "github.com/sigrpc/sigrpcd/pkg/domain/repository/cpu"
 	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
 )
 
+/* gregs of mcontext_t followed by the FXSAVE area of fpstate */
+const (
+	GREGCOUNT   = cpu.CR2 + 1
+	GREGSSIZE   = 8 * GREGCOUNT
+	FPSTATESIZE = 512
+	CPUSIZE     = GREGSSIZE + FPSTATESIZE
+)
+
+/* offsets in the FXSAVE area */
+const (
+	FPCWD      = 0
+	FPSWD      = 2
+	FPFTW      = 4
+	FPFOP      = 6
+	FPRIP      = 8
+	FPRDP      = 16
+	FPMXCSR    = 24
+	FPMXCRMASK = 28
+	FPST       = 32
+	FPXMM      = 160
+	FPRESERVED = 416
+)
+
+const (
+	STCOUNT         = 8
+	STSIZE          = 16
+	STSIGNIFICANDS  = 4
+	STRESERVED      = 3
+	XMMCOUNT        = 16
+	XMMSIZE         = 16
+	XMMELEMENTS     = 4
+	FPRESERVEDCOUNT = 24
+)
+
 type CPUCodec struct{}
 
 func NewCodec() codec.CPU {
 	return &CPUCodec{}
 }
 
-func (h *CPUCodec) Encode(cpuState *cpu.CPU) []byte {
-	gregMax := cpu.CR2 + 1
-	gregsSize := 8 * gregMax
-	fpstateSize := 512
-	byteData := make([]byte, gregsSize+fpstateSize)
-	head := byteData
-	// x64 state
-	// gregs
-	for i := 0; i < gregMax; i++ {
-		binary.LittleEndian.PutUint64(head, cpuState.X64.Gregs[i])
-		head = head[unsafe.Sizeof(cpuState.X64.Gregs[i]):]
+/*
+ * registers missing from the state are encoded as zero so that the layout stays fixed.
+ * Sizes too small for the xstate header send the FXSAVE area alone.
+ */
+func (h *CPUCodec) Encode(cpuState *cpu.CPU, xstateSize uint32) []byte {
+	if xstateSize < cpu.XSTATEMINSIZE {
+		xstateSize = 0
+	}
+	byteData := make([]byte, GREGSSIZE+max(FPSTATESIZE, int(xstateSize)))
+	if xstateSize > 0 {
+		encodeXState(byteData[GREGSSIZE:], cpuState.X64.Xstate)
+	}
+	gregs := cpuState.X64.Gregs
+	for i := 0; i < GREGCOUNT && i < len(gregs); i++ {
+		binary.LittleEndian.PutUint64(byteData[8*i:], gregs[i])
+	}
+	fpregs := cpuState.X64.Fpregs
+	if fpregs == nil {
+		return byteData
 	}
-	// fpregs
-	binary.LittleEndian.PutUint16(head, uint16(cpuState.X64.Fpregs.Cwd))
-	head = head[unsafe.Sizeof(uint16(cpuState.X64.Fpregs.Cwd)):]
-	binary.LittleEndian.PutUint16(head, uint16(cpuState.X64.Fpregs.Swd))
-	head = head[unsafe.Sizeof(uint16(cpuState.X64.Fpregs.Swd)):]
-	binary.LittleEndian.PutUint16(head, uint16(cpuState.X64.Fpregs.Ftw))
-	head = head[unsafe.Sizeof(uint16(cpuState.X64.Fpregs.Ftw)):]
-	binary.LittleEndian.PutUint16(head, uint16(cpuState.X64.Fpregs.Fop))
-	head = head[unsafe.Sizeof(uint16(cpuState.X64.Fpregs.Fop)):]
-	binary.LittleEndian.PutUint64(head, cpuState.X64.Fpregs.Rip)
-	head = head[unsafe.Sizeof(cpuState.X64.Fpregs.Rip):]
-	binary.LittleEndian.PutUint64(head, cpuState.X64.Fpregs.Rdp)
-	head = head[unsafe.Sizeof(cpuState.X64.Fpregs.Rdp):]
-	binary.LittleEndian.PutUint32(head, cpuState.X64.Fpregs.Mxcsr)
-	head = head[unsafe.Sizeof(cpuState.X64.Fpregs.Mxcsr):]
-	binary.LittleEndian.PutUint32(head, cpuState.X64.Fpregs.MxcrMask)
-	head = head[unsafe.Sizeof(cpuState.X64.Fpregs.MxcrMask):]
-	for i := 0; i < len(cpuState.X64.Fpregs.St); i++ {
-		for j := 0; j < len(cpuState.X64.Fpregs.St[i].Significand); j++ {
-			binary.LittleEndian.PutUint16(head, uint16(cpuState.X64.Fpregs.St[i].Significand[j]))
-			head = head[unsafe.Sizeof(uint16(cpuState.X64.Fpregs.St[i].Significand[j])):]
+	fpstate := byteData[GREGSSIZE:]
+	binary.LittleEndian.PutUint16(fpstate[FPCWD:], uint16(fpregs.Cwd))
+	binary.LittleEndian.PutUint16(fpstate[FPSWD:], uint16(fpregs.Swd))
+	binary.LittleEndian.PutUint16(fpstate[FPFTW:], uint16(fpregs.Ftw))
+	binary.LittleEndian.PutUint16(fpstate[FPFOP:], uint16(fpregs.Fop))
+	binary.LittleEndian.PutUint64(fpstate[FPRIP:], fpregs.Rip)
+	binary.LittleEndian.PutUint64(fpstate[FPRDP:], fpregs.Rdp)
+	binary.LittleEndian.PutUint32(fpstate[FPMXCSR:], fpregs.Mxcsr)
+	binary.LittleEndian.PutUint32(fpstate[FPMXCRMASK:], fpregs.MxcrMask)
+	for i := 0; i < STCOUNT && i < len(fpregs.St); i++ {
+		st := fpregs.St[i]
+		if st == nil {
+			continue
 		}
-		binary.LittleEndian.PutUint16(head, uint16(cpuState.X64.Fpregs.St[i].Exponent))
-		head = head[unsafe.Sizeof(uint16(cpuState.X64.Fpregs.St[i].Exponent)):]
-		for j := 0; j < len(cpuState.X64.Fpregs.St[i].Reserved); j++ {
-			binary.LittleEndian.PutUint16(head, uint16(cpuState.X64.Fpregs.St[i].Reserved[j]))
-			head = head[unsafe.Sizeof(uint16(cpuState.X64.Fpregs.St[i].Reserved[j])):]
+		head := fpstate[FPST+STSIZE*i:]
+		for j := 0; j < STSIGNIFICANDS && j < len(st.Significand); j++ {
+			binary.LittleEndian.PutUint16(head[2*j:], uint16(st.Significand[j]))
+		}
+		binary.LittleEndian.PutUint16(head[2*STSIGNIFICANDS:], uint16(st.Exponent))
+		for j := 0; j < STRESERVED && j < len(st.Reserved); j++ {
+			binary.LittleEndian.PutUint16(head[2*(STSIGNIFICANDS+1+j):], uint16(st.Reserved[j]))
 		}
 	}
-	for i := 0; i < len(cpuState.X64.Fpregs.Xmm); i++ {
-		for j := 0; j < len(cpuState.X64.Fpregs.Xmm[i].Element); j++ {
-			binary.LittleEndian.PutUint32(head, cpuState.X64.Fpregs.Xmm[i].Element[j])
-			head = head[unsafe.Sizeof(cpuState.X64.Fpregs.Xmm[i].Element[j]):]
+	for i := 0; i < XMMCOUNT && i < len(fpregs.Xmm); i++ {
+		xmm := fpregs.Xmm[i]
+		if xmm == nil {
+			continue
+		}
+		head := fpstate[FPXMM+XMMSIZE*i:]
+		for j := 0; j < XMMELEMENTS && j < len(xmm.Element); j++ {
+			binary.LittleEndian.PutUint32(head[4*j:], xmm.Element[j])
 		}
 	}
-	for i := 0; i < len(cpuState.X64.Fpregs.Reserved); i++ {
-		binary.LittleEndian.PutUint32(head, cpuState.X64.Fpregs.Reserved[i])
-		head = head[unsafe.Sizeof(cpuState.X64.Fpregs.Reserved[i]):]
+	for i := 0; i < FPRESERVEDCOUNT && i < len(fpregs.Reserved); i++ {
+		binary.LittleEndian.PutUint32(fpstate[FPRESERVED+4*i:], fpregs.Reserved[i])
 	}
 	return byteData
 }
 
-func (h *CPUCodec) Decode(reader io.Reader) *cpu.CPU {
-	st := make([]*x64.X64FPXReg, 8)
-	for i := 0; i < len(st); i++ {
-		st[i] = &x64.X64FPXReg{
-			Significand: make([]uint32, 4),
-			Reserved:    make([]uint32, 3),
+/* the whole state is read at once and registers are sliced out of shared backing arrays */
+func (h *CPUCodec) Decode(reader io.Reader, xstateSize uint32) (*cpu.CPU, error) {
+	if xstateSize != 0 && (xstateSize < cpu.XSTATEMINSIZE || xstateSize > cpu.XSTATEMAXSIZE) {
+		return nil, fmt.Errorf("XSAVE area size %d is out of %d-%d",
+			xstateSize, cpu.XSTATEMINSIZE, cpu.XSTATEMAXSIZE)
+	}
+	byteData := make([]byte, GREGSSIZE+max(FPSTATESIZE, int(xstateSize)))
+	if _, err := io.ReadFull(reader, byteData); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
 		}
+		return nil, err
 	}
-	xmm := make([]*x64.X64XMMReg, 16)
-	for i := 0; i < len(xmm); i++ {
-		xmm[i] = &x64.X64XMMReg{
-			Element: make([]uint32, 4),
+	var xstate *x64.X64XState
+	if xstateSize > 0 {
+		var err error
+		if xstate, err = decodeXState(byteData[GREGSSIZE:]); err != nil {
+			return nil, err
 		}
 	}
-	cpu := cpu.CPU{
-		X64: &x64.CPUState{
-			Gregs: make([]uint64, cpu.CR2+1),
-			Fpregs: &x64.X64FPRegs{
-				St:       st,
-				Xmm:      xmm,
-				Reserved: make([]uint32, 24),
-			},
-		},
+	gregs := make([]uint64, GREGCOUNT)
+	for i := range gregs {
+		gregs[i] = binary.LittleEndian.Uint64(byteData[8*i:])
+	}
+	fpstate := byteData[GREGSSIZE:]
+	fpregs := &x64.X64FPRegs{
+		Cwd:      uint32(binary.LittleEndian.Uint16(fpstate[FPCWD:])),
+		Swd:      uint32(binary.LittleEndian.Uint16(fpstate[FPSWD:])),
+		Ftw:      uint32(binary.LittleEndian.Uint16(fpstate[FPFTW:])),
+		Fop:      uint32(binary.LittleEndian.Uint16(fpstate[FPFOP:])),
+		Rip:      binary.LittleEndian.Uint64(fpstate[FPRIP:]),
+		Rdp:      binary.LittleEndian.Uint64(fpstate[FPRDP:]),
+		Mxcsr:    binary.LittleEndian.Uint32(fpstate[FPMXCSR:]),
+		MxcrMask: binary.LittleEndian.Uint32(fpstate[FPMXCRMASK:]),
+		St:       make([]*x64.X64FPXReg, STCOUNT),
+		Xmm:      make([]*x64.X64XMMReg, XMMCOUNT),
+		Reserved: make([]uint32, FPRESERVEDCOUNT),
 	}
-	binary.Read(reader, binary.LittleEndian, &cpu.X64.Gregs)
-	var shortReg uint16
-	binary.Read(reader, binary.LittleEndian, &shortReg)
-	cpu.X64.Fpregs.Cwd = uint32(shortReg)
-	binary.Read(reader, binary.LittleEndian, &shortReg)
-	cpu.X64.Fpregs.Swd = uint32(shortReg)
-	binary.Read(reader, binary.LittleEndian, &shortReg)
-	cpu.X64.Fpregs.Ftw = uint32(shortReg)
-	binary.Read(reader, binary.LittleEndian, &shortReg)
-	cpu.X64.Fpregs.Fop = uint32(shortReg)
-	binary.Read(reader, binary.LittleEndian, &cpu.X64.Fpregs.Rip)
-	binary.Read(reader, binary.LittleEndian, &cpu.X64.Fpregs.Rdp)
-	binary.Read(reader, binary.LittleEndian, &cpu.X64.Fpregs.Mxcsr)
-	binary.Read(reader, binary.LittleEndian, &cpu.X64.Fpregs.MxcrMask)
-	for st := 0; st < len(cpu.X64.Fpregs.St); st++ {
-		for sig := 0; sig < len(cpu.X64.Fpregs.St[st].Significand); sig++ {
-			binary.Read(reader, binary.LittleEndian, &shortReg)
-			cpu.X64.Fpregs.St[st].Significand[sig] = uint32(shortReg)
+	stRegs := make([]x64.X64FPXReg, STCOUNT)
+	stWords := make([]uint32, STCOUNT*(STSIGNIFICANDS+STRESERVED))
+	for i := range fpregs.St {
+		head := fpstate[FPST+STSIZE*i:]
+		words := stWords[(STSIGNIFICANDS+STRESERVED)*i : (STSIGNIFICANDS+STRESERVED)*(i+1)]
+		st := &stRegs[i]
+		st.Significand = words[:STSIGNIFICANDS:STSIGNIFICANDS]
+		st.Reserved = words[STSIGNIFICANDS:]
+		for j := range st.Significand {
+			st.Significand[j] = uint32(binary.LittleEndian.Uint16(head[2*j:]))
 		}
-		binary.Read(reader, binary.LittleEndian, &shortReg)
-		cpu.X64.Fpregs.St[st].Exponent = uint32(shortReg)
-		for res := 0; res < len(cpu.X64.Fpregs.St[st].Reserved); res++ {
-			binary.Read(reader, binary.LittleEndian, &shortReg)
-			cpu.X64.Fpregs.St[st].Reserved[res] = uint32(shortReg)
+		st.Exponent = uint32(binary.LittleEndian.Uint16(head[2*STSIGNIFICANDS:]))
+		for j := range st.Reserved {
+			st.Reserved[j] = uint32(binary.LittleEndian.Uint16(head[2*(STSIGNIFICANDS+1+j):]))
 		}
+		fpregs.St[i] = st
 	}
-	for xmm := 0; xmm < len(cpu.X64.Fpregs.Xmm); xmm++ {
-		for element := 0; element < len(cpu.X64.Fpregs.Xmm[xmm].Element); element++ {
-			binary.Read(reader, binary.LittleEndian, &cpu.X64.Fpregs.Xmm[xmm].Element[element])
+	xmmRegs := make([]x64.X64XMMReg, XMMCOUNT)
+	xmmElements := make([]uint32, XMMCOUNT*XMMELEMENTS)
+	for i := range fpregs.Xmm {
+		xmm := &xmmRegs[i]
+		xmm.Element = xmmElements[XMMELEMENTS*i : XMMELEMENTS*(i+1) : XMMELEMENTS*(i+1)]
+		for j := range xmm.Element {
+			xmm.Element[j] = binary.LittleEndian.Uint32(fpstate[FPXMM+XMMSIZE*i+4*j:])
 		}
+		fpregs.Xmm[i] = xmm
 	}
-	for reserved := 0; reserved < len(cpu.X64.Fpregs.Reserved); reserved++ {
-		binary.Read(reader, binary.LittleEndian, &cpu.X64.Fpregs.Reserved[reserved])
+	for i := range fpregs.Reserved {
+		fpregs.Reserved[i] = binary.LittleEndian.Uint32(fpstate[FPRESERVED+4*i:])
 	}
-	return &cpu
+	return &cpu.CPU{
+		X64: &x64.CPUState{
+			Gregs:  gregs,
+			Fpregs: fpregs,
+			Xstate: xstate,
+		},
+	}, nil
 }
@@ -0,0 +1,333 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/cpu"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+)
+
+const XFEATUREPKRU = 1 << 9
+
+/* every register set to a distinct value in its wire width, and every component that fits the size */
+func testCPU(xstateSize uint32) *cpu.CPU {
+	value := uint32(0)
+	next := func(mask uint32) uint32 {
+		value++
+		return value & mask
+	}
+	state := &x64.CPUState{
+		Gregs: make([]uint64, GREGCOUNT),
+		Fpregs: &x64.X64FPRegs{
+			Cwd:      next(0xffff),
+			Swd:      next(0xffff),
+			Ftw:      next(0xffff),
+			Fop:      next(0xffff),
+			Rip:      0x7f0000001000,
+			Rdp:      0x7f0000002000,
+			Mxcsr:    0x1f80,
+			MxcrMask: 0xffff,
+			St:       make([]*x64.X64FPXReg, STCOUNT),
+			Xmm:      make([]*x64.X64XMMReg, XMMCOUNT),
+			Reserved: make([]uint32, FPRESERVEDCOUNT),
+		},
+	}
+	for i := range state.Gregs {
+		state.Gregs[i] = 0x1000000000 + uint64(i)
+	}
+	for i := range state.Fpregs.St {
+		st := &x64.X64FPXReg{Exponent: next(0xffff)}
+		for range STSIGNIFICANDS {
+			st.Significand = append(st.Significand, next(0xffff))
+		}
+		for range STRESERVED {
+			st.Reserved = append(st.Reserved, next(0xffff))
+		}
+		state.Fpregs.St[i] = st
+	}
+	for i := range state.Fpregs.Xmm {
+		xmm := &x64.X64XMMReg{}
+		for range XMMELEMENTS {
+			xmm.Element = append(xmm.Element, next(^uint32(0))*0x01010101)
+		}
+		state.Fpregs.Xmm[i] = xmm
+	}
+	for i := range state.Fpregs.Reserved {
+		state.Fpregs.Reserved[i] = next(^uint32(0))
+	}
+	if xstateSize == 0 {
+		return &cpu.CPU{X64: state}
+	}
+	state.Xstate = &x64.X64XState{
+		Size:     xstateSize,
+		XstateBv: XFEATURELEGACY,
+	}
+	for i, component := range xcomponents {
+		if component.offset+component.size > int(xstateSize) {
+			continue
+		}
+		state.Xstate.XstateBv |= component.feature
+		*component.field(state.Xstate) = bytes.Repeat([]byte{byte(i + 1)}, component.size)
+	}
+	if xstateSize > EXTENDEDOFFSET {
+		state.Xstate.XstateBv |= XFEATUREPKRU
+		state.Xstate.Extended = bytes.Repeat([]byte{0x55}, int(xstateSize)-EXTENDEDOFFSET)
+	}
+	return &cpu.CPU{X64: state}
+}
+
+func TestCPURoundTrip(t *testing.T) {
+	codec := NewCodec()
+	for _, size := range xstateSizes {
+		want := testCPU(size)
+		encoded := codec.Encode(want, size)
+		if len(encoded) != GREGSSIZE+max(FPSTATESIZE, int(size)) {
+			t.Fatalf("xstate size %d: encoded %d bytes", size, len(encoded))
+		}
+		got, err := codec.Decode(bytes.NewReader(encoded), size)
+		if err != nil {
+			t.Fatalf("xstate size %d: %v", size, err)
+		}
+		if !proto.Equal(want.X64, got.X64) {
+			t.Fatalf("xstate size %d: state changed through encoding\n%v\n%v", size, want.X64, got.X64)
+		}
+	}
+}
+
+/* an empty state is sent as zeros in the fixed layout */
+func TestCPUEncodeEmpty(t *testing.T) {
+	encoded := NewCodec().Encode(&cpu.CPU{X64: &x64.CPUState{}}, 0)
+	if !bytes.Equal(encoded, make([]byte, CPUSIZE)) {
+		t.Fatalf("empty state encoded to %x", encoded)
+	}
+}
+
+func TestCPUDecodeErrors(t *testing.T) {
+	codec := NewCodec()
+	encoded := codec.Encode(testCPU(cpu.XSTATEMINSIZE), cpu.XSTATEMINSIZE)
+	compacted := bytes.Clone(encoded)
+	compacted[GREGSSIZE+XCOMPBV+7] = 0x80
+	failure := errors.New("read failure")
+	tests := []struct {
+		name   string
+		reader io.Reader
+		size   uint32
+		want   error
+	}{
+		{"empty", bytes.NewReader(nil), 0, io.ErrUnexpectedEOF},
+		{"truncated", bytes.NewReader(encoded[:CPUSIZE-1]), 0, io.ErrUnexpectedEOF},
+		{"truncated xstate", bytes.NewReader(encoded[:len(encoded)-1]), cpu.XSTATEMINSIZE, io.ErrUnexpectedEOF},
+		{"read failure", iotest.ErrReader(failure), 0, failure},
+		{"compacted", bytes.NewReader(compacted), cpu.XSTATEMINSIZE, nil},
+		{"too small", bytes.NewReader(encoded), cpu.XSTATEMINSIZE - 1, nil},
+		{"too large", bytes.NewReader(encoded), cpu.XSTATEMAXSIZE + 1, nil},
+	}
+	for _, test := range tests {
+		_, err := codec.Decode(test.reader, test.size)
+		if err == nil {
+			t.Errorf("%s: decoded without an error", test.name)
+			continue
+		}
+		if test.want != nil && !errors.Is(err, test.want) {
+			t.Errorf("%s: %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+/* the field by field encoding the single pass one replaced, kept as the reference of the layout */
+func fieldEncode(state *x64.CPUState) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, state.Gregs[:GREGCOUNT])
+	fpregs := state.Fpregs
+	for _, reg := range []uint32{fpregs.Cwd, fpregs.Swd, fpregs.Ftw, fpregs.Fop} {
+		binary.Write(&buf, binary.LittleEndian, uint16(reg))
+	}
+	binary.Write(&buf, binary.LittleEndian, fpregs.Rip)
+	binary.Write(&buf, binary.LittleEndian, fpregs.Rdp)
+	binary.Write(&buf, binary.LittleEndian, fpregs.Mxcsr)
+	binary.Write(&buf, binary.LittleEndian, fpregs.MxcrMask)
+	for _, st := range fpregs.St {
+		for _, significand := range st.Significand {
+			binary.Write(&buf, binary.LittleEndian, uint16(significand))
+		}
+		binary.Write(&buf, binary.LittleEndian, uint16(st.Exponent))
+		for _, reserved := range st.Reserved {
+			binary.Write(&buf, binary.LittleEndian, uint16(reserved))
+		}
+	}
+	for _, xmm := range fpregs.Xmm {
+		binary.Write(&buf, binary.LittleEndian, xmm.Element)
+	}
+	binary.Write(&buf, binary.LittleEndian, fpregs.Reserved)
+	return buf.Bytes()
+}
+
+func fieldDecode(reader io.Reader) (*x64.CPUState, error) {
+	state := &x64.CPUState{
+		Gregs: make([]uint64, GREGCOUNT),
+		Fpregs: &x64.X64FPRegs{
+			St:       make([]*x64.X64FPXReg, STCOUNT),
+			Xmm:      make([]*x64.X64XMMReg, XMMCOUNT),
+			Reserved: make([]uint32, FPRESERVEDCOUNT),
+		},
+	}
+	short := func(reg *uint32) error {
+		var value uint16
+		err := binary.Read(reader, binary.LittleEndian, &value)
+		*reg = uint32(value)
+		return err
+	}
+	if err := binary.Read(reader, binary.LittleEndian, state.Gregs); err != nil {
+		return nil, err
+	}
+	fpregs := state.Fpregs
+	for _, reg := range []*uint32{&fpregs.Cwd, &fpregs.Swd, &fpregs.Ftw, &fpregs.Fop} {
+		if err := short(reg); err != nil {
+			return nil, err
+		}
+	}
+	for _, reg := range []any{&fpregs.Rip, &fpregs.Rdp, &fpregs.Mxcsr, &fpregs.MxcrMask} {
+		if err := binary.Read(reader, binary.LittleEndian, reg); err != nil {
+			return nil, err
+		}
+	}
+	for i := range fpregs.St {
+		st := &x64.X64FPXReg{
+			Significand: make([]uint32, STSIGNIFICANDS),
+			Reserved:    make([]uint32, STRESERVED),
+		}
+		for j := range st.Significand {
+			if err := short(&st.Significand[j]); err != nil {
+				return nil, err
+			}
+		}
+		if err := short(&st.Exponent); err != nil {
+			return nil, err
+		}
+		for j := range st.Reserved {
+			if err := short(&st.Reserved[j]); err != nil {
+				return nil, err
+			}
+		}
+		fpregs.St[i] = st
+	}
+	for i := range fpregs.Xmm {
+		fpregs.Xmm[i] = &x64.X64XMMReg{Element: make([]uint32, XMMELEMENTS)}
+		if err := binary.Read(reader, binary.LittleEndian, fpregs.Xmm[i].Element); err != nil {
+			return nil, err
+		}
+	}
+	if err := binary.Read(reader, binary.LittleEndian, fpregs.Reserved); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func TestCPUMatchesFieldLayout(t *testing.T) {
+	codec := NewCodec()
+	want := testCPU(0)
+	encoded := codec.Encode(want, 0)
+	if !bytes.Equal(encoded, fieldEncode(want.X64)) {
+		t.Fatalf("layout differs from the field by field encoding\n%x\n%x", encoded, fieldEncode(want.X64))
+	}
+	fromFields, err := codec.Decode(bytes.NewReader(fieldEncode(want.X64)), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(want.X64, fromFields.X64) {
+		t.Fatalf("state changed decoding the field by field encoding\n%v\n%v", want.X64, fromFields.X64)
+	}
+	toFields, err := fieldDecode(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(want.X64, toFields) {
+		t.Fatalf("state changed decoding field by field\n%v\n%v", want.X64, toFields)
+	}
+}
+
+func BenchmarkCPUEncode(b *testing.B) {
+	codec := NewCodec()
+	state := testCPU(0)
+	b.Run("single pass", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(CPUSIZE)
+		for i := 0; i < b.N; i++ {
+			codec.Encode(state, 0)
+		}
+	})
+	b.Run("field by field", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(CPUSIZE)
+		for i := 0; i < b.N; i++ {
+			fieldEncode(state.X64)
+		}
+	})
+	for _, size := range xstateSizes[1:] {
+		state := testCPU(size)
+		b.Run(fmt.Sprintf("xstate %d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(GREGSSIZE + size))
+			for i := 0; i < b.N; i++ {
+				codec.Encode(state, size)
+			}
+		})
+	}
+}
+
+func BenchmarkCPUDecode(b *testing.B) {
+	codec := NewCodec()
+	encoded := codec.Encode(testCPU(0), 0)
+	b.Run("single pass", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(CPUSIZE)
+		for i := 0; i < b.N; i++ {
+			if _, err := codec.Decode(bytes.NewReader(encoded), 0); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("field by field", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(CPUSIZE)
+		for i := 0; i < b.N; i++ {
+			if _, err := fieldDecode(bytes.NewReader(encoded)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	for _, size := range xstateSizes[1:] {
+		encoded := codec.Encode(testCPU(size), size)
+		b.Run(fmt.Sprintf("xstate %d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(encoded)))
+			for i := 0; i < b.N; i++ {
+				if _, err := codec.Decode(bytes.NewReader(encoded), size); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"bytes"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/cpu"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+)
+
+/* the FXSAVE area alone, the smallest XSAVE area, AVX and AVX-512 */
+var xstateSizes = []uint32{0, cpu.XSTATEMINSIZE, 832, 2696}
+
+func FuzzCPUDecode(f *testing.F) {
+	codec := NewCodec()
+	for i, size := range xstateSizes {
+		f.Add(codec.Encode(&cpu.CPU{X64: &x64.CPUState{}}, size), uint8(i))
+	}
+	f.Fuzz(func(t *testing.T, data []byte, sizeIndex uint8) {
+		size := xstateSizes[int(sizeIndex)%len(xstateSizes)]
+		decoded, err := codec.Decode(bytes.NewReader(data), size)
+		if err != nil {
+			return
+		}
+		encoded := codec.Encode(decoded, size)
+		redecoded, err := codec.Decode(bytes.NewReader(encoded), size)
+		if err != nil {
+			t.Fatalf("decoding an encoded state: %v", err)
+		}
+		if !proto.Equal(decoded.X64, redecoded.X64) {
+			t.Fatalf("state changed through encoding\n%v\n%v", decoded.X64, redecoded.X64)
+		}
+	})
+}
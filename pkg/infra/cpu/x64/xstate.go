@@ -0,0 +1,118 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+)
+
+/* state component bits of XSTATE_BV */
+const (
+	XFEATUREFP       = 1 << 0
+	XFEATURESSE      = 1 << 1
+	XFEATUREYMM      = 1 << 2
+	XFEATUREBNDREGS  = 1 << 3
+	XFEATUREBNDCSR   = 1 << 4
+	XFEATUREOPMASK   = 1 << 5
+	XFEATUREZMMHI256 = 1 << 6
+	XFEATUREHI16ZMM  = 1 << 7
+	XFEATURELEGACY   = XFEATUREFP | XFEATURESSE
+	XFEATUREMPX      = XFEATUREBNDREGS | XFEATUREBNDCSR
+	XFEATUREKNOWN    = XFEATURELEGACY | XFEATUREYMM | XFEATUREOPMASK | XFEATUREZMMHI256 | XFEATUREHI16ZMM
+)
+
+/* offsets in the standard format XSAVE area of a signal frame */
+const (
+	XSTATEBV       = 512
+	XCOMPBV        = 520
+	EXTENDEDOFFSET = 2688
+)
+
+type xcomponent struct {
+	feature uint64
+	offset  int
+	size    int
+	field   func(*x64.X64XState) *[]byte
+}
+
+var xcomponents = []xcomponent{
+	{XFEATUREYMM, 576, 256, func(x *x64.X64XState) *[]byte { return &x.YmmHi128 }},
+	{XFEATUREOPMASK, 1088, 64, func(x *x64.X64XState) *[]byte { return &x.Opmask }},
+	{XFEATUREZMMHI256, 1152, 512, func(x *x64.X64XState) *[]byte { return &x.ZmmHi256 }},
+	{XFEATUREHI16ZMM, 1664, 1024, func(x *x64.X64XState) *[]byte { return &x.Hi16Zmm }},
+}
+
+/*
+ * components not in XSTATE_BV are in their initial state and left out.
+ * Components past Hi16_ZMM such as PKRU are carried as they are in extended.
+ * XSTATE_BV is decoded as encodeXState writes it back.
+ */
+func decodeXState(area []byte) (*x64.X64XState, error) {
+	if binary.LittleEndian.Uint64(area[XCOMPBV:]) != 0 {
+		return nil, errors.New("compacted XSAVE area is not supported")
+	}
+	xstate := &x64.X64XState{
+		Size: uint32(len(area)),
+		/* MPX is gone from Linux and never restored */
+		XstateBv: binary.LittleEndian.Uint64(area[XSTATEBV:])&^XFEATUREMPX | XFEATURELEGACY,
+	}
+	if len(area) <= EXTENDEDOFFSET {
+		xstate.XstateBv &= XFEATUREKNOWN
+	}
+	for _, component := range xcomponents {
+		if xstate.XstateBv&component.feature == 0 {
+			continue
+		}
+		if component.offset+component.size > len(area) {
+			return nil, fmt.Errorf("XSAVE component %#x at %d+%d is beyond the area size %d",
+				component.feature, component.offset, component.size, len(area))
+		}
+		*component.field(xstate) = slices.Clone(area[component.offset : component.offset+component.size])
+	}
+	if len(area) > EXTENDEDOFFSET {
+		xstate.Extended = slices.Clone(area[EXTENDEDOFFSET:])
+	}
+	return xstate, nil
+}
+
+/* the FXSAVE area is always sent in full, so its components are never left in the initial state */
+func encodeXState(area []byte, xstate *x64.X64XState) {
+	xstateBV := uint64(XFEATURELEGACY)
+	if xstate != nil {
+		xstateBV |= xstate.XstateBv &^ XFEATUREMPX
+		for _, component := range xcomponents {
+			if xstateBV&component.feature == 0 {
+				continue
+			}
+			if component.offset+component.size > len(area) {
+				xstateBV &^= component.feature
+				continue
+			}
+			copy(area[component.offset:component.offset+component.size], *component.field(xstate))
+		}
+		/* unknown components are kept only with their contents */
+		if len(area) <= EXTENDEDOFFSET || len(xstate.Extended) < len(area)-EXTENDEDOFFSET {
+			xstateBV &= XFEATUREKNOWN
+		} else {
+			copy(area[EXTENDEDOFFSET:], xstate.Extended)
+		}
+	}
+	binary.LittleEndian.PutUint64(area[XSTATEBV:], xstateBV)
+}
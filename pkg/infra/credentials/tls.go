@@ -0,0 +1,167 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentials
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type TLSConfig struct {
+	Enabled    bool
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	ServerName string
+}
+
+type certReloader struct {
+	conf       TLSConfig
+	mu         sync.Mutex
+	caModTime  time.Time
+	pool       *x509.CertPool
+	crtModTime time.Time
+	keyModTime time.Time
+	cert       *tls.Certificate
+}
+
+/* plaintext only when nothing of TLS is configured, a partial configuration is an error */
+func NewStubCredentials(conf TLSConfig) (credentials.TransportCredentials, error) {
+	if !conf.Enabled &&
+		len(conf.CAFile) == 0 &&
+		len(conf.CertFile) == 0 &&
+		len(conf.KeyFile) == 0 &&
+		len(conf.ServerName) == 0 {
+		return insecure.NewCredentials(), nil
+	}
+	return NewTLSCredentials(conf)
+}
+
+func NewTLSCredentials(conf TLSConfig) (credentials.TransportCredentials, error) {
+	if (len(conf.CertFile) == 0) != (len(conf.KeyFile) == 0) {
+		return nil, errors.New("both client certificate and key must be specified")
+	}
+	r := &certReloader{conf: conf}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: conf.ServerName,
+		/* server certificate is verified in VerifyConnection with the reloaded CA pool */
+		InsecureSkipVerify: true,
+		VerifyConnection:   r.verifyConnection,
+	}
+	if len(conf.CertFile) > 0 {
+		tlsConfig.GetClientCertificate = r.getClientCertificate
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+func modTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+func (r *certReloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.conf.CAFile) > 0 {
+		mtime, err := modTime(r.conf.CAFile)
+		if err != nil {
+			return err
+		}
+		if r.pool == nil || !mtime.Equal(r.caModTime) {
+			pem, err := os.ReadFile(r.conf.CAFile)
+			if err != nil {
+				return err
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return errors.New("no valid certificate in " + r.conf.CAFile)
+			}
+			r.pool = pool
+			r.caModTime = mtime
+		}
+	}
+	if len(r.conf.CertFile) > 0 {
+		crtMtime, err := modTime(r.conf.CertFile)
+		if err != nil {
+			return err
+		}
+		keyMtime, err := modTime(r.conf.KeyFile)
+		if err != nil {
+			return err
+		}
+		if r.cert == nil || !crtMtime.Equal(r.crtModTime) || !keyMtime.Equal(r.keyModTime) {
+			cert, err := tls.LoadX509KeyPair(r.conf.CertFile, r.conf.KeyFile)
+			if err != nil {
+				return err
+			}
+			r.cert = &cert
+			r.crtModTime = crtMtime
+			r.keyModTime = keyMtime
+		}
+	}
+	return nil
+}
+
+func (r *certReloader) current() (*x509.CertPool, *tls.Certificate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pool, r.cert
+}
+
+func (r *certReloader) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	_, cert := r.current()
+	return cert, nil
+}
+
+func (r *certReloader) verifyConnection(state tls.ConnectionState) error {
+	if err := r.reload(); err != nil {
+		return err
+	}
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("no server certificate")
+	}
+	pool, _ := r.current()
+	serverName := r.conf.ServerName
+	if len(serverName) == 0 {
+		serverName = state.ServerName
+	}
+	opts := x509.VerifyOptions{
+		Roots:         pool,
+		DNSName:       serverName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range state.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := state.PeerCertificates[0].Verify(opts)
+	return err
+}
@@ -0,0 +1,259 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentials
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+var testSerial int64
+
+func newTestCert(t *testing.T, name string, parent *testCert, isCA bool) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSerial++
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(testSerial),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{name},
+	}
+	if isCA {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+func writePEM(t *testing.T, path string, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (c *testCert) writeFiles(t *testing.T, dir string, name string) (string, string) {
+	t.Helper()
+	crt := filepath.Join(dir, name+".crt")
+	key := filepath.Join(dir, name+".key")
+	writePEM(t, crt, "CERTIFICATE", c.der)
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, key, "EC PRIVATE KEY", keyDER)
+	return crt, key
+}
+
+/* serves one TLS handshake and reports the client certificate seen */
+func serveTLS(t *testing.T, cert *testCert, clientCA *testCert) (string, <-chan []*x509.Certificate) {
+	t.Helper()
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert.tlsCertificate()},
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		/* gRPC clients insist on the protocol a gRPC server negotiates */
+		NextProtos: []string{"h2"},
+	}
+	if clientCA != nil {
+		config.ClientCAs = x509.NewCertPool()
+		config.ClientCAs.AddCert(clientCA.cert)
+	}
+	sock, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sock.Close() })
+	peers := make(chan []*x509.Certificate, 1)
+	go func() {
+		conn, err := sock.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tlsConn := conn.(*tls.Conn)
+		if err := tlsConn.Handshake(); err != nil {
+			peers <- nil
+			return
+		}
+		peers <- tlsConn.ConnectionState().PeerCertificates
+	}()
+	return sock.Addr().String(), peers
+}
+
+func handshake(t *testing.T, conf TLSConfig, addr string) error {
+	t.Helper()
+	creds, err := NewTLSCredentials(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	secure, _, err := creds.ClientHandshake(ctx, "stub.test", conn)
+	if err == nil {
+		secure.Close()
+	}
+	return err
+}
+
+func TestTLSCredentialsMutualAuth(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil, true)
+	server := newTestCert(t, "stub.test", ca, false)
+	client := newTestCert(t, "sigrpcd", ca, false)
+	caFile, _ := ca.writeFiles(t, dir, "ca")
+	crtFile, keyFile := client.writeFiles(t, dir, "client")
+
+	addr, peers := serveTLS(t, server, ca)
+	err := handshake(t, TLSConfig{CAFile: caFile, CertFile: crtFile, KeyFile: keyFile}, addr)
+	if err != nil {
+		t.Fatalf("handshake: %v", err)
+	}
+	seen := <-peers
+	if len(seen) != 1 || seen[0].Subject.CommonName != "sigrpcd" {
+		t.Fatalf("server saw client certificates %v", seen)
+	}
+}
+
+func TestTLSCredentialsRejectsServer(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil, true)
+	other := newTestCert(t, "other-ca", nil, true)
+	caFile, _ := ca.writeFiles(t, dir, "ca")
+
+	tests := []struct {
+		name   string
+		server *testCert
+		conf   TLSConfig
+	}{
+		{"unknown CA", newTestCert(t, "stub.test", other, false), TLSConfig{CAFile: caFile}},
+		{"wrong server name", newTestCert(t, "stub.test", ca, false), TLSConfig{CAFile: caFile, ServerName: "other.test"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, _ := serveTLS(t, tt.server, nil)
+			if err := handshake(t, tt.conf, addr); err == nil {
+				t.Fatal("handshake succeeded")
+			}
+		})
+	}
+}
+
+func TestTLSCredentialsReloadsCA(t *testing.T) {
+	dir := t.TempDir()
+	oldCA := newTestCert(t, "old-ca", nil, true)
+	newCA := newTestCert(t, "new-ca", nil, true)
+	caFile, _ := oldCA.writeFiles(t, dir, "ca")
+	creds := TLSConfig{CAFile: caFile}
+
+	addr, _ := serveTLS(t, newTestCert(t, "stub.test", oldCA, false), nil)
+	if err := handshake(t, creds, addr); err != nil {
+		t.Fatalf("handshake with the old CA: %v", err)
+	}
+	newCA.writeFiles(t, dir, "ca")
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(caFile, future, future); err != nil {
+		t.Fatal(err)
+	}
+	addr, _ = serveTLS(t, newTestCert(t, "stub.test", newCA, false), nil)
+	if err := handshake(t, creds, addr); err != nil {
+		t.Fatalf("handshake with the rotated CA: %v", err)
+	}
+}
+
+func TestStubCredentials(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil, true)
+	caFile, _ := ca.writeFiles(t, dir, "ca")
+	crtFile, keyFile := newTestCert(t, "sigrpcd", ca, false).writeFiles(t, dir, "client")
+
+	tests := []struct {
+		name     string
+		conf     TLSConfig
+		protocol string
+		wantErr  bool
+	}{
+		{"nothing configured", TLSConfig{}, "insecure", false},
+		{"enabled", TLSConfig{Enabled: true}, "tls", false},
+		{"ca only", TLSConfig{CAFile: caFile}, "tls", false},
+		{"client certificate", TLSConfig{CAFile: caFile, CertFile: crtFile, KeyFile: keyFile}, "tls", false},
+		{"key without certificate", TLSConfig{KeyFile: keyFile}, "", true},
+		{"certificate without key", TLSConfig{CertFile: crtFile}, "", true},
+		{"missing ca", TLSConfig{CAFile: filepath.Join(dir, "missing.crt")}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds, err := NewStubCredentials(tt.conf)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %s credentials, want an error", creds.Info().SecurityProtocol)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := creds.Info().SecurityProtocol; got != tt.protocol {
+				t.Fatalf("got %s credentials, want %s", got, tt.protocol)
+			}
+		})
+	}
+}
@@ -16,12 +16,25 @@ package x64
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"log"
+	"strconv"
 
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/mapping"
 	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/peer"
+	cacherepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/cache"
 	grpcclient "github.com/sigrpc/sigrpcd/pkg/domain/repository/grpc"
+	pagecodec "github.com/sigrpc/sigrpcd/pkg/domain/repository/page"
+	storerepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/store"
 	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+	x64page "github.com/sigrpc/sigrpcd/pkg/infra/page/x64"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type X64GRPCClient struct {
@@ -30,15 +43,72 @@ type X64GRPCClient struct {
 	ClientID     string
 	StreamClient *x64.SigRPC_InvokeFuncClient
 	isStreaming  bool
+	limits       limit.Limits
+	pageElision  bool
+	store        storerepository.Content
+	bases        cacherepository.Bases
+	delta        pagecodec.Delta
+	features     uint64
 }
 
-func NewClient(cc grpc.ClientConnInterface, ctx context.Context) grpcclient.GRPCClient {
+type Options struct {
+	Limits      limit.Limits
+	PageElision bool
+	Store       storerepository.Content
+	/* pages sent to the stub are deltas against the revisions held here */
+	Bases cacherepository.Bases
+}
+
+/* tells the stub that pages are exchanged as deltas against revisions it sent before */
+func WithDeltaPages(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx,
+		"sigrpc-page-encoding", x64.PageEncoding_PAGE_ENCODING_XOR_RLE.String())
+}
+
+/* without page encodings, the stub answers with full content */
+func fullContent(ctx context.Context) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	md = md.Copy()
+	md.Delete("sigrpc-page-encoding")
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+func NewClient(
+	cc grpc.ClientConnInterface,
+	ctx context.Context,
+	peer *peer.Peer,
+	opts Options) grpcclient.GRPCClient {
 	client := x64.NewSigRPCClient(cc)
+	ctx = metadata.AppendToOutgoingContext(ctx,
+		"sigrpc-page-encoding", x64.PageEncoding_PAGE_ENCODING_ZERO.String(),
+		"sigrpc-page-encoding", x64.PageEncoding_PAGE_ENCODING_DUPLICATE.String(),
+		"sigrpc-page-encoding", x64.PageEncoding_PAGE_ENCODING_DIRTY.String())
+	if opts.Store != nil {
+		ctx = metadata.AppendToOutgoingContext(ctx,
+			"sigrpc-page-encoding", x64.PageEncoding_PAGE_ENCODING_HASH.String())
+	}
+	if peer != nil {
+		ctx = metadata.AppendToOutgoingContext(ctx,
+			"sigrpc-pid", strconv.FormatInt(int64(peer.Pid), 10),
+			"sigrpc-uid", strconv.FormatUint(uint64(peer.Uid), 10),
+			"sigrpc-gid", strconv.FormatUint(uint64(peer.Gid), 10),
+			"sigrpc-nspid", strconv.FormatInt(int64(peer.ClientPid()), 10),
+			"sigrpc-pidns", strconv.FormatUint(peer.PidNamespace, 10),
+			"sigrpc-starttime", strconv.FormatUint(peer.StartTime, 10))
+	}
 	return &X64GRPCClient{
 		Ctx:          ctx,
 		Client:       client,
 		StreamClient: nil,
 		isStreaming:  false,
+		limits:       opts.Limits,
+		pageElision:  opts.PageElision,
+		store:        opts.Store,
+		bases:        opts.Bases,
+		delta:        x64page.NewXORRLEDelta(),
 	}
 }
 
@@ -46,6 +116,11 @@ func (c *X64GRPCClient) IsStreaming() bool {
 	return c.isStreaming
 }
 
+/* features negotiated with the client, they take effect on the next stream */
+func (c *X64GRPCClient) SetFeatures(features uint64) {
+	c.features = features
+}
+
 func (c *X64GRPCClient) LoadLib(req *msg.LoadLibMsg) (*msg.LoadLibMsg, error) {
 	resp, err := c.Client.LoadLib(c.Ctx, req.X64)
 	if err != nil {
@@ -59,20 +134,46 @@ func (c *X64GRPCClient) LoadLib(req *msg.LoadLibMsg) (*msg.LoadLibMsg, error) {
 
 func (c *X64GRPCClient) InvokeFunc(req *msg.InvokeFuncMsg) (*msg.InvokeFuncMsg, error) {
 	if c.StreamClient == nil {
-		stream, err := c.Client.InvokeFunc(c.Ctx)
+		ctx := c.Ctx
+		/* the stub asks the daemon for pages instead of the client */
+		if c.features&msg.FEATUREDIRECTMEMORY != 0 {
+			ctx = metadata.AppendToOutgoingContext(ctx, "sigrpc-direct-memory", "true")
+		}
+		stream, err := c.Client.InvokeFunc(ctx)
 		if err != nil {
 			return nil, err
 		}
 		c.StreamClient = &stream
 	}
 	stream := *c.StreamClient
+	/* dirty ranges returned by the stub are relative to the pages sent here */
+	bases := x64page.PageContents(req.X64.Page)
+	if c.store != nil {
+		c.referContents(req.X64.Page)
+	}
+	if c.pageElision {
+		x64page.ElidePages(req.X64.Page)
+	}
+	var full map[*x64.Page][]byte
+	if c.bases != nil {
+		full = c.encodeDeltas(req.X64.Header, req.X64.Page)
+	}
 	err := stream.Send(req.X64)
 	if err != nil {
 		return nil, err
 	}
 	resp, err := stream.Recv()
+	/* a stub without the base of a delta runs nothing and is sent the pages in full */
+	if err == nil && len(full) > 0 && resp.Header != nil && resp.Header.Status == msg.STATUSFAILEDPRECONDITION {
+		restoreContents(full)
+		if err := stream.Send(req.X64); err != nil {
+			return nil, err
+		}
+		resp, err = stream.Recv()
+	}
 	if err == nil {
 		c.isStreaming = true
+		err = c.resolvePages(req.X64.Header, resp.Page, bases, req.X64.Page)
 	} else if err == io.EOF {
 		c.isStreaming = false
 	}
@@ -86,12 +187,212 @@ func (c *X64GRPCClient) PullPage(req *msg.PullPageMsg) (*msg.PullPageMsg, error)
 	if err != nil {
 		return nil, err
 	}
+	if err := c.resolvePages(req.X64.Header, resp.Page, nil, req.X64.Page); err != nil {
+		return nil, err
+	}
 	page := msg.PullPageMsg{
 		X64: resp,
 	}
 	return &page, nil
 }
 
+/*
+ * pages the client modified from a revision the stub sent are sent as deltas against it,
+ * returns their full content
+ */
+func (c *X64GRPCClient) encodeDeltas(header *x64.RPCHeader, pages []*x64.Page) map[*x64.Page][]byte {
+	if header == nil {
+		return nil
+	}
+	var full map[*x64.Page][]byte
+	for _, page := range pages {
+		if page.Encoding != x64.PageEncoding_PAGE_ENCODING_FULL || len(page.Content) == 0 {
+			continue
+		}
+		length := page.Length
+		if length == 0 {
+			length = uint64(len(page.Content))
+		}
+		base, ok := c.bases.Base(header.ClientId, page.Address, page.RuntimeRevision, length)
+		if !ok {
+			continue
+		}
+		delta := c.delta.Encode(base, page.Content)
+		if delta == nil || len(delta) >= len(page.Content) {
+			continue
+		}
+		if full == nil {
+			full = make(map[*x64.Page][]byte)
+		}
+		full[page] = page.Content
+		page.ContentSize = uint32(len(page.Content))
+		page.Content = delta
+		page.BaseRevision = page.RuntimeRevision
+		page.Encoding = x64.PageEncoding_PAGE_ENCODING_XOR_RLE
+	}
+	return full
+}
+
+func restoreContents(full map[*x64.Page][]byte) {
+	for page, content := range full {
+		page.Content = content
+		page.BaseRevision = 0
+		page.Encoding = x64.PageEncoding_PAGE_ENCODING_FULL
+	}
+}
+
+/*
+ * only contents of read-only file mappings are kept,
+ * the others may be private to the client and change with every call
+ */
+func storable(m *x64.Mapping) bool {
+	return m != nil && m.Kind == x64.MappingKind_MAPPING_KIND_FILE && m.Protection&mapping.PROTWRITE == 0
+}
+
+/* pages whose content the stub reported to have are sent as hash references */
+func (c *X64GRPCClient) referContents(pages []*x64.Page) {
+	hashes := make([][]byte, len(pages))
+	unknown := make([][]byte, 0)
+	for i, page := range pages {
+		if page.Encoding != x64.PageEncoding_PAGE_ENCODING_FULL || len(page.Content) == 0 || !storable(page.Mapping) {
+			continue
+		}
+		hashes[i] = c.store.Put(page.Content)
+		if !c.store.IsRemote(hashes[i]) {
+			unknown = append(unknown, hashes[i])
+		}
+	}
+	if len(unknown) > 0 && c.store.RemoteEnabled() {
+		resp, err := c.Client.HasContent(c.Ctx, &x64.ContentHashes{
+			Hash: unknown,
+		})
+		if status.Code(err) == codes.Unimplemented {
+			c.store.DisableRemote()
+		} else if err != nil {
+			log.Println(err)
+		} else {
+			c.store.SetRemote(resp.Hash)
+		}
+	}
+	for i, page := range pages {
+		if hashes[i] == nil || !c.store.IsRemote(hashes[i]) {
+			continue
+		}
+		page.ContentSize = uint32(len(page.Content))
+		page.Content = nil
+		page.ContentHash = hashes[i]
+		page.Encoding = x64.PageEncoding_PAGE_ENCODING_HASH
+	}
+}
+
+/*
+ * restores hash references from the store and dirty ranges from their bases,
+ * or pulls their full content from the stub.
+ * Pages the stub sends without a mapping have that of the page requested.
+ */
+func (c *X64GRPCClient) resolvePages(
+	header *x64.RPCHeader,
+	pages []*x64.Page,
+	bases map[uint64][]byte,
+	requested []*x64.Page) error {
+	unpatched, err := x64page.PatchPages(pages, bases)
+	if err != nil {
+		return err
+	}
+	missing := make(map[uint64]*x64.Page)
+	req := &x64.PullPageMsg{
+		Header: &x64.RPCHeader{
+			MsgType: msg.PULLPAGE,
+		},
+	}
+	if header != nil {
+		req.Header.ClientId = header.ClientId
+	}
+	refer := func(page *x64.Page) {
+		missing[page.Address] = page
+		req.Page = append(req.Page, &x64.Page{
+			Address:         page.Address,
+			RuntimeRevision: page.RuntimeRevision,
+			ClientRevision:  page.ClientRevision,
+			Length:          page.Length,
+		})
+	}
+	for _, page := range unpatched {
+		refer(page)
+	}
+	var mappings map[uint64]*x64.Mapping
+	for _, page := range pages {
+		switch page.Encoding {
+		case x64.PageEncoding_PAGE_ENCODING_HASH:
+			if c.store != nil {
+				if content, ok := c.store.Get(page.ContentHash); ok {
+					page.Content = content
+					page.ContentSize = uint32(len(content))
+					page.ContentHash = nil
+					page.Encoding = x64.PageEncoding_PAGE_ENCODING_FULL
+					continue
+				}
+			}
+			refer(page)
+		case x64.PageEncoding_PAGE_ENCODING_FULL:
+			if c.store == nil || len(page.Content) == 0 {
+				continue
+			}
+			m := page.Mapping
+			if m == nil {
+				if mappings == nil {
+					mappings = make(map[uint64]*x64.Mapping, len(requested))
+					for _, req := range requested {
+						mappings[req.Address] = req.Mapping
+					}
+				}
+				m = mappings[page.Address]
+			}
+			if storable(m) {
+				c.store.Put(page.Content)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		resp, err := c.Client.PullPage(fullContent(c.Ctx), req)
+		if err != nil {
+			return err
+		}
+		for _, full := range resp.Page {
+			page, ok := missing[full.Address]
+			if !ok || full.Encoding != x64.PageEncoding_PAGE_ENCODING_FULL {
+				continue
+			}
+			page.Content = full.Content
+			page.ContentSize = uint32(len(full.Content))
+			page.ContentHash = nil
+			page.Dirty = nil
+			page.Encoding = x64.PageEncoding_PAGE_ENCODING_FULL
+			delete(missing, full.Address)
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("%d pages are missing in the full content", len(missing))
+		}
+	}
+	/* replies of the stub are charged as a whole once they are returned */
+	return x64page.ExpandPages(pages, c.limits.MaxPageContentSize, nil)
+}
+
 func (c *X64GRPCClient) GetRPCType(header *msg.RPCHeader) uint32 {
 	return header.X64.MsgType
 }
+
+func (c *X64GRPCClient) GetArch() uint32 {
+	return msg.ARCHX64
+}
+
+func (c *X64GRPCClient) GetRPCStatus(err error) uint32 {
+	if err == nil {
+		return msg.STATUSOK
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return msg.STATUSUNKNOWN
+	}
+	return uint32(st.Code())
+}
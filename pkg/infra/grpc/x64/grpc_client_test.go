@@ -0,0 +1,235 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/mapping"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	storemodel "github.com/sigrpc/sigrpcd/pkg/domain/model/store"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+	x64page "github.com/sigrpc/sigrpcd/pkg/infra/page/x64"
+	"github.com/sigrpc/sigrpcd/pkg/infra/store"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const TESTPAGESIZE = 0x1000
+
+/* holds revision 1 of every page as zeros unless it has lost them */
+type deltaStub struct {
+	x64.UnimplementedSigRPCServer
+	hasBase   bool
+	encodings []x64.PageEncoding
+	contents  [][]byte
+}
+
+func (s *deltaStub) InvokeFunc(stream x64.SigRPC_InvokeFuncServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		resp := &x64.InvokeFuncMsg{Header: &x64.RPCHeader{MsgType: msg.INVOKEFUNC, ClientId: req.Header.ClientId}}
+		contents := make([][]byte, 0, len(req.Page))
+		for _, page := range req.Page {
+			s.encodings = append(s.encodings, page.Encoding)
+			content := page.Content
+			if page.Encoding == x64.PageEncoding_PAGE_ENCODING_XOR_RLE {
+				if !s.hasBase || page.BaseRevision != 1 {
+					resp.Header.Status = msg.STATUSFAILEDPRECONDITION
+					break
+				}
+				content, err = x64page.NewXORRLEDelta().Decode(make([]byte, TESTPAGESIZE), page.Content)
+				if err != nil {
+					return err
+				}
+			}
+			contents = append(contents, content)
+		}
+		if resp.Header.Status == msg.STATUSOK {
+			s.contents = append(s.contents, contents...)
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+/* answers pulls with the content of every page as its address */
+type pageStub struct {
+	x64.UnimplementedSigRPCServer
+}
+
+func (pageStub) PullPage(_ context.Context, req *x64.PullPageMsg) (*x64.PullPageMsg, error) {
+	resp := &x64.PullPageMsg{Header: req.Header}
+	for _, page := range req.Page {
+		resp.Page = append(resp.Page, &x64.Page{
+			Address:         page.Address,
+			RuntimeRevision: page.RuntimeRevision,
+			ContentSize:     TESTPAGESIZE,
+			Content:         bytes.Repeat([]byte{byte(page.Address >> 12)}, TESTPAGESIZE),
+		})
+	}
+	return resp, nil
+}
+
+func (pageStub) HasContent(context.Context, *x64.ContentHashes) (*x64.ContentHashes, error) {
+	return &x64.ContentHashes{}, nil
+}
+
+type zeroBases struct{}
+
+func (zeroBases) Base(clientID string, address uint64, revision uint64, length uint64) ([]byte, bool) {
+	return make([]byte, length), revision == 1 && length == TESTPAGESIZE
+}
+
+func newTestClient(t *testing.T, stub x64.SigRPCServer) *X64GRPCClient {
+	t.Helper()
+	return newTestClientWith(t, stub, Options{
+		Limits: limit.NewDefaultLimits(),
+		Bases:  zeroBases{},
+	})
+}
+
+func newTestClientWith(t *testing.T, stub x64.SigRPCServer, opts Options) *X64GRPCClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	x64.RegisterSigRPCServer(srv, stub)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	cc, err := grpc.NewClient("passthrough:///stub",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cc.Close() })
+	return NewClient(cc, WithDeltaPages(context.Background()), nil, opts).(*X64GRPCClient)
+}
+
+func invoke(t *testing.T, c *X64GRPCClient, content []byte) {
+	t.Helper()
+	resp, err := c.InvokeFunc(&msg.InvokeFuncMsg{X64: &x64.InvokeFuncMsg{
+		Header: &x64.RPCHeader{MsgType: msg.INVOKEFUNC, ClientId: "test-1"},
+		Page: []*x64.Page{{
+			Address:         0x1000,
+			RuntimeRevision: 1,
+			ClientRevision:  1,
+			Length:          TESTPAGESIZE,
+			ContentSize:     TESTPAGESIZE,
+			Content:         append([]byte(nil), content...),
+		}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.X64.Header.Status != msg.STATUSOK {
+		t.Fatalf("stub answered status %d", resp.X64.Header.Status)
+	}
+}
+
+func modified() []byte {
+	content := make([]byte, TESTPAGESIZE)
+	copy(content[0x100:], "modified")
+	return content
+}
+
+func TestInvokeFuncSendsDeltas(t *testing.T) {
+	stub := &deltaStub{hasBase: true}
+	invoke(t, newTestClient(t, stub), modified())
+	if len(stub.encodings) != 1 || stub.encodings[0] != x64.PageEncoding_PAGE_ENCODING_XOR_RLE {
+		t.Fatalf("stub received %v", stub.encodings)
+	}
+	if len(stub.contents) != 1 || !bytes.Equal(stub.contents[0], modified()) {
+		t.Fatal("content changed through the delta")
+	}
+}
+
+func TestInvokeFuncFallsBackToFullContent(t *testing.T) {
+	stub := &deltaStub{hasBase: false}
+	invoke(t, newTestClient(t, stub), modified())
+	want := []x64.PageEncoding{x64.PageEncoding_PAGE_ENCODING_XOR_RLE, x64.PageEncoding_PAGE_ENCODING_FULL}
+	if len(stub.encodings) != len(want) || stub.encodings[0] != want[0] || stub.encodings[1] != want[1] {
+		t.Fatalf("stub received %v, want %v", stub.encodings, want)
+	}
+	if len(stub.contents) != 1 || !bytes.Equal(stub.contents[0], modified()) {
+		t.Fatal("content changed through the fallback")
+	}
+}
+
+func testMappings() []*x64.Mapping {
+	return []*x64.Mapping{
+		{Protection: mapping.PROTREAD | mapping.PROTEXEC, Kind: x64.MappingKind_MAPPING_KIND_FILE, Path: "/usr/lib/libc.so.6"},
+		{Protection: mapping.PROTREAD | mapping.PROTWRITE, Kind: x64.MappingKind_MAPPING_KIND_FILE, Path: "/usr/lib/libc.so.6"},
+		{Protection: mapping.PROTREAD | mapping.PROTWRITE, Kind: x64.MappingKind_MAPPING_KIND_STACK},
+		{Protection: mapping.PROTREAD | mapping.PROTWRITE, Kind: x64.MappingKind_MAPPING_KIND_HEAP},
+		nil,
+	}
+}
+
+func TestStoreKeepsReadOnlyFilePages(t *testing.T) {
+	contentStore, err := store.NewContentStore(storemodel.Config{Enabled: true, MaxMemory: 0x100000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer contentStore.Close()
+	c := newTestClientWith(t, pageStub{}, Options{Limits: limit.NewDefaultLimits(), Store: contentStore})
+	req := &msg.PullPageMsg{X64: &x64.PullPageMsg{Header: &x64.RPCHeader{MsgType: msg.PULLPAGE, ClientId: "test-1"}}}
+	for i, m := range testMappings() {
+		req.X64.Page = append(req.X64.Page, &x64.Page{Address: uint64(i+1) << 12, Mapping: m})
+	}
+	if _, err := c.PullPage(req); err != nil {
+		t.Fatal(err)
+	}
+	if stats := contentStore.Stats(); stats.MemoryEntries != 1 {
+		t.Fatalf("pulled pages are stored: %s", stats)
+	}
+}
+
+func TestStoreRefersReadOnlyFilePages(t *testing.T) {
+	contentStore, err := store.NewContentStore(storemodel.Config{Enabled: true, MaxMemory: 0x100000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer contentStore.Close()
+	c := newTestClientWith(t, pageStub{}, Options{Limits: limit.NewDefaultLimits(), Store: contentStore})
+	pages := make([]*x64.Page, 0)
+	for i, m := range testMappings() {
+		pages = append(pages, &x64.Page{
+			Address:     uint64(i+1) << 12,
+			ContentSize: TESTPAGESIZE,
+			Content:     bytes.Repeat([]byte{byte(i + 1)}, TESTPAGESIZE),
+			Mapping:     m,
+		})
+	}
+	c.referContents(pages)
+	if stats := contentStore.Stats(); stats.MemoryEntries != 1 {
+		t.Fatalf("sent pages are stored: %s", stats)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package listener
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+const listenFDsStart = 3
+
+type Named struct {
+	Name      string
+	Unlink    bool
+	Activated bool
+	net.Listener
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func Name(network string, addr string) string {
+	return network + ":" + addr
+}
+
+func isUnix(network string) bool {
+	return network == "unix" || network == "unixpacket"
+}
+
+func newNamed(name string, sock net.Listener) *Named {
+	/* the socket path is removed by Named.Close so that it can be handed over */
+	if unixSock, ok := sock.(*net.UnixListener); ok {
+		unixSock.SetUnlinkOnClose(false)
+	}
+	return &Named{
+		Name:     name,
+		Listener: sock,
+	}
+}
+
+/* permissions of a unix socket path, empty fields are left as they are */
+type Permissions struct {
+	Mode  string
+	Owner string
+	Group string
+}
+
+func Listen(name string, network string, addr string, perm Permissions) (*Named, error) {
+	if !isUnix(network) {
+		sock, err := net.Listen(network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return newNamed(name, sock), nil
+	}
+	/* a socket left by a previous run is replaced, anything else at the path is not ours to remove */
+	if info, err := os.Lstat(addr); err == nil {
+		if info.Mode().Type() != os.ModeSocket {
+			return nil, fmt.Errorf("%s exists and is not a socket", addr)
+		}
+		if err := os.Remove(addr); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	/*
+	 * the socket is connectable as soon as it is bound, so it is bound
+	 * for the owner alone and opened up once its permissions are set.
+	 * The umask is process wide, listeners are created before anything else writes files.
+	 */
+	umask := syscall.Umask(0177)
+	sock, err := net.Listen(network, addr)
+	syscall.Umask(umask)
+	if err != nil {
+		return nil, err
+	}
+	named := newNamed(name, sock)
+	named.Unlink = true
+	if len(perm.Mode) == 0 {
+		perm.Mode = strconv.FormatUint(uint64(0777&^umask), 8)
+	}
+	if err := setPermissions(addr, perm); err != nil {
+		named.Close()
+		return nil, err
+	}
+	return named, nil
+}
+
+/* a configured listener is the one with its name or, as systemd names sockets on its own, its address */
+func (l *Named) Matches(name string, network string, addr string) bool {
+	if l.Name == name {
+		return true
+	}
+	return len(addr) > 0 && l.Addr().Network() == network && l.Addr().String() == addr
+}
+
+/* removes the listener matching a configured one from available */
+func Take(available *[]*Named, name string, network string, addr string) *Named {
+	for i, sock := range *available {
+		if sock.Matches(name, network, addr) {
+			*available = append((*available)[:i], (*available)[i+1:]...)
+			return sock
+		}
+	}
+	return nil
+}
+
+func (l *Named) Close() error {
+	l.closeOnce.Do(func() {
+		l.closeErr = l.Listener.Close()
+		if l.Unlink && isUnix(l.Addr().Network()) {
+			if err := os.Remove(l.Addr().String()); err != nil && !os.IsNotExist(err) && l.closeErr == nil {
+				l.closeErr = err
+			}
+		}
+	})
+	return l.closeErr
+}
+
+func lookupID(name string, lookup func(string) (string, error)) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+	idStr, err := lookup(name)
+	if err != nil {
+		return -1, err
+	}
+	return strconv.Atoi(idStr)
+}
+
+func setPermissions(path string, perm Permissions) error {
+	uid, gid := -1, -1
+	var err error
+	if len(perm.Owner) > 0 {
+		uid, err = lookupID(perm.Owner, func(name string) (string, error) {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return u.Uid, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(perm.Group) > 0 {
+		gid, err = lookupID(perm.Group, func(name string) (string, error) {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if uid >= 0 || gid >= 0 {
+		if err := os.Chown(path, uid, gid); err != nil {
+			return err
+		}
+	}
+	if len(perm.Mode) > 0 {
+		mode, err := strconv.ParseUint(perm.Mode, 8, 32)
+		if err != nil {
+			return err
+		}
+		if err := os.Chmod(path, os.FileMode(mode)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
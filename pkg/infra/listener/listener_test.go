@@ -0,0 +1,287 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package listener
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const (
+	envTestActivatedAddr = "SIGRPCD_TEST_ACTIVATED_ADDR"
+	envTestUpgradeAddr   = "SIGRPCD_TEST_UPGRADE_ADDR"
+)
+
+func TestListenUnlinksOnClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sigrpcd.sock")
+	sock, err := Listen(Name("unix", path), "unix", path, Permissions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sock.Unlink || sock.Activated {
+		t.Fatalf("listener unlink %v activated %v", sock.Unlink, sock.Activated)
+	}
+	if err := sock.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("socket path is left after close: %v", err)
+	}
+}
+
+func TestListenSetsPermissions(t *testing.T) {
+	umask := syscall.Umask(0022)
+	defer syscall.Umask(umask)
+	dir := t.TempDir()
+	tests := []struct {
+		mode string
+		want os.FileMode
+	}{
+		{"", 0755},
+		{"660", 0660},
+		{"0600", 0600},
+	}
+	for i, test := range tests {
+		path := filepath.Join(dir, strconv.Itoa(i)+".sock")
+		sock, err := Listen(Name("unix", path), "unix", path, Permissions{Mode: test.mode})
+		if err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(path)
+		sock.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != test.want {
+			t.Errorf("mode %q: socket has %v, want %v", test.mode, info.Mode().Perm(), test.want)
+		}
+	}
+	if current := syscall.Umask(umask); current != 0022 {
+		t.Fatalf("umask left at %#o", current)
+	}
+
+	path := filepath.Join(dir, "invalid.sock")
+	if _, err := Listen(Name("unix", path), "unix", path, Permissions{Mode: "rw"}); err == nil {
+		t.Fatal("listened with an invalid mode")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("socket path is left after a failed listen: %v", err)
+	}
+}
+
+func TestListenReplacesOnlySockets(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "stale.sock")
+	old, err := net.Listen("unix", stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	/* a crashed daemon leaves its socket path behind */
+	old.(*net.UnixListener).SetUnlinkOnClose(false)
+	old.Close()
+	sock, err := Listen(Name("unix", stale), "unix", stale, Permissions{})
+	if err != nil {
+		t.Fatalf("listening over a stale socket: %v", err)
+	}
+	sock.Close()
+
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, []byte("data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(stale, link); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{file, link, dir} {
+		if _, err := Listen(Name("unix", path), "unix", path, Permissions{}); err == nil {
+			t.Fatalf("listened over %s", path)
+		}
+		if _, err := os.Lstat(path); err != nil {
+			t.Fatalf("%s is removed: %v", path, err)
+		}
+	}
+}
+
+/* systemd passes the socket as fd 3 of a child, which serves it under a name of its own */
+func TestActivatedListener(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sigrpcd.sock")
+	sock, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sock.(*net.UnixListener).SetUnlinkOnClose(false)
+	defer sock.Close()
+	file, err := sock.(*net.UnixListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestActivatedChild$", "-test.v")
+	cmd.Env = append(os.Environ(),
+		envTestActivatedAddr+"="+path,
+		"LISTEN_FDS=1",
+		"LISTEN_FDNAMES=sigrpcd.socket")
+	cmd.ExtraFiles = []*os.File{file}
+	out, err := cmd.CombinedOutput()
+	if err != nil || !bytes.Contains(out, []byte("--- PASS: TestActivatedChild")) {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("socket path owned by systemd is removed: %v", err)
+	}
+}
+
+func TestActivatedChild(t *testing.T) {
+	addr := os.Getenv(envTestActivatedAddr)
+	if len(addr) == 0 {
+		t.Skip("run by TestActivatedListener")
+	}
+	os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	activated, err := Activated()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(activated) != 1 {
+		t.Fatalf("%d activated listeners", len(activated))
+	}
+	sock := activated[0]
+	if sock.Name != "sigrpcd.socket" || !sock.Activated || sock.Unlink {
+		t.Fatalf("activated listener %q activated %v unlink %v", sock.Name, sock.Activated, sock.Unlink)
+	}
+
+	available := activated
+	if Take(&available, Name("unix", addr+".other"), "unix", addr+".other") != nil {
+		t.Fatal("took the activated listener for another address")
+	}
+	/* configured by address only, the name differs from the one systemd gave */
+	if Take(&available, Name("unix", addr), "unix", addr) != sock || len(available) != 0 {
+		t.Fatal("the activated listener is not taken for its address")
+	}
+
+	go func() {
+		if conn, err := sock.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+	conn, err := net.Dial("unix", addr)
+	if err != nil {
+		t.Fatalf("dialing the activated listener: %v", err)
+	}
+	conn.Close()
+	if err := sock.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(addr); err != nil {
+		t.Fatalf("closing an activated listener removes its path: %v", err)
+	}
+}
+
+/*
+ * a child listens and upgrades, which re-executes the test binary with the same arguments,
+ * so the new process runs TestUpgradeChild again and takes the listener over
+ */
+func TestUpgradeHandsOverListeners(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sigrpcd.sock")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestUpgradeChild$", "-test.v")
+	cmd.Env = append(os.Environ(), envTestUpgradeAddr+"="+path)
+	/* the output closes only when the new process exits as well */
+	out, err := cmd.CombinedOutput()
+	if err != nil || bytes.Count(out, []byte("--- PASS: TestUpgradeChild")) != 2 {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("socket path is left after the new process closed it: %v", err)
+	}
+}
+
+func TestUpgradeChild(t *testing.T) {
+	addr := os.Getenv(envTestUpgradeAddr)
+	if len(addr) == 0 {
+		t.Skip("run by TestUpgradeHandsOverListeners")
+	}
+	if len(os.Getenv(envListenFDs)) > 0 {
+		upgradedChild(t, addr)
+		return
+	}
+	sock, err := Listen(Name("unix", addr), "unix", addr, Permissions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Upgrade([]*Named{sock}, 30*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if sock.Unlink {
+		t.Fatal("the old process still owns the socket path")
+	}
+	if err := sock.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(addr); err != nil {
+		t.Fatalf("closing the handed over listener removes its path: %v", err)
+	}
+	/* only the new process accepts now */
+	conn, err := net.Dial("unix", addr)
+	if err != nil {
+		t.Fatalf("dialing the upgraded listener: %v", err)
+	}
+	defer conn.Close()
+	reply := make([]byte, len("upgraded"))
+	if _, err := io.ReadFull(conn, reply); err != nil || string(reply) != "upgraded" {
+		t.Fatalf("new process answered %q (%v)", reply, err)
+	}
+}
+
+/* the new process started by Upgrade */
+func upgradedChild(t *testing.T, addr string) {
+	inherited, err := Inherited()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inherited) != 1 {
+		t.Fatalf("%d inherited listeners", len(inherited))
+	}
+	sock := inherited[0]
+	defer sock.Close()
+	if sock.Name != Name("unix", addr) || !sock.Unlink || sock.Activated {
+		t.Fatalf("inherited listener %q unlink %v activated %v", sock.Name, sock.Unlink, sock.Activated)
+	}
+	if len(os.Getenv(envListenFDs)) > 0 {
+		t.Fatal("the listeners are handed on to later children")
+	}
+	if err := NotifyReady(); err != nil {
+		t.Fatal(err)
+	}
+	sock.Listener.(*net.UnixListener).SetDeadline(time.Now().Add(30 * time.Second))
+	conn, err := sock.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("upgraded")); err != nil {
+		t.Fatal(err)
+	}
+	/* the old process hangs up once it has read the reply */
+	io.Copy(io.Discard, conn)
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package listener
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+/* listeners passed by systemd socket activation (sd_listen_fds(3)) */
+func Activated() ([]*Named, error) {
+	pidStr := os.Getenv("LISTEN_PID")
+	fdsStr := os.Getenv("LISTEN_FDS")
+	namesStr := os.Getenv("LISTEN_FDNAMES")
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	if len(pidStr) == 0 || len(fdsStr) == 0 {
+		return nil, nil
+	}
+	pid, err := strconv.Atoi(pidStr)
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	fds, err := strconv.Atoi(fdsStr)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if len(namesStr) > 0 {
+		names = strings.Split(namesStr, ":")
+	}
+	for len(names) < fds {
+		names = append(names, "unknown")
+	}
+	names = names[:fds]
+	for i := 0; i < fds; i++ {
+		syscall.CloseOnExec(listenFDsStart + i)
+	}
+	listeners, err := fileListeners(names)
+	if err != nil {
+		return nil, err
+	}
+	for _, listener := range listeners {
+		listener.Activated = true
+	}
+	return listeners, nil
+}
@@ -0,0 +1,179 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	envListenFDs      = "SIGRPCD_LISTEN_FDS"
+	envListenFDNames  = "SIGRPCD_LISTEN_FDNAMES"
+	envListenFDUnlink = "SIGRPCD_LISTEN_FDUNLINK"
+	envReadyFD        = "SIGRPCD_READY_FD"
+)
+
+func fileListeners(names []string) ([]*Named, error) {
+	listeners := make([]*Named, 0, len(names))
+	for i, name := range names {
+		file := os.NewFile(uintptr(listenFDsStart+i), name)
+		sock, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		listeners = append(listeners, newNamed(name, sock))
+	}
+	return listeners, nil
+}
+
+/* listeners handed over by a previous sigrpcd on upgrade */
+func Inherited() ([]*Named, error) {
+	fdsStr := os.Getenv(envListenFDs)
+	if len(fdsStr) == 0 {
+		return nil, nil
+	}
+	names := strings.Split(os.Getenv(envListenFDNames), "\n")
+	unlink := os.Getenv(envListenFDUnlink)
+	os.Unsetenv(envListenFDs)
+	os.Unsetenv(envListenFDNames)
+	os.Unsetenv(envListenFDUnlink)
+	fds, err := strconv.Atoi(fdsStr)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) != fds || len(unlink) != fds {
+		return nil, fmt.Errorf("malformed %s for %d listeners", envListenFDNames, fds)
+	}
+	listeners, err := fileListeners(names)
+	if err != nil {
+		return nil, err
+	}
+	for i, listener := range listeners {
+		listener.Unlink = unlink[i] == '1'
+	}
+	return listeners, nil
+}
+
+func NotifyReady() error {
+	fdStr := os.Getenv(envReadyFD)
+	if len(fdStr) == 0 {
+		return nil
+	}
+	os.Unsetenv(envReadyFD)
+	fd, err := strconv.Atoi(fdStr)
+	if err != nil {
+		return err
+	}
+	file := os.NewFile(uintptr(fd), "ready")
+	defer file.Close()
+	_, err = file.Write([]byte{1})
+	return err
+}
+
+func childEnv() []string {
+	env := make([]string, 0, len(os.Environ())+4)
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, envListenFDs+"=") ||
+			strings.HasPrefix(kv, envListenFDNames+"=") ||
+			strings.HasPrefix(kv, envListenFDUnlink+"=") ||
+			strings.HasPrefix(kv, envReadyFD+"=") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
+
+/* re-executes sigrpcd with the listeners and waits until the new process serves them */
+func Upgrade(listeners []*Named, timeout time.Duration) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	files := []*os.File{os.Stdin, os.Stdout, os.Stderr}
+	names := make([]string, 0, len(listeners))
+	unlink := make([]byte, 0, len(listeners))
+	defer func() {
+		for _, file := range files[listenFDsStart:] {
+			file.Close()
+		}
+	}()
+	for _, listener := range listeners {
+		filer, ok := listener.Listener.(interface{ File() (*os.File, error) })
+		if !ok {
+			return fmt.Errorf("%s cannot be handed over", listener.Name)
+		}
+		file, err := filer.File()
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+		names = append(names, listener.Name)
+		if listener.Unlink {
+			unlink = append(unlink, '1')
+		} else {
+			unlink = append(unlink, '0')
+		}
+	}
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer readyR.Close()
+	files = append(files, readyW)
+	env := append(childEnv(),
+		envListenFDs+"="+strconv.Itoa(len(listeners)),
+		envListenFDNames+"="+strings.Join(names, "\n"),
+		envListenFDUnlink+"="+string(unlink),
+		envReadyFD+"="+strconv.Itoa(len(files)-1))
+	process, err := os.StartProcess(executable, os.Args, &os.ProcAttr{
+		Env:   env,
+		Files: files,
+	})
+	if err != nil {
+		return err
+	}
+	readyW.Close()
+	files = files[:len(files)-1]
+	ready := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		_, err := readyR.Read(buf)
+		ready <- err
+	}()
+	select {
+	case err = <-ready:
+	case <-time.After(timeout):
+		err = errors.New("timed out waiting for the new sigrpcd")
+	}
+	if err != nil {
+		process.Kill()
+		process.Wait()
+		return err
+	}
+	process.Release()
+	/* the socket paths now belong to the new process */
+	for _, listener := range listeners {
+		listener.Unlink = false
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"testing"
+
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+)
+
+func FuzzParseRegion(f *testing.F) {
+	f.Add("7f0000000000-7f0000001000 r-xp 00001000 08:01 1234    /usr/lib/libc.so.6")
+	f.Add("7ffc00000000-7ffc00021000 rw-p 00000000 00:00 0      [stack]")
+	f.Add("55d000000000-55d000001000 rw-s 00000000 00:01 42     /memfd:sigrpc (deleted)")
+	f.Fuzz(func(t *testing.T, line string) {
+		region, err := parseRegion(line)
+		if err != nil {
+			return
+		}
+		if region.X64.Kind != x64.MappingKind_MAPPING_KIND_FILE && region.X64.Path != "" {
+			t.Fatalf("%v mapping with path %q", region.X64.Kind, region.X64.Path)
+		}
+	})
+}
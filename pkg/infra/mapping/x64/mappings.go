@@ -0,0 +1,176 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/mapping"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/peer"
+	mappingrepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/mapping"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+	peercredentials "github.com/sigrpc/sigrpcd/pkg/infra/peer"
+)
+
+type Mappings struct{}
+
+func NewMappings() mappingrepository.Mappings {
+	return &Mappings{}
+}
+
+func kind(path string) x64.MappingKind {
+	switch {
+	case len(path) == 0 || strings.HasPrefix(path, "[anon:"):
+		return x64.MappingKind_MAPPING_KIND_ANONYMOUS
+	case path == "[heap]":
+		return x64.MappingKind_MAPPING_KIND_HEAP
+	case path == "[stack]" || strings.HasPrefix(path, "[stack:"):
+		return x64.MappingKind_MAPPING_KIND_STACK
+	case strings.HasPrefix(path, "/"):
+		return x64.MappingKind_MAPPING_KIND_FILE
+	}
+	return x64.MappingKind_MAPPING_KIND_UNKNOWN
+}
+
+/* 7f0000000000-7f0000001000 r-xp 00001000 08:01 1234    /usr/lib/libc.so.6 */
+func parseRegion(line string) (mapping.Region, error) {
+	fields := strings.SplitN(line, " ", 6)
+	if len(fields) < 5 {
+		return mapping.Region{}, fmt.Errorf("malformed maps line %q", line)
+	}
+	start, end, ok := strings.Cut(fields[0], "-")
+	if !ok || len(fields[1]) != 4 {
+		return mapping.Region{}, fmt.Errorf("malformed maps line %q", line)
+	}
+	region := mapping.Region{
+		X64: &x64.Mapping{},
+	}
+	var err error
+	if region.Start, err = strconv.ParseUint(start, 16, 64); err != nil {
+		return mapping.Region{}, err
+	}
+	if region.End, err = strconv.ParseUint(end, 16, 64); err != nil {
+		return mapping.Region{}, err
+	}
+	if fields[1][0] == 'r' {
+		region.X64.Protection |= mapping.PROTREAD
+	}
+	if fields[1][1] == 'w' {
+		region.X64.Protection |= mapping.PROTWRITE
+	}
+	if fields[1][2] == 'x' {
+		region.X64.Protection |= mapping.PROTEXEC
+	}
+	region.X64.Shared = fields[1][3] == 's'
+	path := ""
+	if len(fields) == 6 {
+		path = strings.TrimSuffix(strings.TrimLeft(fields[5], " "), " (deleted)")
+	}
+	region.X64.Kind = kind(path)
+	if region.X64.Kind == x64.MappingKind_MAPPING_KIND_FILE {
+		region.X64.Path = path
+		if region.X64.Offset, err = strconv.ParseUint(fields[2], 16, 64); err != nil {
+			return mapping.Region{}, err
+		}
+	}
+	return region, nil
+}
+
+/* regions of /proc/<pid>/maps in ascending order */
+func regions(pid int32) ([]mapping.Region, error) {
+	file, err := os.Open("/proc/" + strconv.FormatInt(int64(pid), 10) + "/maps")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	regions := make([]mapping.Region, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		region, err := parseRegion(scanner.Text())
+		if err != nil {
+			return nil, err
+		}
+		regions = append(regions, region)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return regions, nil
+}
+
+/* fills the mapping of pages the client left out, pages outside any region are left as they are */
+func annotate(peer *peer.Peer, pages []*x64.Page) error {
+	if peer == nil || peer.Pid == 0 {
+		return nil
+	}
+	omitted := false
+	for _, page := range pages {
+		if page.Mapping == nil {
+			omitted = true
+			break
+		}
+	}
+	if !omitted {
+		return nil
+	}
+	regions, err := regions(peer.Pid)
+	if err != nil {
+		return err
+	}
+	/* read after the pid may have been reused, the layout is then of another process */
+	startTime, err := peercredentials.StartTime(peer.Pid)
+	if err != nil {
+		return fmt.Errorf("client pid %d has exited: %w", peer.Pid, err)
+	}
+	if startTime != peer.StartTime {
+		return fmt.Errorf("client pid %d has exited and is reused", peer.Pid)
+	}
+	for _, page := range pages {
+		if page.Mapping != nil {
+			continue
+		}
+		i := sort.Search(len(regions), func(i int) bool {
+			return regions[i].End > page.Address
+		})
+		if i == len(regions) || regions[i].Start > page.Address {
+			continue
+		}
+		region := regions[i].X64
+		page.Mapping = &x64.Mapping{
+			Protection: region.Protection,
+			Shared:     region.Shared,
+			Kind:       region.Kind,
+			Path:       region.Path,
+		}
+		if region.Kind == x64.MappingKind_MAPPING_KIND_FILE {
+			page.Mapping.Offset = region.Offset + page.Address - regions[i].Start
+		}
+	}
+	return nil
+}
+
+func (m *Mappings) Invoke(peer *peer.Peer, invokeFunc *msg.InvokeFuncMsg) error {
+	return annotate(peer, invokeFunc.X64.Page)
+}
+
+func (m *Mappings) Pull(peer *peer.Peer, pullPage *msg.PullPageMsg) error {
+	return annotate(peer, pullPage.X64.Page)
+}
@@ -0,0 +1,65 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"os"
+	"testing"
+	"unsafe"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/mapping"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/peer"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+	peercredentials "github.com/sigrpc/sigrpcd/pkg/infra/peer"
+)
+
+var heapByte = new(byte)
+
+func self(t *testing.T) *peer.Peer {
+	t.Helper()
+	pid := int32(os.Getpid())
+	startTime, err := peercredentials.StartTime(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &peer.Peer{Pid: pid, StartTime: startTime}
+}
+
+func TestAnnotateFillsOmittedMappings(t *testing.T) {
+	address := uint64(uintptr(unsafe.Pointer(heapByte)))
+	given := &x64.Mapping{Kind: x64.MappingKind_MAPPING_KIND_STACK}
+	pages := []*x64.Page{{Address: address}, {Address: address, Mapping: given}}
+	if err := annotate(self(t), pages); err != nil {
+		t.Fatal(err)
+	}
+	if pages[0].Mapping == nil || pages[0].Mapping.Protection&mapping.PROTWRITE == 0 {
+		t.Fatalf("writable page is annotated with %v", pages[0].Mapping)
+	}
+	if pages[1].Mapping != given {
+		t.Fatal("mapping sent by the client is replaced")
+	}
+}
+
+func TestAnnotateRejectsReusedPid(t *testing.T) {
+	p := self(t)
+	p.StartTime++
+	pages := []*x64.Page{{Address: uint64(uintptr(unsafe.Pointer(heapByte)))}}
+	if err := annotate(p, pages); err == nil {
+		t.Fatal("annotated pages of a reused pid")
+	}
+	if pages[0].Mapping != nil {
+		t.Fatalf("layout of another process is leaked: %v", pages[0].Mapping)
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/memory"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/peer"
+	memoryrepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/memory"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+	peercredentials "github.com/sigrpc/sigrpcd/pkg/infra/peer"
+)
+
+type Memory struct {
+	config   memory.Config
+	pageSize uint64
+	limits   limit.Limits
+}
+
+func NewMemory(config memory.Config, pageSize uint64, limits limit.Limits) memoryrepository.Memory {
+	return &Memory{
+		config:   config,
+		pageSize: pageSize,
+		limits:   limits,
+	}
+}
+
+/* the start of the first readable region in /proc/<pid>/maps */
+func readableAddress(pid int32) (uint64, error) {
+	file, err := os.Open("/proc/" + strconv.FormatInt(int64(pid), 10) + "/maps")
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.HasPrefix(fields[1], "r") {
+			continue
+		}
+		start, _, _ := strings.Cut(fields[0], "-")
+		return strconv.ParseUint(start, 16, 64)
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errors.New("no readable region")
+}
+
+/* the memory of one client, bound to its address space when opened and never to another process with its pid */
+type AddressSpace struct {
+	*Memory
+	peer *peer.Peer
+	mem  *os.File
+}
+
+/*
+ * /proc/<pid>/mem refers to the address space of the process at open, so the start time
+ * is checked once after that and a later exit only makes the accesses fail
+ */
+func (m *Memory) Open(peer *peer.Peer) (memoryrepository.AddressSpace, error) {
+	if peer == nil || peer.Pid == 0 {
+		return nil, errors.New("direct memory access needs a local peer")
+	}
+	if !m.config.Allow.Allows(peer) {
+		return nil, fmt.Errorf("direct memory access is not allowed for uid %d gid %d", peer.Uid, peer.Gid)
+	}
+	mem, err := os.OpenFile("/proc/"+strconv.FormatInt(int64(peer.Pid), 10)+"/mem", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	space := &AddressSpace{Memory: m, peer: peer, mem: mem}
+	if err := space.check(); err != nil {
+		mem.Close()
+		return nil, err
+	}
+	return space, nil
+}
+
+/* reading a byte proves that the daemon may access the client */
+func (s *AddressSpace) check() error {
+	startTime, err := peercredentials.StartTime(s.peer.Pid)
+	if err != nil {
+		return fmt.Errorf("client pid %d has exited: %w", s.peer.Pid, err)
+	}
+	if startTime != s.peer.StartTime {
+		return fmt.Errorf("client pid %d has exited and is reused", s.peer.Pid)
+	}
+	address, err := readableAddress(s.peer.Pid)
+	if err != nil {
+		return err
+	}
+	if _, err := s.mem.ReadAt(make([]byte, 1), int64(address)); err != nil {
+		return fmt.Errorf("reading memory of pid %d: %w", s.peer.Pid, err)
+	}
+	return nil
+}
+
+func (s *AddressSpace) Close() error {
+	return s.mem.Close()
+}
+
+func (m *Memory) length(page *x64.Page) uint64 {
+	if page.Length != 0 {
+		return page.Length
+	}
+	return m.pageSize
+}
+
+/* transfers pages one by one, calling failed for a page that cannot be accessed */
+func (s *AddressSpace) transfer(pages []*x64.Page, write bool, failed func(*x64.Page, error) error) error {
+	for _, page := range pages {
+		var err error
+		if page.Address > math.MaxInt64 {
+			err = errors.New("address out of range")
+		} else if write {
+			_, err = s.mem.WriteAt(page.Content, int64(page.Address))
+		} else {
+			_, err = s.mem.ReadAt(page.Content, int64(page.Address))
+		}
+		if err == nil {
+			continue
+		}
+		if err := failed(page, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *AddressSpace) read(faults []*x64.Page) ([]*x64.Page, error) {
+	pages := make([]*x64.Page, 0, len(faults))
+	for _, fault := range faults {
+		length := s.length(fault)
+		if length == 0 || length > uint64(s.limits.MaxPageContentSize) {
+			return nil, fmt.Errorf("page %#x has an invalid length %d", fault.Address, length)
+		}
+		pages = append(pages, &x64.Page{
+			Address:         fault.Address,
+			RuntimeRevision: fault.RuntimeRevision,
+			ClientRevision:  fault.ClientRevision,
+			Length:          fault.Length,
+			ContentSize:     uint32(length),
+			Content:         make([]byte, length),
+		})
+	}
+	err := s.transfer(pages, false, func(page *x64.Page, err error) error {
+		return fmt.Errorf("reading page %#x of pid %d: %w", page.Address, s.peer.Pid, err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+/* answers page faults of the stub from the client memory until it replies without faults */
+func (s *AddressSpace) Invoke(
+	req *msg.InvokeFuncMsg,
+	invoke func(*msg.InvokeFuncMsg) (*msg.InvokeFuncMsg, error)) (*msg.InvokeFuncMsg, error) {
+	resp, err := invoke(req)
+	for rounds := uint64(0); err == nil && resp.X64 != nil && len(resp.X64.Fault) > 0; rounds++ {
+		if rounds >= s.config.MaxFaultRounds {
+			return nil, fmt.Errorf("the stub faulted more than %d times in an invocation", s.config.MaxFaultRounds)
+		}
+		var pages []*x64.Page
+		pages, err = s.read(resp.X64.Fault)
+		if err != nil {
+			return nil, err
+		}
+		/* the client has not run since the request, so its context is unchanged */
+		resp, err = invoke(&msg.InvokeFuncMsg{
+			X64: &x64.InvokeFuncMsg{
+				Header:       req.X64.Header,
+				InvokefuncId: resp.X64.InvokefuncId,
+				RespId:       resp.X64.RespId,
+				Ctx:          req.X64.Ctx,
+				Page:         pages,
+			},
+		})
+	}
+	return resp, err
+}
+
+/* writes full pages of a reply into the client memory, pages that cannot be written are left to the client */
+func (s *AddressSpace) WriteBack(invokeFunc *msg.InvokeFuncMsg) error {
+	if invokeFunc == nil || invokeFunc.X64 == nil {
+		return nil
+	}
+	pages := make([]*x64.Page, 0, len(invokeFunc.X64.Page))
+	rest := make([]*x64.Page, 0)
+	for _, page := range invokeFunc.X64.Page {
+		if page.Encoding == x64.PageEncoding_PAGE_ENCODING_FULL && len(page.Content) > 0 {
+			pages = append(pages, page)
+		} else {
+			rest = append(rest, page)
+		}
+	}
+	var errs []error
+	err := s.transfer(pages, true, func(page *x64.Page, err error) error {
+		rest = append(rest, page)
+		errs = append(errs, fmt.Errorf("writing page %#x of pid %d: %w", page.Address, s.peer.Pid, err))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	invokeFunc.X64.Page = rest
+	return errors.Join(errs...)
+}
@@ -0,0 +1,155 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/memory"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/peer"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+	peercredentials "github.com/sigrpc/sigrpcd/pkg/infra/peer"
+)
+
+/* the test process is its own client, its memory is always accessible */
+func self(t *testing.T) *peer.Peer {
+	t.Helper()
+	pid := int32(os.Getpid())
+	startTime, err := peercredentials.StartTime(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &peer.Peer{Pid: pid, Uid: uint32(os.Getuid()), Gid: uint32(os.Getgid()), StartTime: startTime}
+}
+
+func testSpace(t *testing.T, p *peer.Peer, rounds uint64) *AddressSpace {
+	t.Helper()
+	config := memory.NewDefaultConfig()
+	config.Enabled = true
+	config.MaxFaultRounds = rounds
+	space, err := NewMemory(config, uint64(os.Getpagesize()), limit.NewDefaultLimits()).Open(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { space.Close() })
+	return space.(*AddressSpace)
+}
+
+func faultOn(buf []byte) *msg.InvokeFuncMsg {
+	return &msg.InvokeFuncMsg{X64: &x64.InvokeFuncMsg{
+		Header: &x64.RPCHeader{MsgType: msg.INVOKEFUNC},
+		Fault:  []*x64.Page{{Address: uint64(uintptr(unsafe.Pointer(&buf[0]))), Length: uint64(len(buf))}},
+	}}
+}
+
+func writeBack(buf []byte, content string) *msg.InvokeFuncMsg {
+	reply := faultOn(buf)
+	reply.X64.Page = []*x64.Page{{
+		Address: reply.X64.Fault[0].Address,
+		Length:  uint64(len(buf)),
+		Content: []byte(content),
+	}}
+	return reply
+}
+
+func TestInvokeReadsFaults(t *testing.T) {
+	buf := []byte("content of the client")
+	var got []byte
+	invoke := func(req *msg.InvokeFuncMsg) (*msg.InvokeFuncMsg, error) {
+		if len(req.X64.Page) == 0 {
+			return faultOn(buf), nil
+		}
+		got = req.X64.Page[0].Content
+		return &msg.InvokeFuncMsg{X64: &x64.InvokeFuncMsg{}}, nil
+	}
+	if _, err := testSpace(t, self(t), 1).Invoke(&msg.InvokeFuncMsg{X64: &x64.InvokeFuncMsg{}}, invoke); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, buf) {
+		t.Fatalf("read %q, want %q", got, buf)
+	}
+}
+
+func TestInvokeBoundsFaultRounds(t *testing.T) {
+	buf := make([]byte, 8)
+	calls := 0
+	invoke := func(*msg.InvokeFuncMsg) (*msg.InvokeFuncMsg, error) {
+		calls++
+		return faultOn(buf), nil
+	}
+	_, err := testSpace(t, self(t), 3).Invoke(&msg.InvokeFuncMsg{X64: &x64.InvokeFuncMsg{}}, invoke)
+	if err == nil {
+		t.Fatal("a stub faulting forever is answered")
+	}
+	if calls != 4 {
+		t.Fatalf("the stub is invoked %d times, want 4", calls)
+	}
+}
+
+func TestOpenRejectsReusedPid(t *testing.T) {
+	reused := self(t)
+	reused.StartTime++
+	_, err := NewMemory(memory.NewDefaultConfig(), uint64(os.Getpagesize()), limit.NewDefaultLimits()).Open(reused)
+	if err == nil || !strings.Contains(err.Error(), "reused") {
+		t.Fatalf("Open of a reused pid: %v", err)
+	}
+}
+
+/* the address space stays the one opened, an exited client is never written through its pid */
+func TestExitedClientIsNotAccessed(t *testing.T) {
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Skip(err)
+	}
+	pid := int32(cmd.Process.Pid)
+	startTime, err := peercredentials.StartTime(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	space := testSpace(t, &peer.Peer{Pid: pid, StartTime: startTime}, 1)
+	cmd.Process.Kill()
+	cmd.Wait()
+	buf := make([]byte, 8)
+	if err := space.WriteBack(writeBack(buf, "written!")); err == nil {
+		t.Fatal("pages are written back to an exited client")
+	}
+	invoke := func(req *msg.InvokeFuncMsg) (*msg.InvokeFuncMsg, error) {
+		if len(req.X64.Page) > 0 {
+			t.Fatal("pages of an exited client are sent to the stub")
+		}
+		return faultOn(buf), nil
+	}
+	if _, err := space.Invoke(&msg.InvokeFuncMsg{X64: &x64.InvokeFuncMsg{}}, invoke); err == nil {
+		t.Fatal("faults are answered from an exited client")
+	}
+}
+
+func TestWriteBack(t *testing.T) {
+	buf := make([]byte, 8)
+	reply := writeBack(buf, "written!")
+	if err := testSpace(t, self(t), 1).WriteBack(reply); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "written!" || len(reply.X64.Page) != 0 {
+		t.Fatalf("memory %q, %d pages left in the reply", buf, len(reply.X64.Page))
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/cpu"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/page"
+	x64cpu "github.com/sigrpc/sigrpcd/pkg/infra/cpu/x64"
+	x64page "github.com/sigrpc/sigrpcd/pkg/infra/page/x64"
+	x64uctx "github.com/sigrpc/sigrpcd/pkg/infra/ucontext/x64"
+)
+
+const (
+	TESTCLIENTID   = "test"
+	RPCHEADERSIZE  = 20
+	TESTPAGESIZE   = 4096
+	TESTREGIONSIZE = 0x10000
+	TESTBUDGET     = 0x400000
+)
+
+/* a connection reading from a buffer, enough for RPCHeaderCodec.Decode */
+type readerConn struct {
+	net.Conn
+	reader io.Reader
+}
+
+func (c readerConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+type testCodecs struct {
+	rpcHeader  *RPCHeaderCodec
+	hello      *HelloCodec
+	loadLib    *LoadLibCodec
+	invokeFunc *InvokeFuncCodec
+	pullPage   *PullPageCodec
+}
+
+func newTestCodecs() testCodecs {
+	limits := limit.NewDefaultLimits()
+	rpcHeader := NewRPCHeaderCodec(TESTCLIENTID)
+	pageCodec := x64page.NewPageCodec(limits)
+	return testCodecs{
+		rpcHeader: rpcHeader.(*RPCHeaderCodec),
+		hello:     NewHelloCodec(rpcHeader).(*HelloCodec),
+		loadLib:   NewLoadLibCodec(rpcHeader, limits).(*LoadLibCodec),
+		invokeFunc: NewInvokeFuncCodec(
+			x64uctx.NewCodec(x64cpu.NewCodec()), pageCodec, rpcHeader, limits).(*InvokeFuncCodec),
+		pullPage: NewPullPageCodec(pageCodec, rpcHeader, limits).(*PullPageCodec),
+	}
+}
+
+/*
+ * bit 0 selects ranges, bit 1 shared memory, bit 2 an XSAVE area,
+ * bit 3 an error status and bit 4 leaves elision and dirty ranges out
+ */
+func testLayout(mode uint8) page.Layout {
+	layout := page.Layout{
+		PageSize:    TESTPAGESIZE,
+		Ranges:      mode&1 != 0,
+		Elision:     mode&16 == 0,
+		DirtyRanges: mode&16 == 0,
+		Budget:      limit.NewBudget(TESTBUDGET, nil),
+	}
+	if mode&2 != 0 {
+		layout.Shared = &page.Shared{
+			Region: make([]byte, TESTREGIONSIZE),
+		}
+	}
+	if mode&4 != 0 {
+		layout.XStateSize = cpu.XSTATEMINSIZE
+	}
+	return layout
+}
+
+/* decodes the header of an encoded message and returns a reader of exactly its payload */
+func redecodeHeader(t *testing.T, codecs testCodecs, encoded []byte, layout page.Layout) (*msg.RPCHeader, *bytes.Reader) {
+	t.Helper()
+	reader := bytes.NewReader(encoded)
+	header, err := codecs.rpcHeader.Decode(readerConn{reader: reader})
+	if err != nil {
+		t.Fatalf("decoding an encoded header: %v", err)
+	}
+	if header.X64.PayloadSize != uint64(reader.Len()) {
+		t.Fatalf("header payload size %d with %d bytes following", header.X64.PayloadSize, reader.Len())
+	}
+	layout.Budget = limit.NewBudget(TESTBUDGET, nil)
+	header.Layout = layout
+	return header, reader
+}
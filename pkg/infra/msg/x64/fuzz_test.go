@@ -0,0 +1,176 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"bytes"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+)
+
+func fuzzHeader(msgType uint32, data []byte, mode uint8) *msg.RPCHeader {
+	header := &msg.RPCHeader{
+		X64: &x64.RPCHeader{
+			MsgType:     msgType,
+			ClientId:    TESTCLIENTID + "-1",
+			PayloadSize: uint64(len(data)),
+		},
+		Layout: testLayout(mode),
+	}
+	if mode&8 != 0 {
+		header.X64.Status = msg.STATUSINVALIDARGUMENT
+	}
+	return header
+}
+
+func FuzzRPCHeaderDecode(f *testing.F) {
+	codecs := newTestCodecs()
+	f.Add(codecs.rpcHeader.Encode(&msg.RPCHeader{
+		X64: &x64.RPCHeader{MsgType: msg.HELLO, ClientId: TESTCLIENTID + "-1", PayloadSize: 24},
+	}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		header, err := codecs.rpcHeader.Decode(readerConn{reader: bytes.NewReader(data)})
+		if err != nil {
+			return
+		}
+		if encoded := codecs.rpcHeader.Encode(header); !bytes.Equal(encoded, data[:RPCHEADERSIZE]) {
+			t.Fatalf("header changed through encoding\n%x\n%x", data[:RPCHEADERSIZE], encoded)
+		}
+	})
+}
+
+func FuzzHelloDecode(f *testing.F) {
+	codecs := newTestCodecs()
+	hello := codecs.hello.Encode(&msg.HelloMsg{X64: &x64.HelloMsg{
+		Header:   &x64.RPCHeader{MsgType: msg.HELLO, ClientId: TESTCLIENTID + "-1"},
+		Version:  1,
+		PageSize: TESTPAGESIZE,
+		Features: 0xff,
+	}})
+	f.Add(hello[RPCHEADERSIZE:], uint8(0))
+	f.Fuzz(func(t *testing.T, data []byte, mode uint8) {
+		header := fuzzHeader(msg.HELLO, data, mode)
+		decoded, err := codecs.hello.Decode(bytes.NewReader(data), header)
+		if err != nil {
+			return
+		}
+		header, reader := redecodeHeader(t, codecs, codecs.hello.Encode(decoded), header.Layout)
+		redecoded, err := codecs.hello.Decode(reader, header)
+		if err != nil {
+			t.Fatalf("decoding an encoded hello: %v", err)
+		}
+		if !proto.Equal(decoded.X64, redecoded.X64) {
+			t.Fatalf("hello changed through encoding\n%v\n%v", decoded.X64, redecoded.X64)
+		}
+	})
+}
+
+func FuzzLoadLibDecode(f *testing.F) {
+	codecs := newTestCodecs()
+	loadLib := codecs.loadLib.Encode(&msg.LoadLibMsg{X64: &x64.LoadLibMsg{
+		Header:      &x64.RPCHeader{MsgType: msg.LOADLIB, ClientId: TESTCLIENTID + "-1"},
+		LibraryName: "libfuzz.so",
+		Addr2Sym:    []*x64.Addr2Sym{{Address: 0x1000, Name: "fuzz"}},
+	}})
+	f.Add(loadLib[RPCHEADERSIZE:], uint8(0))
+	f.Fuzz(func(t *testing.T, data []byte, mode uint8) {
+		header := fuzzHeader(msg.LOADLIB, data, mode)
+		decoded, err := codecs.loadLib.Decode(bytes.NewReader(data), header)
+		if err != nil {
+			return
+		}
+		header, reader := redecodeHeader(t, codecs, codecs.loadLib.Encode(decoded), header.Layout)
+		redecoded, err := codecs.loadLib.Decode(reader, header)
+		if err != nil {
+			t.Fatalf("decoding an encoded loadlib: %v", err)
+		}
+		if !proto.Equal(decoded.X64, redecoded.X64) {
+			t.Fatalf("loadlib changed through encoding\n%v\n%v", decoded.X64, redecoded.X64)
+		}
+	})
+}
+
+func fuzzPages() []*x64.Page {
+	content := bytes.Repeat([]byte{0xa5}, TESTPAGESIZE)
+	return []*x64.Page{
+		{Address: 0x1000, Content: content},
+		{Address: 0x2000, ContentSize: TESTPAGESIZE, Encoding: x64.PageEncoding_PAGE_ENCODING_ZERO},
+		{Address: 0x3000, Content: content},
+	}
+}
+
+func FuzzInvokeFuncDecode(f *testing.F) {
+	codecs := newTestCodecs()
+	for _, mode := range []uint8{0, 3, 4} {
+		invokeFunc := &msg.InvokeFuncMsg{X64: &x64.InvokeFuncMsg{
+			Header:       &x64.RPCHeader{MsgType: msg.INVOKEFUNC, ClientId: TESTCLIENTID + "-1"},
+			InvokefuncId: 1,
+			Ctx:          &x64.UserContext{Cpu: &x64.CPUState{}, StackBottom: 0x7ffc0000},
+			Page:         fuzzPages(),
+		}}
+		codecs.invokeFunc.Elide(invokeFunc)
+		encoded := bytes.Join(codecs.invokeFunc.Encode(invokeFunc, testLayout(mode)), nil)
+		f.Add(encoded[RPCHEADERSIZE:], mode)
+	}
+	f.Fuzz(func(t *testing.T, data []byte, mode uint8) {
+		header := fuzzHeader(msg.INVOKEFUNC, data, mode)
+		decoded, err := codecs.invokeFunc.Decode(bytes.NewReader(data), header)
+		if err != nil {
+			return
+		}
+		encoded := bytes.Join(codecs.invokeFunc.Encode(decoded, header.Layout), nil)
+		header, reader := redecodeHeader(t, codecs, encoded, header.Layout)
+		redecoded, err := codecs.invokeFunc.Decode(reader, header)
+		if err != nil {
+			t.Fatalf("decoding an encoded invokefunc: %v", err)
+		}
+		if !proto.Equal(decoded.X64, redecoded.X64) {
+			t.Fatalf("invokefunc changed through encoding\n%v\n%v", decoded.X64, redecoded.X64)
+		}
+	})
+}
+
+func FuzzPullPageDecode(f *testing.F) {
+	codecs := newTestCodecs()
+	for _, mode := range []uint8{0, 3} {
+		pullPage := &msg.PullPageMsg{X64: &x64.PullPageMsg{
+			Header: &x64.RPCHeader{MsgType: msg.PULLPAGE, ClientId: TESTCLIENTID + "-1"},
+			Page:   fuzzPages(),
+		}}
+		codecs.pullPage.Elide(pullPage)
+		encoded := bytes.Join(codecs.pullPage.Encode(pullPage, testLayout(mode)), nil)
+		f.Add(encoded[RPCHEADERSIZE:], mode)
+	}
+	f.Fuzz(func(t *testing.T, data []byte, mode uint8) {
+		header := fuzzHeader(msg.PULLPAGE, data, mode)
+		decoded, err := codecs.pullPage.Decode(bytes.NewReader(data), header)
+		if err != nil {
+			return
+		}
+		encoded := bytes.Join(codecs.pullPage.Encode(decoded, header.Layout), nil)
+		header, reader := redecodeHeader(t, codecs, encoded, header.Layout)
+		redecoded, err := codecs.pullPage.Decode(reader, header)
+		if err != nil {
+			t.Fatalf("decoding an encoded pullpage: %v", err)
+		}
+		if !proto.Equal(decoded.X64, redecoded.X64) {
+			t.Fatalf("pullpage changed through encoding\n%v\n%v", decoded.X64, redecoded.X64)
+		}
+	})
+}
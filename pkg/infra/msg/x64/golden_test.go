@@ -0,0 +1,254 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/page"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+	x64cpu "github.com/sigrpc/sigrpcd/pkg/infra/cpu/x64"
+)
+
+/* go test ./pkg/infra/msg/x64 -run TestGolden -update */
+var update = flag.Bool("update", false, "rewrite the golden frames in testdata/golden")
+
+const GOLDENXSTATESIZE = 832
+
+/* a state in the form the codec decodes it, with every register distinct */
+func goldenCPU(xstateSize uint32) *x64.CPUState {
+	state := &x64.CPUState{
+		Gregs: make([]uint64, x64cpu.GREGCOUNT),
+		Fpregs: &x64.X64FPRegs{
+			Cwd:      0x37f,
+			Mxcsr:    0x1f80,
+			MxcrMask: 0xffff,
+			Rip:      0x7f0000001000,
+			St:       make([]*x64.X64FPXReg, x64cpu.STCOUNT),
+			Xmm:      make([]*x64.X64XMMReg, x64cpu.XMMCOUNT),
+			Reserved: make([]uint32, x64cpu.FPRESERVEDCOUNT),
+		},
+	}
+	for i := range state.Gregs {
+		state.Gregs[i] = uint64(i) << 32
+	}
+	for i := range state.Fpregs.St {
+		state.Fpregs.St[i] = &x64.X64FPXReg{
+			Significand: []uint32{uint32(i), 0, 0, 0x8000},
+			Exponent:    0x3fff,
+			Reserved:    make([]uint32, x64cpu.STRESERVED),
+		}
+	}
+	for i := range state.Fpregs.Xmm {
+		state.Fpregs.Xmm[i] = &x64.X64XMMReg{Element: []uint32{uint32(i), 1, 2, 3}}
+	}
+	if xstateSize > 0 {
+		state.Xstate = &x64.X64XState{
+			Size:     xstateSize,
+			XstateBv: x64cpu.XFEATURELEGACY | x64cpu.XFEATUREYMM,
+			YmmHi128: bytes.Repeat([]byte{0x11}, 256),
+		}
+	}
+	return state
+}
+
+func goldenHeader(msgType uint32) *x64.RPCHeader {
+	return &x64.RPCHeader{
+		MsgType:  msgType,
+		ClientId: TESTCLIENTID + "-1",
+	}
+}
+
+func goldenPages() []*x64.Page {
+	content := bytes.Repeat([]byte("golden page\n"), TESTPAGESIZE/12+1)[:TESTPAGESIZE]
+	return []*x64.Page{
+		{Address: 0x1000, RuntimeRevision: 2, ClientRevision: 1, Length: TESTPAGESIZE,
+			ContentSize: TESTPAGESIZE, Content: content},
+		{Address: 0x2000, RuntimeRevision: 2, Length: TESTPAGESIZE,
+			ContentSize: TESTPAGESIZE, Content: make([]byte, TESTPAGESIZE)},
+		{Address: 0x3000, RuntimeRevision: 2, Length: TESTPAGESIZE,
+			ContentSize: TESTPAGESIZE, Content: content},
+	}
+}
+
+/*
+ * a frame in the golden corpus. Decoding it gives the message back,
+ * or with elided pages a message that elides to the same frame.
+ */
+type goldenFrame struct {
+	name    string
+	layout  page.Layout
+	message proto.Message
+	elided  bool
+}
+
+func goldenFrames() []goldenFrame {
+	rangeContent := bytes.Repeat([]byte{0x42}, 128)
+	return []goldenFrame{
+		{name: "hello", message: &x64.HelloMsg{
+			Header: goldenHeader(msg.HELLO), Version: 1, Arch: 1, PageSize: TESTPAGESIZE,
+			XstateSize: GOLDENXSTATESIZE, Features: 0x1ff,
+		}},
+		{name: "hello_error", message: &x64.HelloMsg{
+			Header: &x64.RPCHeader{MsgType: msg.HELLO, Status: msg.STATUSFAILEDPRECONDITION,
+				ClientId: TESTCLIENTID + "-1", ErrorMessage: "unsupported version 9"},
+		}},
+		{name: "loadlib", message: &x64.LoadLibMsg{
+			Header: goldenHeader(msg.LOADLIB), LibraryName: "libgolden.so",
+			Addr2Sym: []*x64.Addr2Sym{{Address: 0x1120, Name: "golden_init"}, {Address: 0x1200, Name: "golden"}},
+		}},
+		{name: "invokefunc", layout: page.Layout{PageSize: TESTPAGESIZE}, message: &x64.InvokeFuncMsg{
+			Header: goldenHeader(msg.INVOKEFUNC), InvokefuncId: 7, RespId: 1,
+			Ctx:  &x64.UserContext{Cpu: goldenCPU(0), StackBottom: 0x7ffc00000000},
+			Page: goldenPages()[:1],
+		}},
+		{name: "invokefunc_xsave", layout: page.Layout{PageSize: TESTPAGESIZE, XStateSize: GOLDENXSTATESIZE},
+			message: &x64.InvokeFuncMsg{
+				Header: goldenHeader(msg.INVOKEFUNC), InvokefuncId: 8,
+				Ctx: &x64.UserContext{Cpu: goldenCPU(GOLDENXSTATESIZE), StackBottom: 0x7ffc00000000},
+			}},
+		{name: "invokefunc_elided", elided: true,
+			layout: page.Layout{PageSize: TESTPAGESIZE, Elision: true, DirtyRanges: true},
+			message: &x64.InvokeFuncMsg{
+				Header: goldenHeader(msg.INVOKEFUNC), InvokefuncId: 9, RespId: 2,
+				Ctx: &x64.UserContext{Cpu: goldenCPU(0), StackBottom: 0x7ffc00000000},
+				Page: append(goldenPages(), &x64.Page{
+					Address: 0x4000, Length: TESTPAGESIZE, ContentSize: TESTPAGESIZE,
+					Encoding: x64.PageEncoding_PAGE_ENCODING_DIRTY,
+					Dirty:    []*x64.DirtyRange{{Offset: 16, Content: []byte("dirty")}},
+				}),
+			}},
+		{name: "pullpage_request", layout: page.Layout{PageSize: TESTPAGESIZE}, message: &x64.PullPageMsg{
+			Header: goldenHeader(msg.PULLPAGE),
+			Page: []*x64.Page{
+				{Address: 0x1000, RuntimeRevision: 1, Length: TESTPAGESIZE},
+				{Address: 0x2000, RuntimeRevision: 1, Length: TESTPAGESIZE},
+			},
+		}},
+		{name: "pullpage_invocation", layout: page.Layout{PageSize: TESTPAGESIZE, Invocations: true},
+			message: &x64.PullPageMsg{
+				Header:       goldenHeader(msg.PULLPAGE),
+				InvokefuncId: 7,
+				Page:         []*x64.Page{{Address: 0x1000, RuntimeRevision: 1, Length: TESTPAGESIZE}},
+			}},
+		{name: "pullpage_ranges", layout: page.Layout{PageSize: TESTPAGESIZE, Ranges: true},
+			message: &x64.PullPageMsg{
+				Header: goldenHeader(msg.PULLPAGE),
+				Page: []*x64.Page{
+					{Address: 0x1040, RuntimeRevision: 3, Length: 128, ContentSize: 128, Content: rangeContent},
+				},
+			}},
+	}
+}
+
+func (c testCodecs) encode(message proto.Message, layout page.Layout, elide bool) []byte {
+	switch m := message.(type) {
+	case *x64.HelloMsg:
+		return c.hello.Encode(&msg.HelloMsg{X64: m})
+	case *x64.LoadLibMsg:
+		return c.loadLib.Encode(&msg.LoadLibMsg{X64: m})
+	case *x64.InvokeFuncMsg:
+		invokeFunc := &msg.InvokeFuncMsg{X64: m}
+		if elide {
+			c.invokeFunc.Elide(invokeFunc)
+		}
+		return bytes.Join(c.invokeFunc.Encode(invokeFunc, layout), nil)
+	case *x64.PullPageMsg:
+		pullPage := &msg.PullPageMsg{X64: m}
+		if elide {
+			c.pullPage.Elide(pullPage)
+		}
+		return bytes.Join(c.pullPage.Encode(pullPage, layout), nil)
+	}
+	return nil
+}
+
+func (c testCodecs) decode(reader io.Reader, header *msg.RPCHeader) (proto.Message, error) {
+	switch header.X64.MsgType {
+	case msg.HELLO:
+		m, err := c.hello.Decode(reader, header)
+		if err != nil {
+			return nil, err
+		}
+		return m.X64, nil
+	case msg.LOADLIB:
+		m, err := c.loadLib.Decode(reader, header)
+		if err != nil {
+			return nil, err
+		}
+		return m.X64, nil
+	case msg.INVOKEFUNC:
+		m, err := c.invokeFunc.Decode(reader, header)
+		if err != nil {
+			return nil, err
+		}
+		return m.X64, nil
+	default:
+		m, err := c.pullPage.Decode(reader, header)
+		if err != nil {
+			return nil, err
+		}
+		return m.X64, nil
+	}
+}
+
+/* the wire format is locked down by frames encoded once and kept in testdata */
+func TestGolden(t *testing.T) {
+	codecs := newTestCodecs()
+	for _, frame := range goldenFrames() {
+		t.Run(frame.name, func(t *testing.T) {
+			path := filepath.Join("testdata", "golden", frame.name+".bin")
+			encoded := codecs.encode(frame.message, frame.layout, frame.elided)
+			if encoded == nil {
+				t.Fatal("encoding failed")
+			}
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, encoded, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			golden, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(encoded, golden) {
+				t.Fatalf("encoded frame differs from %s\n%x\n%x", path, golden, encoded)
+			}
+
+			header, reader := redecodeHeader(t, codecs, golden, frame.layout)
+			decoded, err := codecs.decode(reader, header)
+			if err != nil {
+				t.Fatalf("decoding %s: %v", path, err)
+			}
+			if !frame.elided && !proto.Equal(frame.message, decoded) {
+				t.Fatalf("%s decodes to another message\n%v\n%v", path, frame.message, decoded)
+			}
+			if reencoded := codecs.encode(decoded, frame.layout, frame.elided); !bytes.Equal(reencoded, golden) {
+				t.Fatalf("%s changes through decoding\n%x\n%x", path, golden, reencoded)
+			}
+		})
+	}
+}
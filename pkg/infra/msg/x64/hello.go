@@ -0,0 +1,101 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"encoding/binary"
+	"io"
+	"unsafe"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	msgcodec "github.com/sigrpc/sigrpcd/pkg/domain/repository/msg"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+)
+
+type HelloCodec struct {
+	msgcodec.RPCHeader
+}
+
+func NewHelloCodec(rpcHeaderCodec msgcodec.RPCHeader) msgcodec.Hello {
+	return &HelloCodec{
+		RPCHeader: rpcHeaderCodec,
+	}
+}
+
+func (h *HelloCodec) Encode(hello *msg.HelloMsg) []byte {
+	header := msg.RPCHeader{
+		X64: hello.X64.Header,
+	}
+	if header.X64.Status != msg.STATUSOK {
+		return h.RPCHeader.EncodeError(&header)
+	}
+	bytePayload := make([]byte,
+		unsafe.Sizeof(hello.X64.Version)+
+			unsafe.Sizeof(hello.X64.Arch)+
+			unsafe.Sizeof(hello.X64.PageSize)+
+			unsafe.Sizeof(hello.X64.XstateSize)+
+			unsafe.Sizeof(hello.X64.Features))
+	offset := 0
+	binary.LittleEndian.PutUint32(bytePayload[offset:], hello.X64.Version)
+	offset += int(unsafe.Sizeof(hello.X64.Version))
+	binary.LittleEndian.PutUint32(bytePayload[offset:], hello.X64.Arch)
+	offset += int(unsafe.Sizeof(hello.X64.Arch))
+	binary.LittleEndian.PutUint32(bytePayload[offset:], hello.X64.PageSize)
+	offset += int(unsafe.Sizeof(hello.X64.PageSize))
+	binary.LittleEndian.PutUint32(bytePayload[offset:], hello.X64.XstateSize)
+	offset += int(unsafe.Sizeof(hello.X64.XstateSize))
+	binary.LittleEndian.PutUint64(bytePayload[offset:], hello.X64.Features)
+
+	header.X64.PayloadSize = uint64(len(bytePayload))
+	byteHello := h.RPCHeader.Encode(&header)
+	byteHello = append(byteHello, bytePayload...)
+	return byteHello
+}
+
+func (h *HelloCodec) Decode(reader io.Reader, header *msg.RPCHeader) (*msg.HelloMsg, error) {
+	hello := msg.HelloMsg{
+		X64: &x64.HelloMsg{
+			Header: header.X64,
+		},
+	}
+	if header.X64.Status != msg.STATUSOK {
+		if err := decodeError(reader, header); err != nil {
+			return nil, err
+		}
+		return &hello, nil
+	}
+	err := binary.Read(reader, binary.LittleEndian, &hello.X64.Version)
+	if err != nil {
+		return nil, err
+	}
+	err = binary.Read(reader, binary.LittleEndian, &hello.X64.Arch)
+	if err != nil {
+		return nil, err
+	}
+	err = binary.Read(reader, binary.LittleEndian, &hello.X64.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	/* reserved and always 0 before FEATUREXSAVE */
+	err = binary.Read(reader, binary.LittleEndian, &hello.X64.XstateSize)
+	if err != nil {
+		return nil, err
+	}
+	err = binary.Read(reader, binary.LittleEndian, &hello.X64.Features)
+	if err != nil {
+		return nil, err
+	}
+	return &hello, nil
+}
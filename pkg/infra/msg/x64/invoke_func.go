@@ -16,10 +16,13 @@ package x64
 
 import (
 	"encoding/binary"
+	"fmt"
 	"io"
+	"net"
 	"unsafe"
 
 	"github.com/sigrpc/sigrpcd/pkg/domain/model/cpu"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
 	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
 	"github.com/sigrpc/sigrpcd/pkg/domain/model/page"
 	"github.com/sigrpc/sigrpcd/pkg/domain/model/ucontext"
@@ -27,26 +30,36 @@ import (
 	pagecodec "github.com/sigrpc/sigrpcd/pkg/domain/repository/page"
 	ucontextcodec "github.com/sigrpc/sigrpcd/pkg/domain/repository/ucontext"
 	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+	x64page "github.com/sigrpc/sigrpcd/pkg/infra/page/x64"
 )
 
 type InvokeFuncCodec struct {
 	ucontextcodec.UserContext
 	pagecodec.Page
 	msgcodec.RPCHeader
+	limits limit.Limits
 }
 
 func NewInvokeFuncCodec(
 	userContextCodec ucontextcodec.UserContext,
 	pageCodec pagecodec.Page,
-	rpcHeaderCodec msgcodec.RPCHeader) msgcodec.InvokeFunc {
+	rpcHeaderCodec msgcodec.RPCHeader,
+	limits limit.Limits) msgcodec.InvokeFunc {
 	return &InvokeFuncCodec{
 		UserContext: userContextCodec,
 		Page:        pageCodec,
 		RPCHeader:   rpcHeaderCodec,
+		limits:      limits,
 	}
 }
 
-func (h *InvokeFuncCodec) Encode(invokeFunc *msg.InvokeFuncMsg) []byte {
+/* the header goes first, then the payload pieces written as they are with writev */
+func (h *InvokeFuncCodec) Encode(invokeFunc *msg.InvokeFuncMsg, layout page.Layout) net.Buffers {
+	if invokeFunc.X64.Header.Status != msg.STATUSOK {
+		return net.Buffers{h.RPCHeader.EncodeError(&msg.RPCHeader{
+			X64: invokeFunc.X64.Header,
+		})}
+	}
 	byteID := make([]byte, unsafe.Sizeof(invokeFunc.X64.InvokefuncId)<<1)
 	binary.LittleEndian.PutUint64(byteID, invokeFunc.X64.InvokefuncId)
 	binary.LittleEndian.PutUint64(byteID[unsafe.Sizeof(invokeFunc.X64.InvokefuncId):], invokeFunc.X64.RespId)
@@ -58,23 +71,32 @@ func (h *InvokeFuncCodec) Encode(invokeFunc *msg.InvokeFuncMsg) []byte {
 		CPU:         &x64CPU,
 		StackBottom: invokeFunc.X64.Ctx.StackBottom,
 	}
-	byteUserContext := h.UserContext.Encode(&ctx)
+	byteUserContext := h.UserContext.Encode(&ctx, layout.XStateSize)
 	bytePayload = append(bytePayload, byteUserContext...)
+	if layout.Shared != nil {
+		layout.Shared.Used = 0
+	}
+	byteInvokeFunc := net.Buffers{nil, bytePayload}
+	payloadSize := uint64(len(bytePayload))
 	for _, x64page := range invokeFunc.X64.Page {
 		page := page.Page{
 			X64: x64page,
 		}
-		bytePage := h.Page.Encode(&page)
-		bytePayload = append(bytePayload, bytePage...)
+		for _, bytePage := range h.Page.Encode(&page, layout) {
+			byteInvokeFunc = append(byteInvokeFunc, bytePage)
+			payloadSize += uint64(len(bytePage))
+		}
 	}
 
-	invokeFunc.X64.Header.PayloadSize = uint64(len(bytePayload))
+	invokeFunc.X64.Header.PayloadSize = payloadSize
 	header := msg.RPCHeader{
 		X64: invokeFunc.X64.Header,
 	}
 	byteHeader := h.RPCHeader.Encode(&header)
-	byteInvokeFunc := byteHeader
-	byteInvokeFunc = append(byteInvokeFunc, bytePayload...)
+	if byteHeader == nil {
+		return nil
+	}
+	byteInvokeFunc[0] = byteHeader
 
 	return byteInvokeFunc
 }
@@ -89,6 +111,12 @@ func (h *InvokeFuncCodec) Decode(reader io.Reader, header *msg.RPCHeader) (*msg.
 			Page:         nil,
 		},
 	}
+	if header.X64.Status != msg.STATUSOK {
+		if err := decodeError(reader, header); err != nil {
+			return nil, err
+		}
+		return &invokeFunc, nil
+	}
 	err := binary.Read(reader, binary.LittleEndian, &invokeFunc.X64.InvokefuncId)
 	if err != nil {
 		return nil, err
@@ -97,16 +125,47 @@ func (h *InvokeFuncCodec) Decode(reader io.Reader, header *msg.RPCHeader) (*msg.
 	if err != nil {
 		return nil, err
 	}
-	userContext := h.UserContext.Decode(reader)
+	userContext, err := h.UserContext.Decode(reader, header.Layout.XStateSize)
+	if err != nil {
+		return nil, err
+	}
 	invokeFunc.X64.Ctx.Cpu = userContext.CPU.X64
 	invokeFunc.X64.Ctx.StackBottom = userContext.StackBottom
+	if header.Layout.Shared != nil {
+		header.Layout.Shared.Used = 0
+	}
 	for {
-		p := h.Page.Decode(reader)
-		if p == nil {
+		p, err := h.Page.Decode(reader, header.Layout)
+		if err == io.EOF {
 			break
 		}
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(invokeFunc.X64.Page)) >= h.limits.MaxPageCount {
+			return nil, fmt.Errorf("page count exceeds limit %d", h.limits.MaxPageCount)
+		}
 		invokeFunc.X64.Page = append(invokeFunc.X64.Page, p.X64)
 	}
+	if err := x64page.ExpandPages(invokeFunc.X64.Page, h.limits.MaxPageContentSize, header.Layout.Budget); err != nil {
+		return nil, err
+	}
 
 	return &invokeFunc, nil
 }
+
+func (h *InvokeFuncCodec) Elide(invokeFunc *msg.InvokeFuncMsg) {
+	x64page.ElidePages(invokeFunc.X64.Page)
+}
+
+func (h *InvokeFuncCodec) Contents(invokeFunc *msg.InvokeFuncMsg) map[uint64][]byte {
+	return x64page.PageContents(invokeFunc.X64.Page)
+}
+
+func (h *InvokeFuncCodec) Diff(invokeFunc *msg.InvokeFuncMsg, bases map[uint64][]byte) {
+	x64page.DiffPages(invokeFunc.X64.Page, bases)
+}
+
+func (h *InvokeFuncCodec) Release(contents *page.Contents) {
+	x64page.ReleaseContents(contents)
+}
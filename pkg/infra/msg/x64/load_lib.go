@@ -17,10 +17,12 @@ package x64
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
 	"strings"
 	"unsafe"
 
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
 	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
 	msgcodec "github.com/sigrpc/sigrpcd/pkg/domain/repository/msg"
 	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
@@ -28,11 +30,13 @@ import (
 
 type LoadLibCodec struct {
 	msgcodec.RPCHeader
+	limits limit.Limits
 }
 
-func NewLoadLibCodec(rpcHeaderCodec msgcodec.RPCHeader) msgcodec.LoadLib {
+func NewLoadLibCodec(rpcHeaderCodec msgcodec.RPCHeader, limits limit.Limits) msgcodec.LoadLib {
 	return &LoadLibCodec{
 		RPCHeader: rpcHeaderCodec,
+		limits:    limits,
 	}
 }
 
@@ -41,6 +45,9 @@ func (h *LoadLibCodec) Encode(loadlib *msg.LoadLibMsg) []byte {
 	header := msg.RPCHeader{
 		X64: loadlib.X64.Header,
 	}
+	if header.X64.Status != msg.STATUSOK {
+		return h.RPCHeader.EncodeError(&header)
+	}
 	payloadSize := uintptr(len(loadlib.X64.LibraryName) + /* null byte */ 1)
 	for _, addr2sym := range loadlib.X64.GetAddr2Sym() {
 		payloadSize += unsafe.Sizeof(addr2sym.Address) +
@@ -56,15 +63,13 @@ func (h *LoadLibCodec) Encode(loadlib *msg.LoadLibMsg) []byte {
 	header.X64.PayloadSize = uint64(payloadSize)
 	byteHeader := h.RPCHeader.Encode(&header)
 
-	if payloadSize == 0 {
+	if byteHeader == nil || payloadSize == 0 {
 		return byteHeader
 	}
 	byteLoadLib := make([]byte, size)
 	copy(byteLoadLib, byteHeader)
 
-	offset := unsafe.Sizeof(header.X64.MsgType) +
-		unsafe.Sizeof(header.X64.Status) +
-		unsafe.Sizeof(header.X64.PayloadSize)
+	offset := uintptr(len(byteHeader))
 	/* encode library name */
 	copy(byteLoadLib[offset:], []byte(loadlib.X64.LibraryName))
 	offset += uintptr(len(loadlib.X64.LibraryName)) + /* null byte */ 1
@@ -86,22 +91,34 @@ func (h *LoadLibCodec) Decode(reader io.Reader, header *msg.RPCHeader) (*msg.Loa
 		X64: &x64.LoadLibMsg{},
 	}
 	loadlib.X64.Header = header.X64
+	if loadlib.X64.Header.Status != msg.STATUSOK {
+		if err := decodeError(reader, header); err != nil {
+			return nil, err
+		}
+		return &loadlib, nil
+	}
 	if loadlib.X64.Header.PayloadSize == 0 {
 		return &loadlib, nil
 	}
 	bytePayload := make([]byte, loadlib.X64.Header.PayloadSize)
-	size, err := reader.Read(bytePayload)
-	if err != nil || size != len(bytePayload) {
+	if _, err := io.ReadFull(reader, bytePayload); err != nil {
 		return nil, err
 	}
 	nullIndex := strings.Index(string(bytePayload[offset:]), "\x00")
 	if nullIndex < 0 {
 		return nil, errors.New("non null terminated string")
 	}
+	if uint64(nullIndex) > h.limits.MaxLibraryNameSize {
+		return nil, fmt.Errorf("library name length %d exceeds limit %d",
+			nullIndex, h.limits.MaxLibraryNameSize)
+	}
 	loadlib.X64.LibraryName = string(bytePayload[offset:nullIndex])
 	offset += len(loadlib.X64.LibraryName) + /* null byte */ 1
 	loadlib.X64.Addr2Sym = make([]*x64.Addr2Sym, 0, 10)
 	for offset < int(loadlib.X64.Header.PayloadSize) {
+		if offset+ /* address size */ 8 > len(bytePayload) {
+			return nil, errors.New("truncated addr2sym")
+		}
 		addr := binary.LittleEndian.Uint64(bytePayload[offset:])
 		offset += /* address size */ 8
 		nullIndex = strings.Index(string(bytePayload[offset:]), "\x00")
@@ -16,13 +16,14 @@ package x64
 
 import (
 	"github.com/google/uuid"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
 	x64cpu "github.com/sigrpc/sigrpcd/pkg/infra/cpu/x64"
 	x64page "github.com/sigrpc/sigrpcd/pkg/infra/page/x64"
 	x64uctx "github.com/sigrpc/sigrpcd/pkg/infra/ucontext/x64"
 	"github.com/sigrpc/sigrpcd/pkg/usecase"
 )
 
-func NewX64MsgCodec() (*usecase.MsgCodec, error) {
+func NewX64MsgCodec(limits limit.Limits) (*usecase.MsgCodec, error) {
 	clientID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -30,29 +31,35 @@ func NewX64MsgCodec() (*usecase.MsgCodec, error) {
 	msgCodec := usecase.MsgCodec{}
 	rpcHeaderCodec := NewRPCHeaderCodec(clientID.String())
 	cpuCodec := usecase.NewCPUCodec(x64cpu.NewCodec())
-	pageCodec := x64page.NewPageCodec()
+	pageCodec := x64page.NewPageCodec(limits)
 	uctxCodec := usecase.NewUserContextCodec(
 		x64uctx.NewCodec(&cpuCodec),
 	)
 	loadLibCodec := usecase.NewLoadLibCodec(
-		NewLoadLibCodec(rpcHeaderCodec),
+		NewLoadLibCodec(rpcHeaderCodec, limits),
 	)
 	invokeFuncCodec := usecase.NewInvokeFuncCodec(
 		NewInvokeFuncCodec(
 			&uctxCodec,
 			pageCodec,
 			rpcHeaderCodec,
+			limits,
 		),
 	)
 	pullPageCodec := usecase.NewPullPageCodec(
 		NewPullPageCodec(
 			pageCodec,
 			rpcHeaderCodec,
+			limits,
 		),
 	)
+	helloCodec := usecase.NewHelloCodec(
+		NewHelloCodec(rpcHeaderCodec),
+	)
 	msgCodec.RPCHeaderCodec = usecase.NewRPCHeaderCodec(rpcHeaderCodec)
 	msgCodec.LoadLibCodec = loadLibCodec
 	msgCodec.InvokeFuncCodec = invokeFuncCodec
 	msgCodec.PullPageCodec = pullPageCodec
+	msgCodec.HelloCodec = helloCodec
 	return &msgCodec, nil
 }
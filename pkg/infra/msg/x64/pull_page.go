@@ -15,44 +15,72 @@
 package x64
 
 import (
+	"encoding/binary"
+	"fmt"
 	"io"
+	"net"
 
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
 	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
 	"github.com/sigrpc/sigrpcd/pkg/domain/model/page"
 	msgcodec "github.com/sigrpc/sigrpcd/pkg/domain/repository/msg"
 	pagecodec "github.com/sigrpc/sigrpcd/pkg/domain/repository/page"
 	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+	x64page "github.com/sigrpc/sigrpcd/pkg/infra/page/x64"
 )
 
 type PullPageCodec struct {
 	pagecodec.Page
 	msgcodec.RPCHeader
+	limits limit.Limits
 }
 
-func NewPullPageCodec(pageCodec pagecodec.Page, rpcHeaderCodec msgcodec.RPCHeader) msgcodec.PullPage {
+func NewPullPageCodec(
+	pageCodec pagecodec.Page,
+	rpcHeaderCodec msgcodec.RPCHeader,
+	limits limit.Limits) msgcodec.PullPage {
 	return &PullPageCodec{
 		Page:      pageCodec,
 		RPCHeader: rpcHeaderCodec,
+		limits:    limits,
 	}
 }
 
-func (h *PullPageCodec) Encode(pullpage *msg.PullPageMsg) []byte {
-	var bytePayload []byte
+func (h *PullPageCodec) Encode(pullpage *msg.PullPageMsg, layout page.Layout) net.Buffers {
+	if pullpage.X64.Header.Status != msg.STATUSOK {
+		return net.Buffers{h.RPCHeader.EncodeError(&msg.RPCHeader{
+			X64: pullpage.X64.Header,
+		})}
+	}
 
+	if layout.Shared != nil {
+		layout.Shared.Used = 0
+	}
+	bytePullPage := net.Buffers{nil}
+	payloadSize := uint64(0)
+	if layout.Invocations {
+		byteID := binary.LittleEndian.AppendUint64(nil, pullpage.X64.InvokefuncId)
+		bytePullPage = append(bytePullPage, byteID)
+		payloadSize += uint64(len(byteID))
+	}
 	for _, x64page := range pullpage.X64.Page {
 		page := page.Page{
 			X64: x64page,
 		}
-		bytepage := h.Page.Encode(&page)
-		bytePayload = append(bytePayload, bytepage...)
+		for _, bytepage := range h.Page.Encode(&page, layout) {
+			bytePullPage = append(bytePullPage, bytepage)
+			payloadSize += uint64(len(bytepage))
+		}
 	}
-	pullpage.X64.Header.PayloadSize = uint64(len(bytePayload))
+	pullpage.X64.Header.PayloadSize = payloadSize
 	header := msg.RPCHeader{
 		X64: pullpage.X64.Header,
 	}
 	byteHeader := h.RPCHeader.Encode(&header)
-	bytePullPage := byteHeader
-	bytePullPage = append(bytePullPage, bytePayload...)
+	if byteHeader == nil {
+		return nil
+	}
+	bytePullPage[0] = byteHeader
 	return bytePullPage
 }
 
@@ -63,15 +91,42 @@ func (h *PullPageCodec) Decode(reader io.Reader, header *msg.RPCHeader) (*msg.Pu
 		},
 	}
 	pullPageMsg.X64.Header = header.X64
+	if pullPageMsg.X64.Header.Status != msg.STATUSOK {
+		if err := decodeError(reader, header); err != nil {
+			return nil, err
+		}
+		return &pullPageMsg, nil
+	}
 	if pullPageMsg.X64.Header.PayloadSize == 0 {
 		return &pullPageMsg, nil
 	}
+	if header.Layout.Invocations {
+		if err := binary.Read(reader, binary.LittleEndian, &pullPageMsg.X64.InvokefuncId); err != nil {
+			return nil, fmt.Errorf("invokefunc_id: %w", err)
+		}
+	}
+	if header.Layout.Shared != nil {
+		header.Layout.Shared.Used = 0
+	}
 	for {
-		p := h.Page.Decode(reader)
-		if p == nil {
+		p, err := h.Page.Decode(reader, header.Layout)
+		if err == io.EOF {
 			break
 		}
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(pullPageMsg.X64.Page)) >= h.limits.MaxPageCount {
+			return nil, fmt.Errorf("page count exceeds limit %d", h.limits.MaxPageCount)
+		}
 		pullPageMsg.X64.Page = append(pullPageMsg.X64.Page, p.X64)
 	}
+	if err := x64page.ExpandPages(pullPageMsg.X64.Page, h.limits.MaxPageContentSize, header.Layout.Budget); err != nil {
+		return nil, err
+	}
 	return &pullPageMsg, nil
 }
+
+func (h *PullPageCodec) Elide(pullpage *msg.PullPageMsg) {
+	x64page.ElidePages(pullpage.X64.Page)
+}
@@ -16,12 +16,14 @@ package x64
 
 import (
 	"encoding/binary"
+	"io"
 	"net"
 	"strconv"
 	"strings"
 	"unsafe"
 
 	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/peer"
 	msgcodec "github.com/sigrpc/sigrpcd/pkg/domain/repository/msg"
 	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
 )
@@ -63,6 +65,24 @@ func (h *RPCHeaderCodec) Encode(header *msg.RPCHeader) []byte {
 	return byteHeader
 }
 
+func (h *RPCHeaderCodec) EncodeError(header *msg.RPCHeader) []byte {
+	header.X64.PayloadSize = uint64(len(header.X64.ErrorMessage))
+	byteHeader := h.Encode(header)
+	if byteHeader == nil {
+		return nil
+	}
+	return append(byteHeader, []byte(header.X64.ErrorMessage)...)
+}
+
+func decodeError(reader io.Reader, header *msg.RPCHeader) error {
+	byteMessage := make([]byte, header.X64.PayloadSize)
+	if _, err := io.ReadFull(reader, byteMessage); err != nil {
+		return err
+	}
+	header.X64.ErrorMessage = string(byteMessage)
+	return nil
+}
+
 func (h *RPCHeaderCodec) Decode(conn net.Conn) (*msg.RPCHeader, error) {
 	header := msg.RPCHeader{
 		X64: &x64.RPCHeader{},
@@ -87,8 +107,26 @@ func (h *RPCHeaderCodec) Decode(conn net.Conn) (*msg.RPCHeader, error) {
 	header.X64.Status = binary.LittleEndian.Uint32(buf)
 	buf = buf[unsafe.Sizeof(header.X64.Status):]
 	pid = binary.LittleEndian.Uint32(buf)
+	header.Pid = pid
 	header.X64.ClientId = h.clientID + "-" + strconv.FormatUint(uint64(pid), 16)
 	buf = buf[unsafe.Sizeof(pid):]
 	header.X64.PayloadSize = binary.LittleEndian.Uint64(buf)
 	return &header, nil
 }
+
+/*
+ * client id of a peer with kernel-provided credentials is
+ * <uuid>-<host pid>-<pid namespace>-<start time>-<pid in namespace>
+ * so that reused or colliding pids never share a session.
+ * The last element is always the pid written back to the client.
+ */
+func (h *RPCHeaderCodec) BindPeer(header *msg.RPCHeader, peer *peer.Peer) {
+	if peer == nil {
+		return
+	}
+	header.X64.ClientId = h.clientID +
+		"-" + strconv.FormatUint(uint64(peer.Pid), 16) +
+		"-" + strconv.FormatUint(peer.PidNamespace, 16) +
+		"-" + strconv.FormatUint(peer.StartTime, 16) +
+		"-" + strconv.FormatUint(uint64(peer.ClientPid()), 16)
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+	peercredentials "github.com/sigrpc/sigrpcd/pkg/infra/peer"
+)
+
+func TestBindPeerOfTestProcess(t *testing.T) {
+	sock, err := net.Listen("unix", filepath.Join(t.TempDir(), "peer.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sock.Close()
+	client, err := net.Dial("unix", sock.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	conn, err := sock.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	peer, err := peercredentials.NewCredentials().Get(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codecs := newTestCodecs()
+	header := &msg.RPCHeader{X64: &x64.RPCHeader{ClientId: TESTCLIENTID + "-0"}}
+	codecs.rpcHeader.BindPeer(header, peer)
+	pid := strconv.FormatInt(int64(os.Getpid()), 16)
+	want := strings.Join([]string{
+		TESTCLIENTID,
+		pid,
+		strconv.FormatUint(peer.PidNamespace, 16),
+		strconv.FormatUint(peer.StartTime, 16),
+		pid,
+	}, "-")
+	if header.X64.ClientId != want {
+		t.Fatalf("client id is %q, want %q", header.X64.ClientId, want)
+	}
+
+	/* a peer without credentials keeps the client id of the header */
+	header.X64.ClientId = TESTCLIENTID + "-" + pid
+	codecs.rpcHeader.BindPeer(header, nil)
+	if header.X64.ClientId != TESTCLIENTID+"-"+pid {
+		t.Fatalf("client id is %q without a peer", header.X64.ClientId)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"encoding/binary"
+	"errors"
+
+	pagecodec "github.com/sigrpc/sigrpcd/pkg/domain/repository/page"
+)
+
+/*
+ * content XORed with the base, stored as a sequence of
+ * (unchanged length uvarint, changed length uvarint, changed bytes)
+ */
+type XORRLEDelta struct{}
+
+func NewXORRLEDelta() pagecodec.Delta {
+	return &XORRLEDelta{}
+}
+
+/* nil if the content is not of the size of the base */
+func (d *XORRLEDelta) Encode(base []byte, content []byte) []byte {
+	if len(base) != len(content) {
+		return nil
+	}
+	delta := make([]byte, 0)
+	pos := 0
+	for pos < len(content) {
+		start := pos
+		for pos < len(content) && base[pos] == content[pos] {
+			pos++
+		}
+		if pos == len(content) {
+			break
+		}
+		unchanged := pos - start
+		start = pos
+		for pos < len(content) && base[pos] != content[pos] {
+			pos++
+		}
+		delta = binary.AppendUvarint(delta, uint64(unchanged))
+		delta = binary.AppendUvarint(delta, uint64(pos-start))
+		for i := start; i < pos; i++ {
+			delta = append(delta, base[i]^content[i])
+		}
+	}
+	return delta
+}
+
+func (d *XORRLEDelta) Decode(base []byte, delta []byte) ([]byte, error) {
+	content := append([]byte(nil), base...)
+	pos := uint64(0)
+	for len(delta) > 0 {
+		unchanged, n := binary.Uvarint(delta)
+		if n <= 0 {
+			return nil, errors.New("malformed delta")
+		}
+		delta = delta[n:]
+		changed, n := binary.Uvarint(delta)
+		if n <= 0 {
+			return nil, errors.New("malformed delta")
+		}
+		delta = delta[n:]
+		if unchanged > uint64(len(content))-pos || changed > uint64(len(content))-pos-unchanged {
+			return nil, errors.New("delta exceeds the base")
+		}
+		if changed > uint64(len(delta)) {
+			return nil, errors.New("truncated delta")
+		}
+		pos += unchanged
+		for i := uint64(0); i < changed; i++ {
+			content[pos+i] ^= delta[i]
+		}
+		pos += changed
+		delta = delta[changed:]
+	}
+	return content, nil
+}
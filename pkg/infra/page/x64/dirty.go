@@ -0,0 +1,116 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"fmt"
+
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+)
+
+/* unchanged bytes shorter than this are sent within one range rather than splitting it */
+const DIRTYMERGEGAP = 16
+
+/* a count, then an offset and a size per range */
+const (
+	DIRTYCOUNTSIZE = 4
+	DIRTYRANGESIZE = 8
+)
+
+/* contents of pages sent in full, the bases dirty ranges are relative to */
+func PageContents(pages []*x64.Page) map[uint64][]byte {
+	contents := make(map[uint64][]byte, len(pages))
+	for _, page := range pages {
+		if page.Encoding == x64.PageEncoding_PAGE_ENCODING_FULL && len(page.Content) > 0 {
+			contents[page.Address] = page.Content
+		}
+	}
+	return contents
+}
+
+func dirtyRanges(base []byte, content []byte) []*x64.DirtyRange {
+	ranges := make([]*x64.DirtyRange, 0)
+	for i := 0; i < len(content); {
+		if content[i] == base[i] {
+			i++
+			continue
+		}
+		start, end := i, i+1
+		for j := end; j < len(content) && j-end < DIRTYMERGEGAP; j++ {
+			if content[j] != base[j] {
+				end = j + 1
+			}
+		}
+		ranges = append(ranges, &x64.DirtyRange{
+			Offset:  uint32(start),
+			Content: content[start:end],
+		})
+		i = end
+	}
+	return ranges
+}
+
+/* replaces full pages with the ranges changed from their bases when that is shorter */
+func DiffPages(pages []*x64.Page, bases map[uint64][]byte) {
+	for _, page := range pages {
+		if page.Encoding != x64.PageEncoding_PAGE_ENCODING_FULL || len(page.Content) == 0 {
+			continue
+		}
+		base, ok := bases[page.Address]
+		if !ok || len(base) != len(page.Content) {
+			continue
+		}
+		ranges := dirtyRanges(base, page.Content)
+		size := DIRTYCOUNTSIZE
+		for _, dirty := range ranges {
+			size += DIRTYRANGESIZE + len(dirty.Content)
+		}
+		if size >= len(page.Content) {
+			continue
+		}
+		page.ContentSize = uint32(len(page.Content))
+		page.Content = nil
+		page.Dirty = ranges
+		page.Encoding = x64.PageEncoding_PAGE_ENCODING_DIRTY
+	}
+}
+
+/* applies dirty ranges to copies of their bases, returns pages whose base is missing */
+func PatchPages(pages []*x64.Page, bases map[uint64][]byte) ([]*x64.Page, error) {
+	missing := make([]*x64.Page, 0)
+	for _, page := range pages {
+		if page.Encoding != x64.PageEncoding_PAGE_ENCODING_DIRTY {
+			continue
+		}
+		base, ok := bases[page.Address]
+		if !ok || len(base) != int(page.ContentSize) {
+			missing = append(missing, page)
+			continue
+		}
+		content := append([]byte(nil), base...)
+		for _, dirty := range page.Dirty {
+			end := uint64(dirty.Offset) + uint64(len(dirty.Content))
+			if end > uint64(len(content)) {
+				return nil, fmt.Errorf("page %#x has a dirty range %d-%d beyond its size %d",
+					page.Address, dirty.Offset, end, len(content))
+			}
+			copy(content[dirty.Offset:], dirty.Content)
+		}
+		page.Content = content
+		page.Dirty = nil
+		page.Encoding = x64.PageEncoding_PAGE_ENCODING_FULL
+	}
+	return missing, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+)
+
+func isZero(content []byte) bool {
+	for _, b := range content {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+/* replaces zero pages and pages repeating an earlier one with their short forms */
+func ElidePages(pages []*x64.Page) {
+	seen := make(map[[sha256.Size]byte]bool)
+	for _, page := range pages {
+		if page.Encoding != x64.PageEncoding_PAGE_ENCODING_FULL || len(page.Content) == 0 {
+			continue
+		}
+		if isZero(page.Content) {
+			page.ContentSize = uint32(len(page.Content))
+			page.Content = nil
+			page.Encoding = x64.PageEncoding_PAGE_ENCODING_ZERO
+			continue
+		}
+		hash := sha256.Sum256(page.Content)
+		if !seen[hash] {
+			seen[hash] = true
+			continue
+		}
+		page.ContentSize = uint32(len(page.Content))
+		page.Content = nil
+		page.ContentHash = hash[:]
+		page.Encoding = x64.PageEncoding_PAGE_ENCODING_DUPLICATE
+	}
+}
+
+/*
+ * restores the content of elided pages, the reverse of ElidePages.
+ * Restored contents are charged to the budget, duplicates too
+ * as they are copied apart once sent on.
+ */
+func ExpandPages(pages []*x64.Page, maxContentSize uint32, budget *limit.Budget) error {
+	var contents map[[sha256.Size]byte][]byte
+	for _, page := range pages {
+		switch page.Encoding {
+		case x64.PageEncoding_PAGE_ENCODING_ZERO:
+			if page.ContentSize > maxContentSize {
+				return fmt.Errorf("page content size %d exceeds limit %d", page.ContentSize, maxContentSize)
+			}
+			if err := budget.Reserve(uint64(page.ContentSize)); err != nil {
+				return err
+			}
+			page.Content = make([]byte, page.ContentSize)
+		case x64.PageEncoding_PAGE_ENCODING_DUPLICATE:
+			if len(page.ContentHash) != sha256.Size {
+				return fmt.Errorf("page %#x has an invalid content hash", page.Address)
+			}
+			if contents == nil {
+				contents = make(map[[sha256.Size]byte][]byte)
+				for _, full := range pages {
+					if full.Encoding == x64.PageEncoding_PAGE_ENCODING_FULL && len(full.Content) > 0 {
+						contents[sha256.Sum256(full.Content)] = full.Content
+					}
+				}
+			}
+			content, ok := contents[[sha256.Size]byte(page.ContentHash)]
+			if !ok {
+				return fmt.Errorf("page %#x duplicates a page missing in the message", page.Address)
+			}
+			if err := budget.Reserve(uint64(len(content))); err != nil {
+				return err
+			}
+			page.Content = content
+		default:
+			continue
+		}
+		page.ContentSize = uint32(len(page.Content))
+		page.ContentHash = nil
+		page.Encoding = x64.PageEncoding_PAGE_ENCODING_FULL
+	}
+	return nil
+}
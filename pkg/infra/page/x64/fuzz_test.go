@@ -0,0 +1,172 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
+	pagemodel "github.com/sigrpc/sigrpcd/pkg/domain/model/page"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+)
+
+const (
+	/* address, revisions and content size of a page without ranges */
+	PAGEHEADERSIZE = 28
+	FUZZPAGESIZE   = 4096
+	FUZZREGIONSIZE = 0x10000
+	FUZZBUDGET     = 0x400000
+)
+
+/* bit 0 selects ranges, bit 1 shared memory, bit 2 leaves elision and dirty ranges out and bit 3 adds mappings */
+func fuzzLayout(mode uint8) pagemodel.Layout {
+	layout := pagemodel.Layout{
+		PageSize:    FUZZPAGESIZE,
+		Ranges:      mode&1 != 0,
+		Elision:     mode&4 == 0,
+		DirtyRanges: mode&4 == 0,
+		Mappings:    mode&8 != 0,
+		Budget:      limit.NewBudget(FUZZBUDGET, nil),
+	}
+	if mode&2 != 0 {
+		layout.Shared = &pagemodel.Shared{
+			Region: make([]byte, FUZZREGIONSIZE),
+		}
+	}
+	return layout
+}
+
+func fuzzPages() []*x64.Page {
+	content := bytes.Repeat([]byte{0xa5}, FUZZPAGESIZE)
+	return []*x64.Page{
+		{Address: 0x1000, Content: content},
+		{Address: 0x2000, ContentSize: FUZZPAGESIZE, Encoding: x64.PageEncoding_PAGE_ENCODING_ZERO},
+		{Address: 0x3000, ContentSize: FUZZPAGESIZE, Encoding: x64.PageEncoding_PAGE_ENCODING_DUPLICATE,
+			ContentHash: bytes.Repeat([]byte{1}, pagemodel.HASHSIZE)},
+		{Address: 0x4000, ContentSize: FUZZPAGESIZE, Encoding: x64.PageEncoding_PAGE_ENCODING_DIRTY,
+			Dirty: []*x64.DirtyRange{{Offset: 8, Content: []byte{1, 2, 3}}}},
+	}
+}
+
+func joined(buffers [][]byte) []byte {
+	return bytes.Join(buffers, nil)
+}
+
+func FuzzPageDecode(f *testing.F) {
+	codec := NewPageCodec(limit.NewDefaultLimits())
+	for mode := uint8(0); mode < 16; mode++ {
+		for _, page := range fuzzPages() {
+			layout := fuzzLayout(mode)
+			f.Add(joined(codec.Encode(&pagemodel.Page{X64: page}, layout)), mode)
+		}
+	}
+	f.Fuzz(func(t *testing.T, data []byte, mode uint8) {
+		layout := fuzzLayout(mode)
+		decoded, err := codec.Decode(bytes.NewReader(data), layout)
+		if err != nil {
+			return
+		}
+		if layout.Budget.Used() > FUZZBUDGET {
+			t.Fatalf("decoding used %d bytes of a %d byte budget", layout.Budget.Used(), FUZZBUDGET)
+		}
+		if layout.Shared != nil {
+			layout.Shared.Used = 0
+		}
+		encoded := joined(codec.Encode(decoded, layout))
+		layout.Budget = limit.NewBudget(FUZZBUDGET, nil)
+		if layout.Shared != nil {
+			layout.Shared.Used = 0
+		}
+		reader := bytes.NewReader(encoded)
+		redecoded, err := codec.Decode(reader, layout)
+		if err != nil {
+			t.Fatalf("decoding an encoded page: %v", err)
+		}
+		if reader.Len() != 0 {
+			t.Fatalf("%d bytes left after an encoded page", reader.Len())
+		}
+		if !proto.Equal(decoded.X64, redecoded.X64) {
+			t.Fatalf("page changed through encoding\n%v\n%v", decoded.X64, redecoded.X64)
+		}
+	})
+}
+
+func FuzzPageDecodeEOF(f *testing.F) {
+	codec := NewPageCodec(limit.NewDefaultLimits())
+	f.Add([]byte{}, uint8(0))
+	f.Fuzz(func(t *testing.T, data []byte, mode uint8) {
+		/* every byte of data is consumed or an error is returned, a clean io.EOF only between pages */
+		reader := bytes.NewReader(data)
+		layout := fuzzLayout(mode)
+		for {
+			_, err := codec.Decode(reader, layout)
+			if err == io.EOF && reader.Len() != 0 {
+				t.Fatalf("io.EOF with %d bytes left", reader.Len())
+			}
+			if err != nil {
+				return
+			}
+		}
+	})
+}
+
+/* the stub side of XORRLEDelta */
+func FuzzXORRLEDelta(f *testing.F) {
+	delta := NewXORRLEDelta()
+	f.Add([]byte("base content"), []byte("bake contest"))
+	f.Fuzz(func(t *testing.T, base []byte, content []byte) {
+		size := min(len(base), len(content))
+		base, content = base[:size], content[:size]
+		decoded, err := delta.Decode(base, delta.Encode(base, content))
+		if err != nil {
+			t.Fatalf("decoding an encoded delta: %v", err)
+		}
+		if !bytes.Equal(decoded, content) {
+			t.Fatalf("content changed through the delta\n%x\n%x", content, decoded)
+		}
+	})
+}
+
+func FuzzXORRLEDeltaDecode(f *testing.F) {
+	delta := NewXORRLEDelta()
+	f.Add([]byte("base content"), []byte{2, 1, 0x0b})
+	f.Fuzz(func(t *testing.T, base []byte, data []byte) {
+		decoded, err := delta.Decode(base, data)
+		if err == nil && len(decoded) != len(base) {
+			t.Fatalf("decoded %d bytes from a %d byte base", len(decoded), len(base))
+		}
+	})
+}
+
+func FuzzDirtyPages(f *testing.F) {
+	f.Add([]byte("base content of a page"), []byte("bake contest of a cage"))
+	f.Fuzz(func(t *testing.T, base []byte, content []byte) {
+		size := min(len(base), len(content))
+		base, content = base[:size], content[:size]
+		pages := []*x64.Page{{Address: 0x1000, Content: append([]byte(nil), content...)}}
+		DiffPages(pages, map[uint64][]byte{0x1000: base})
+		missing, err := PatchPages(pages, map[uint64][]byte{0x1000: base})
+		if err != nil || len(missing) != 0 {
+			t.Fatalf("patching diffed pages: %v, %d missing", err, len(missing))
+		}
+		if !bytes.Equal(pages[0].Content, content) {
+			t.Fatalf("content changed through dirty ranges\n%x\n%x", content, pages[0].Content)
+		}
+	})
+}
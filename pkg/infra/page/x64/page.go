@@ -16,29 +16,40 @@ package x64
 
 import (
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
+	"net"
+	"unicode/utf8"
 	"unsafe"
 
-	"github.com/sigrpc/sigrpcd/pkg/domain/model/page"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/mapping"
+	pagemodel "github.com/sigrpc/sigrpcd/pkg/domain/model/page"
 	pagecodec "github.com/sigrpc/sigrpcd/pkg/domain/repository/page"
 	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
 )
 
-type PageCodec struct{}
+type PageCodec struct {
+	limits limit.Limits
+}
 
-func NewPageCodec() pagecodec.Page {
-	return &PageCodec{}
+func NewPageCodec(limits limit.Limits) pagecodec.Page {
+	return &PageCodec{
+		limits: limits,
+	}
 }
 
-func (h *PageCodec) Encode(page *page.Page) []byte {
+/* contents are referenced rather than copied, so they must stay untouched until written */
+func (h *PageCodec) Encode(page *pagemodel.Page, layout pagemodel.Layout) net.Buffers {
 	propertySize := unsafe.Sizeof(page.X64.Address) +
 		unsafe.Sizeof(page.X64.RuntimeRevision) +
 		unsafe.Sizeof(page.X64.ClientRevision) +
 		unsafe.Sizeof(page.X64.ContentSize)
-	bytePage := make(
-		[]byte,
-		propertySize,
-		propertySize+uintptr(page.X64.ContentSize))
+	if layout.Ranges {
+		propertySize += unsafe.Sizeof(page.X64.Length)
+	}
+	bytePage := make([]byte, propertySize)
 	offset := 0
 	binary.LittleEndian.PutUint64(bytePage[offset:], page.X64.Address)
 	offset += int(unsafe.Sizeof(page.X64.Address))
@@ -46,42 +57,315 @@ func (h *PageCodec) Encode(page *page.Page) []byte {
 	offset += int(unsafe.Sizeof(page.X64.RuntimeRevision))
 	binary.LittleEndian.PutUint64(bytePage[offset:], page.X64.ClientRevision)
 	offset += int(unsafe.Sizeof(page.X64.ClientRevision))
-	binary.LittleEndian.PutUint32(bytePage[offset:], page.X64.ContentSize)
-	if len(page.X64.Content) > 0 {
-		bytePage = append(bytePage, page.X64.Content...)
+	if layout.Ranges {
+		length := page.X64.Length
+		if length == 0 {
+			length = layout.PageSize
+		}
+		binary.LittleEndian.PutUint64(bytePage[offset:], length)
+		offset += int(unsafe.Sizeof(page.X64.Length))
+	}
+	if layout.Mappings {
+		bytePage = encodeMapping(bytePage, page.X64.Mapping)
+	}
+	switch page.X64.Encoding {
+	case x64.PageEncoding_PAGE_ENCODING_ZERO:
+		binary.LittleEndian.PutUint32(bytePage[offset:], page.X64.ContentSize|pagemodel.CONTENTZERO)
+	case x64.PageEncoding_PAGE_ENCODING_DUPLICATE:
+		binary.LittleEndian.PutUint32(bytePage[offset:], page.X64.ContentSize|pagemodel.CONTENTDUPLICATE)
+		bytePage = append(bytePage, page.X64.ContentHash...)
+	case x64.PageEncoding_PAGE_ENCODING_DIRTY:
+		binary.LittleEndian.PutUint32(bytePage[offset:], page.X64.ContentSize|pagemodel.CONTENTDIRTY)
+		bytePage = binary.LittleEndian.AppendUint32(bytePage, uint32(len(page.X64.Dirty)))
+		for _, dirty := range page.X64.Dirty {
+			bytePage = binary.LittleEndian.AppendUint32(bytePage, dirty.Offset)
+			bytePage = binary.LittleEndian.AppendUint32(bytePage, uint32(len(dirty.Content)))
+			bytePage = append(bytePage, dirty.Content...)
+		}
+	default:
+		/* content_size always describes the bytes that follow */
+		binary.LittleEndian.PutUint32(bytePage[offset:], uint32(len(page.X64.Content)))
+		if layout.Shared != nil {
+			return h.encodeShared(bytePage, page.X64.Content, layout.Shared)
+		}
+		if len(page.X64.Content) > 0 {
+			return net.Buffers{bytePage, page.X64.Content}
+		}
 	}
 
-	return bytePage
+	return net.Buffers{bytePage}
 }
 
-func (h *PageCodec) Decode(reader io.Reader) *page.Page {
-	page := page.Page{
+func (h *PageCodec) Decode(reader io.Reader, layout pagemodel.Layout) (*pagemodel.Page, error) {
+	page := pagemodel.Page{
 		X64: &x64.Page{},
 	}
-	err := binary.Read(reader, binary.LittleEndian, &page.X64.Address)
-	if err != nil {
-		return nil
+	propertySize := unsafe.Sizeof(page.X64.Address) +
+		unsafe.Sizeof(page.X64.RuntimeRevision) +
+		unsafe.Sizeof(page.X64.ClientRevision) +
+		unsafe.Sizeof(page.X64.ContentSize)
+	if layout.Ranges {
+		propertySize += unsafe.Sizeof(page.X64.Length)
 	}
-	err = binary.Read(reader, binary.LittleEndian, &page.X64.RuntimeRevision)
-	if err != nil {
-		return nil
+	buf := make([]byte, propertySize)
+	/* io.EOF only when no byte of the next page is left */
+	if _, err := io.ReadFull(reader, buf); err != nil {
+		return nil, err
 	}
-	err = binary.Read(reader, binary.LittleEndian, &page.X64.ClientRevision)
-	if err != nil {
-		return nil
+	if err := layout.Budget.Reserve(uint64(unsafe.Sizeof(*page.X64))); err != nil {
+		return nil, err
 	}
-	err = binary.Read(reader, binary.LittleEndian, &page.X64.ContentSize)
-	if err != nil {
-		return nil
+	page.X64.Address = binary.LittleEndian.Uint64(buf)
+	buf = buf[unsafe.Sizeof(page.X64.Address):]
+	page.X64.RuntimeRevision = binary.LittleEndian.Uint64(buf)
+	buf = buf[unsafe.Sizeof(page.X64.RuntimeRevision):]
+	page.X64.ClientRevision = binary.LittleEndian.Uint64(buf)
+	buf = buf[unsafe.Sizeof(page.X64.ClientRevision):]
+	page.X64.Length = layout.PageSize
+	if layout.Ranges {
+		page.X64.Length = binary.LittleEndian.Uint64(buf)
+		buf = buf[unsafe.Sizeof(page.X64.Length):]
 	}
-	content := make([]byte, page.X64.ContentSize)
-	err = binary.Read(reader, binary.LittleEndian, &content)
-	if err == io.EOF {
-		return &page
+	contentSize := binary.LittleEndian.Uint32(buf)
+	if layout.Mappings {
+		mapping, err := decodeMapping(reader, layout.Budget)
+		if err != nil {
+			return nil, fmt.Errorf("page %#x mapping: %w", page.X64.Address, err)
+		}
+		page.X64.Mapping = mapping
+	}
+	page.X64.ContentSize = contentSize & pagemodel.CONTENTSIZEMASK
+	if page.X64.ContentSize > h.limits.MaxPageContentSize {
+		return nil, fmt.Errorf("page content size %d exceeds limit %d",
+			page.X64.ContentSize, h.limits.MaxPageContentSize)
+	}
+	if layout.Ranges {
+		if err := h.checkRange(page.X64); err != nil {
+			return nil, err
+		}
+	}
+	flags := contentSize &^ pagemodel.CONTENTSIZEMASK
+	if flags == pagemodel.CONTENTDIRTY && !layout.DirtyRanges ||
+		flags != pagemodel.CONTENTDIRTY && flags != 0 && !layout.Elision {
+		return nil, fmt.Errorf("page %#x has content size flags %#x that were not negotiated", page.X64.Address, flags)
+	}
+	switch flags {
+	case 0:
+	case pagemodel.CONTENTZERO:
+		page.X64.Encoding = x64.PageEncoding_PAGE_ENCODING_ZERO
+		return &page, nil
+	case pagemodel.CONTENTDUPLICATE:
+		page.X64.Encoding = x64.PageEncoding_PAGE_ENCODING_DUPLICATE
+		if err := layout.Budget.Reserve(pagemodel.HASHSIZE); err != nil {
+			return nil, err
+		}
+		page.X64.ContentHash = make([]byte, pagemodel.HASHSIZE)
+		if _, err := io.ReadFull(reader, page.X64.ContentHash); err != nil {
+			return nil, fmt.Errorf("page %#x content hash: %w", page.X64.Address, truncated(err))
+		}
+		return &page, nil
+	case pagemodel.CONTENTDIRTY:
+		page.X64.Encoding = x64.PageEncoding_PAGE_ENCODING_DIRTY
+		if err := h.decodeDirty(reader, page.X64, layout.Budget); err != nil {
+			return nil, err
+		}
+		return &page, nil
+	default:
+		return nil, fmt.Errorf("invalid page content size %#x", contentSize)
+	}
+	if layout.Shared != nil {
+		content, err := h.decodeShared(reader, page.X64, layout)
+		if err != nil {
+			return nil, err
+		}
+		page.X64.Content = content
+		return &page, nil
 	}
-	if err != nil {
-		return nil
+	if err := layout.Budget.Reserve(uint64(page.X64.ContentSize)); err != nil {
+		return nil, err
+	}
+	content := newContent(layout.Contents, page.X64.ContentSize)
+	if _, err := io.ReadFull(reader, content); err != nil {
+		return nil, fmt.Errorf("page %#x content: %w", page.X64.Address, truncated(err))
 	}
 	page.X64.Content = content
-	return &page
+	return &page, nil
+}
+
+func encodeMapping(bytePage []byte, mapping *x64.Mapping) []byte {
+	if mapping == nil || mapping.Kind == x64.MappingKind_MAPPING_KIND_UNKNOWN {
+		return binary.LittleEndian.AppendUint32(bytePage, uint32(x64.MappingKind_MAPPING_KIND_UNKNOWN))
+	}
+	bytePage = binary.LittleEndian.AppendUint32(bytePage, uint32(mapping.Kind))
+	protection := mapping.Protection
+	if mapping.Shared {
+		protection |= pagemodel.MAPPINGSHARED
+	}
+	bytePage = binary.LittleEndian.AppendUint32(bytePage, protection)
+	bytePage = binary.LittleEndian.AppendUint64(bytePage, mapping.Offset)
+	bytePage = binary.LittleEndian.AppendUint32(bytePage, uint32(len(mapping.Path)))
+	return append(bytePage, mapping.Path...)
+}
+
+/* nil if the client left the mapping to the daemon */
+func decodeMapping(reader io.Reader, budget *limit.Budget) (*x64.Mapping, error) {
+	var kind uint32
+	if err := binary.Read(reader, binary.LittleEndian, &kind); err != nil {
+		return nil, truncated(err)
+	}
+	if kind == uint32(x64.MappingKind_MAPPING_KIND_UNKNOWN) {
+		return nil, nil
+	}
+	if kind > uint32(x64.MappingKind_MAPPING_KIND_FILE) {
+		return nil, fmt.Errorf("unknown mapping kind %d", kind)
+	}
+	buf := make([]byte, 16)
+	if _, err := io.ReadFull(reader, buf); err != nil {
+		return nil, truncated(err)
+	}
+	protection := binary.LittleEndian.Uint32(buf)
+	pathSize := binary.LittleEndian.Uint32(buf[12:])
+	if protection&^(pagemodel.MAPPINGSHARED|mapping.PROTREAD|mapping.PROTWRITE|mapping.PROTEXEC) != 0 {
+		return nil, fmt.Errorf("unknown protection %#x", protection)
+	}
+	if pathSize > pagemodel.MAXMAPPINGPATHSIZE ||
+		pathSize > 0 && kind != uint32(x64.MappingKind_MAPPING_KIND_FILE) {
+		return nil, fmt.Errorf("invalid path size %d of a mapping of kind %d", pathSize, kind)
+	}
+	if err := budget.Reserve(uint64(unsafe.Sizeof(x64.Mapping{})) + uint64(pathSize)); err != nil {
+		return nil, err
+	}
+	path := make([]byte, pathSize)
+	if _, err := io.ReadFull(reader, path); err != nil {
+		return nil, truncated(err)
+	}
+	/* sent on to the stub as a protobuf string */
+	if !utf8.Valid(path) {
+		return nil, errors.New("mapping path is not UTF-8")
+	}
+	return &x64.Mapping{
+		Protection: protection &^ pagemodel.MAPPINGSHARED,
+		Shared:     protection&pagemodel.MAPPINGSHARED != 0,
+		Kind:       x64.MappingKind(kind),
+		Path:       string(path),
+		Offset:     binary.LittleEndian.Uint64(buf[4:]),
+	}, nil
+}
+
+/* input running out within a page is unexpected, any other error is returned as it is */
+func truncated(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+/* the content goes to the shared region and only its offset to the socket, inline if the region is full */
+func (h *PageCodec) encodeShared(bytePage []byte, content []byte, shared *pagemodel.Shared) net.Buffers {
+	size := uint64(len(content))
+	if size == 0 || shared.Used+size > uint64(len(shared.Region)) {
+		bytePage = binary.LittleEndian.AppendUint64(bytePage, pagemodel.SHAREDINLINE)
+		return net.Buffers{bytePage, content}
+	}
+	bytePage = binary.LittleEndian.AppendUint64(bytePage, shared.Used)
+	copy(shared.Region[shared.Used:], content)
+	shared.Used += size
+	return net.Buffers{bytePage}
+}
+
+/* contents follow one another in the region, so none of them is copied out twice */
+func (h *PageCodec) decodeShared(reader io.Reader, page *x64.Page, layout pagemodel.Layout) ([]byte, error) {
+	shared := layout.Shared
+	var offset uint64
+	if err := binary.Read(reader, binary.LittleEndian, &offset); err != nil {
+		return nil, fmt.Errorf("page %#x shared offset: %w", page.Address, truncated(err))
+	}
+	if err := layout.Budget.Reserve(uint64(page.ContentSize)); err != nil {
+		return nil, err
+	}
+	content := newContent(layout.Contents, page.ContentSize)
+	if offset == pagemodel.SHAREDINLINE {
+		if _, err := io.ReadFull(reader, content); err != nil {
+			return nil, fmt.Errorf("page %#x content: %w", page.Address, truncated(err))
+		}
+		return content, nil
+	}
+	size := uint64(page.ContentSize)
+	if offset > uint64(len(shared.Region)) || size > uint64(len(shared.Region))-offset {
+		return nil, fmt.Errorf("page %#x has shared content %d+%d beyond the region size %d",
+			page.Address, offset, size, len(shared.Region))
+	}
+	if offset < shared.Used {
+		return nil, fmt.Errorf("page %#x has shared content at %d before the end %d of the previous one",
+			page.Address, offset, shared.Used)
+	}
+	/* copied out, the client may reuse the region as soon as the message is decoded */
+	copy(content, shared.Region[offset:offset+size])
+	shared.Used = offset + size
+	return content, nil
+}
+
+func (h *PageCodec) decodeDirty(reader io.Reader, page *x64.Page, budget *limit.Budget) error {
+	var count uint32
+	if err := binary.Read(reader, binary.LittleEndian, &count); err != nil {
+		return fmt.Errorf("page %#x dirty range count: %w", page.Address, truncated(err))
+	}
+	/* ranges never overlap, so there are at most as many ranges and bytes as the page has */
+	if count > page.ContentSize {
+		return fmt.Errorf("page %#x has %d dirty ranges in %d bytes", page.Address, count, page.ContentSize)
+	}
+	/* the count comes from the wire, the slice is charged before it is allocated */
+	if err := budget.Reserve(uint64(count) * uint64(unsafe.Sizeof(&x64.DirtyRange{}))); err != nil {
+		return err
+	}
+	page.Dirty = make([]*x64.DirtyRange, 0, count)
+	total := uint64(0)
+	buf := make([]byte, 8)
+	for i := uint32(0); i < count; i++ {
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return fmt.Errorf("page %#x dirty range: %w", page.Address, truncated(err))
+		}
+		offset := binary.LittleEndian.Uint32(buf)
+		size := binary.LittleEndian.Uint32(buf[4:])
+		if uint64(offset)+uint64(size) > uint64(page.ContentSize) {
+			return fmt.Errorf("page %#x has a dirty range %d+%d beyond its size %d",
+				page.Address, offset, size, page.ContentSize)
+		}
+		total += uint64(size)
+		if total > uint64(page.ContentSize) {
+			return fmt.Errorf("page %#x has more dirty bytes than its size %d", page.Address, page.ContentSize)
+		}
+		/* empty ranges still cost their structure */
+		if err := budget.Reserve(uint64(unsafe.Sizeof(x64.DirtyRange{})) + uint64(size)); err != nil {
+			return err
+		}
+		content := make([]byte, size)
+		if _, err := io.ReadFull(reader, content); err != nil {
+			return fmt.Errorf("page %#x dirty range content: %w", page.Address, truncated(err))
+		}
+		page.Dirty = append(page.Dirty, &x64.DirtyRange{
+			Offset:  offset,
+			Content: content,
+		})
+	}
+	return nil
+}
+
+/* a range is aligned, fits in a page content and is either empty or wholly sent */
+func (h *PageCodec) checkRange(page *x64.Page) error {
+	if page.Length == 0 || page.Length > uint64(h.limits.MaxPageContentSize) {
+		return fmt.Errorf("page %#x has an invalid length %d", page.Address, page.Length)
+	}
+	if page.Address%pagemodel.RANGEALIGNMENT != 0 || page.Length%pagemodel.RANGEALIGNMENT != 0 {
+		return fmt.Errorf("page %#x+%#x is not aligned to %d bytes",
+			page.Address, page.Length, pagemodel.RANGEALIGNMENT)
+	}
+	if page.Address+page.Length < page.Address {
+		return fmt.Errorf("page %#x+%#x overflows the address space", page.Address, page.Length)
+	}
+	if page.ContentSize != 0 && uint64(page.ContentSize) != page.Length {
+		return fmt.Errorf("page %#x has content size %d other than its length %d",
+			page.Address, page.ContentSize, page.Length)
+	}
+	return nil
 }
@@ -0,0 +1,299 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/mapping"
+	pagemodel "github.com/sigrpc/sigrpcd/pkg/domain/model/page"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+)
+
+func testPages(length uint64) []*x64.Page {
+	content := bytes.Repeat([]byte{0xa5, 0x5a}, int(length)/2)
+	return []*x64.Page{
+		{Address: 0x1000, RuntimeRevision: 2, ClientRevision: 1, Length: length,
+			ContentSize: uint32(length), Content: content,
+			Mapping: &x64.Mapping{Protection: mapping.PROTREAD | mapping.PROTEXEC,
+				Kind: x64.MappingKind_MAPPING_KIND_FILE, Path: "/usr/lib/libc.so.6", Offset: 0x1000}},
+		{Address: 0x2000, RuntimeRevision: 3, Length: length, Content: []byte{},
+			Mapping: &x64.Mapping{Protection: mapping.PROTREAD | mapping.PROTWRITE, Shared: true,
+				Kind: x64.MappingKind_MAPPING_KIND_STACK}},
+		{Address: 0x3000, Length: length, ContentSize: uint32(length),
+			Encoding: x64.PageEncoding_PAGE_ENCODING_ZERO},
+		{Address: 0x4000, Length: length, ContentSize: uint32(length),
+			Encoding: x64.PageEncoding_PAGE_ENCODING_DUPLICATE, ContentHash: bytes.Repeat([]byte{7}, pagemodel.HASHSIZE)},
+		{Address: 0x5000, Length: length, ContentSize: uint32(length),
+			Encoding: x64.PageEncoding_PAGE_ENCODING_DIRTY, Dirty: []*x64.DirtyRange{
+				{Offset: 0, Content: []byte{1}},
+				{Offset: 64, Content: []byte{2, 3, 4}},
+			}},
+	}
+}
+
+func TestPageRoundTrip(t *testing.T) {
+	codec := NewPageCodec(limit.NewDefaultLimits())
+	layouts := map[string]pagemodel.Layout{
+		"pages":  {PageSize: FUZZPAGESIZE},
+		"ranges": {PageSize: FUZZPAGESIZE, Ranges: true},
+		"shared": {PageSize: FUZZPAGESIZE, Shared: &pagemodel.Shared{Region: make([]byte, FUZZREGIONSIZE)}},
+		/* too small for a page, contents follow inline */
+		"shared inline": {PageSize: FUZZPAGESIZE, Shared: &pagemodel.Shared{Region: make([]byte, 16)}},
+		"mappings":      {PageSize: FUZZPAGESIZE, Mappings: true},
+	}
+	for name, layout := range layouts {
+		layout.Elision = true
+		layout.DirtyRanges = true
+		length := uint64(FUZZPAGESIZE)
+		if layout.Ranges {
+			length = 128
+		}
+		for _, want := range testPages(length) {
+			if !layout.Mappings {
+				want.Mapping = nil
+			}
+			if layout.Shared != nil {
+				layout.Shared.Used = 0
+			}
+			reader := bytes.NewReader(joined(codec.Encode(&pagemodel.Page{X64: want}, layout)))
+			if layout.Shared != nil {
+				layout.Shared.Used = 0
+			}
+			got, err := codec.Decode(reader, layout)
+			if err != nil {
+				t.Fatalf("%s: page %#x: %v", name, want.Address, err)
+			}
+			if reader.Len() != 0 {
+				t.Fatalf("%s: page %#x: %d bytes left", name, want.Address, reader.Len())
+			}
+			if !proto.Equal(want, got.X64) {
+				t.Fatalf("%s: page changed through encoding\n%v\n%v", name, want, got.X64)
+			}
+		}
+	}
+}
+
+func TestPageDecodeErrors(t *testing.T) {
+	limits := limit.NewDefaultLimits()
+	codec := NewPageCodec(limits)
+	layout := pagemodel.Layout{PageSize: FUZZPAGESIZE, Elision: true, DirtyRanges: true}
+	pages := testPages(FUZZPAGESIZE)
+	full := joined(codec.Encode(&pagemodel.Page{X64: pages[0]}, layout))
+	duplicate := joined(codec.Encode(&pagemodel.Page{X64: pages[3]}, layout))
+	dirty := joined(codec.Encode(&pagemodel.Page{X64: pages[4]}, layout))
+	/* as many ranges as the page has bytes but none of them on the wire */
+	manyRanges := bytes.Clone(dirty[:PAGEHEADERSIZE+4])
+	binary.LittleEndian.PutUint32(manyRanges[PAGEHEADERSIZE:], FUZZPAGESIZE)
+	oversized := bytes.Clone(full[:PAGEHEADERSIZE])
+	oversized[PAGEHEADERSIZE-4] = 0xff
+	oversized[PAGEHEADERSIZE-3] = 0xff
+	oversized[PAGEHEADERSIZE-2] = 0xff
+	failure := errors.New("read failure")
+	tests := []struct {
+		name   string
+		reader io.Reader
+		budget uint64
+		want   error
+	}{
+		{"no page", bytes.NewReader(nil), FUZZBUDGET, io.EOF},
+		{"truncated header", bytes.NewReader(full[:PAGEHEADERSIZE-1]), FUZZBUDGET, io.ErrUnexpectedEOF},
+		{"truncated content", bytes.NewReader(full[:len(full)-1]), FUZZBUDGET, io.ErrUnexpectedEOF},
+		{"missing content", bytes.NewReader(full[:PAGEHEADERSIZE]), FUZZBUDGET, io.ErrUnexpectedEOF},
+		{"truncated hash", bytes.NewReader(duplicate[:len(duplicate)-1]), FUZZBUDGET, io.ErrUnexpectedEOF},
+		{"truncated dirty range", bytes.NewReader(dirty[:len(dirty)-1]), FUZZBUDGET, io.ErrUnexpectedEOF},
+		{"failed header", iotest.ErrReader(failure), FUZZBUDGET, failure},
+		{"failed content", io.MultiReader(bytes.NewReader(full[:PAGEHEADERSIZE+1]), iotest.ErrReader(failure)),
+			FUZZBUDGET, failure},
+		{"failed dirty range", io.MultiReader(bytes.NewReader(dirty[:PAGEHEADERSIZE+4]), iotest.ErrReader(failure)),
+			FUZZBUDGET, failure},
+		{"oversized", bytes.NewReader(oversized), FUZZBUDGET, nil},
+		{"over budget", bytes.NewReader(full), FUZZPAGESIZE, limit.ErrMemoryExhausted},
+		{"dirty ranges over budget", bytes.NewReader(manyRanges), FUZZPAGESIZE, limit.ErrMemoryExhausted},
+	}
+	for _, test := range tests {
+		layout.Budget = limit.NewBudget(test.budget, nil)
+		_, err := codec.Decode(test.reader, layout)
+		if err == nil {
+			t.Errorf("%s: decoded without an error", test.name)
+			continue
+		}
+		if test.want != nil && !errors.Is(err, test.want) {
+			t.Errorf("%s: %v, want %v", test.name, err, test.want)
+		}
+		if test.want != io.EOF && err == io.EOF {
+			t.Errorf("%s: a clean io.EOF within a page", test.name)
+		}
+	}
+}
+
+func TestPageDecodeMappingErrors(t *testing.T) {
+	codec := NewPageCodec(limit.NewDefaultLimits())
+	layout := pagemodel.Layout{PageSize: FUZZPAGESIZE, Mappings: true}
+	tests := []struct {
+		name    string
+		mapping *x64.Mapping
+	}{
+		{"unknown kind", &x64.Mapping{Kind: x64.MappingKind_MAPPING_KIND_FILE + 1}},
+		{"unknown protection", &x64.Mapping{Kind: x64.MappingKind_MAPPING_KIND_HEAP, Protection: 0x10}},
+		{"path of an anonymous mapping", &x64.Mapping{Kind: x64.MappingKind_MAPPING_KIND_ANONYMOUS, Path: "/tmp/a"}},
+		{"path over the limit", &x64.Mapping{Kind: x64.MappingKind_MAPPING_KIND_FILE,
+			Path: string(bytes.Repeat([]byte{'a'}, int(pagemodel.MAXMAPPINGPATHSIZE)+1))}},
+		{"path not UTF-8", &x64.Mapping{Kind: x64.MappingKind_MAPPING_KIND_FILE, Path: "/tmp/\xff"}},
+	}
+	for _, test := range tests {
+		page := &x64.Page{Address: 0x1000, Content: []byte{}, Mapping: test.mapping}
+		layout.Budget = limit.NewBudget(FUZZBUDGET, nil)
+		encoded := joined(codec.Encode(&pagemodel.Page{X64: page}, layout))
+		if _, err := codec.Decode(bytes.NewReader(encoded), layout); err == nil {
+			t.Errorf("%s: decoded without an error", test.name)
+		}
+	}
+	/* the path is the last of the page without content */
+	encoded := joined(codec.Encode(&pagemodel.Page{X64: testPages(FUZZPAGESIZE)[0]}, layout))
+	encoded = encoded[:len(encoded)-FUZZPAGESIZE-1]
+	layout.Budget = limit.NewBudget(FUZZBUDGET, nil)
+	if _, err := codec.Decode(bytes.NewReader(encoded), layout); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("truncated path decoded with %v", err)
+	}
+}
+
+func TestPageDecodeRejectsFlagsNotNegotiated(t *testing.T) {
+	codec := NewPageCodec(limit.NewDefaultLimits())
+	pages := testPages(FUZZPAGESIZE)
+	tests := []struct {
+		name   string
+		page   *x64.Page
+		layout pagemodel.Layout
+	}{
+		{"zero without elision", pages[2], pagemodel.Layout{PageSize: FUZZPAGESIZE, DirtyRanges: true}},
+		{"duplicate without elision", pages[3], pagemodel.Layout{PageSize: FUZZPAGESIZE, DirtyRanges: true}},
+		{"dirty without dirty ranges", pages[4], pagemodel.Layout{PageSize: FUZZPAGESIZE, Elision: true}},
+	}
+	for _, test := range tests {
+		encoded := joined(codec.Encode(&pagemodel.Page{X64: test.page}, test.layout))
+		if _, err := codec.Decode(bytes.NewReader(encoded), test.layout); err == nil {
+			t.Errorf("%s: decoded without an error", test.name)
+		}
+	}
+}
+
+/* a few bytes on the wire must not expand past the budget */
+func TestExpandPagesChargesBudget(t *testing.T) {
+	content := bytes.Repeat([]byte{1}, FUZZPAGESIZE)
+	pages := []*x64.Page{
+		{Address: 0x1000, Content: content},
+		{Address: 0x2000, ContentSize: FUZZPAGESIZE, Encoding: x64.PageEncoding_PAGE_ENCODING_ZERO},
+		{Address: 0x3000, ContentSize: FUZZPAGESIZE, Encoding: x64.PageEncoding_PAGE_ENCODING_ZERO},
+	}
+	budget := limit.NewBudget(FUZZPAGESIZE, nil)
+	if err := ExpandPages(pages, FUZZPAGESIZE, budget); !errors.Is(err, limit.ErrMemoryExhausted) {
+		t.Fatalf("expanding two zero pages into one page of budget: %v", err)
+	}
+
+	duplicates := []*x64.Page{{Address: 0x1000, Content: content}}
+	for i := range 4 {
+		duplicates = append(duplicates, &x64.Page{Address: uint64(0x2000 + 0x1000*i), Content: content})
+	}
+	ElidePages(duplicates)
+	budget = limit.NewBudget(3*FUZZPAGESIZE, nil)
+	if err := ExpandPages(duplicates, FUZZPAGESIZE, budget); !errors.Is(err, limit.ErrMemoryExhausted) {
+		t.Fatalf("expanding four duplicates into three pages of budget: %v", err)
+	}
+}
+
+func TestPageDecodeSharedContentOnce(t *testing.T) {
+	codec := NewPageCodec(limit.NewDefaultLimits())
+	layout := pagemodel.Layout{
+		PageSize: FUZZPAGESIZE,
+		Shared:   &pagemodel.Shared{Region: make([]byte, FUZZREGIONSIZE)},
+	}
+	page := &pagemodel.Page{X64: testPages(FUZZPAGESIZE)[0]}
+	encoded := joined(codec.Encode(page, layout))
+
+	/* the same entry twice points both pages at one content */
+	layout.Shared.Used = 0
+	reader := bytes.NewReader(append(bytes.Clone(encoded), encoded...))
+	if _, err := codec.Decode(reader, layout); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := codec.Decode(reader, layout); err == nil {
+		t.Fatal("decoded shared content twice")
+	}
+
+	layout.Shared.Used = 0
+	layout.Budget = limit.NewBudget(FUZZPAGESIZE, nil)
+	if _, err := codec.Decode(bytes.NewReader(encoded), layout); !errors.Is(err, limit.ErrMemoryExhausted) {
+		t.Fatalf("copying a page into less than a page of budget: %v", err)
+	}
+}
+
+func TestPageDecodePooledContents(t *testing.T) {
+	codec := NewPageCodec(limit.NewDefaultLimits())
+	layouts := map[string]pagemodel.Layout{
+		"pages":  {PageSize: FUZZPAGESIZE},
+		"shared": {PageSize: FUZZPAGESIZE, Shared: &pagemodel.Shared{Region: make([]byte, FUZZREGIONSIZE)}},
+	}
+	for name, layout := range layouts {
+		layout.Elision = true
+		layout.DirtyRanges = true
+		layout.Contents = &pagemodel.Contents{}
+		/* released buffers come back with the contents of earlier pages */
+		for round := 0; round < 3; round++ {
+			want := testPages(FUZZPAGESIZE)
+			want[0].Content[round] = byte(round)
+			for _, page := range want {
+				page.Mapping = nil
+			}
+			var encoded []byte
+			if layout.Shared != nil {
+				layout.Shared.Used = 0
+			}
+			for _, page := range want {
+				encoded = append(encoded, joined(codec.Encode(&pagemodel.Page{X64: page}, layout))...)
+			}
+			if layout.Shared != nil {
+				layout.Shared.Used = 0
+			}
+			reader := bytes.NewReader(encoded)
+			for _, page := range want {
+				layout.Budget = limit.NewBudget(FUZZBUDGET, nil)
+				got, err := codec.Decode(reader, layout)
+				if err != nil {
+					t.Fatalf("%s: page %#x: %v", name, page.Address, err)
+				}
+				if !proto.Equal(page, got.X64) {
+					t.Fatalf("%s: page %#x changed through a pooled buffer in round %d", name, page.Address, round)
+				}
+			}
+			/* only the page sent in full takes a buffer, empty and elided ones do not */
+			if len(layout.Contents.Buffers) != 1 {
+				t.Fatalf("%s: %d pooled buffers, want 1", name, len(layout.Contents.Buffers))
+			}
+			ReleaseContents(layout.Contents)
+			if len(layout.Contents.Buffers) != 0 {
+				t.Fatalf("%s: %d buffers left after release", name, len(layout.Contents.Buffers))
+			}
+		}
+	}
+}
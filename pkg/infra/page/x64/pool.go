@@ -0,0 +1,55 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"math/bits"
+	"sync"
+
+	pagemodel "github.com/sigrpc/sigrpcd/pkg/domain/model/page"
+)
+
+/* content buffers in size classes of powers of two, the content size mask has 30 bits */
+var contentPools [31]sync.Pool
+
+func contentClass(size int) int {
+	return bits.Len(uint(size - 1))
+}
+
+/* a buffer of size bytes, pooled and kept in contents if it is not nil */
+func newContent(contents *pagemodel.Contents, size uint32) []byte {
+	if contents == nil || size == 0 {
+		return make([]byte, size)
+	}
+	class := contentClass(int(size))
+	var content []byte
+	if buf, ok := contentPools[class].Get().(*[]byte); ok {
+		content = (*buf)[:size]
+	} else {
+		content = make([]byte, size, 1<<class)
+	}
+	contents.Buffers = append(contents.Buffers, content)
+	return content
+}
+
+/* hands the buffers back to the pools, nothing may refer to them afterwards */
+func ReleaseContents(contents *pagemodel.Contents) {
+	for i, content := range contents.Buffers {
+		content = content[:cap(content)]
+		contentPools[contentClass(len(content))].Put(&content)
+		contents.Buffers[i] = nil
+	}
+	contents.Buffers = contents.Buffers[:0]
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package peer
+
+import (
+	"errors"
+	"net"
+	"syscall"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/peer"
+	peerrepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/peer"
+)
+
+type Credentials struct{}
+
+func NewCredentials() peerrepository.Credentials {
+	return &Credentials{}
+}
+
+/* returns nil for connections without kernel-provided credentials such as TCP */
+func (c *Credentials) Get(conn net.Conn) (*peer.Peer, error) {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return nil, nil
+	}
+	rawConn, err := unixConn.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+	var ucred *syscall.Ucred
+	var credErr error
+	err = rawConn.Control(func(fd uintptr) {
+		ucred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if credErr != nil {
+		return nil, credErr
+	}
+	/* the kernel reports pid 0 for a peer whose pid namespace is not below the daemon's */
+	if ucred.Pid == 0 {
+		return nil, msg.NewRPCError(msg.STATUSPERMISSIONDENIED,
+			errors.New("peer is not visible in the pid namespace of the daemon"))
+	}
+	p := peer.Peer{
+		Pid: ucred.Pid,
+		Uid: ucred.Uid,
+		Gid: ucred.Gid,
+	}
+	if p.NsPid, err = nsPid(p.Pid); err != nil {
+		return nil, err
+	}
+	if p.PidNamespace, err = pidNamespace(p.Pid); err != nil {
+		return nil, err
+	}
+	if p.StartTime, err = StartTime(p.Pid); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
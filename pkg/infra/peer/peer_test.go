@@ -0,0 +1,72 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package peer
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/* the accepted end of a unix socket the test process connected to itself */
+func selfConn(t *testing.T) net.Conn {
+	t.Helper()
+	sock, err := net.Listen("unix", filepath.Join(t.TempDir(), "peer.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sock.Close()
+	client, err := net.Dial("unix", sock.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	conn, err := sock.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestGetReadsTestProcess(t *testing.T) {
+	p, err := NewCredentials().Get(selfConn(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Pid != int32(os.Getpid()) || p.Uid != uint32(os.Getuid()) || p.Gid != uint32(os.Getgid()) {
+		t.Fatalf("peer %+v is not the test process", p)
+	}
+	if p.ClientPid() != int32(os.Getpid()) || p.PidNamespace == 0 || p.StartTime == 0 {
+		t.Fatalf("peer %+v is not annotated", p)
+	}
+}
+
+func TestGetWithoutCredentials(t *testing.T) {
+	sock, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sock.Close()
+	conn, err := net.Dial("tcp", sock.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if p, err := NewCredentials().Get(conn); p != nil || err != nil {
+		t.Fatalf("TCP peer is %+v (%v), want none", p, err)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package peer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+func procPath(pid int32, name string) string {
+	return "/proc/" + strconv.FormatInt(int64(pid), 10) + "/" + name
+}
+
+/* the innermost pid of NSpid in /proc/<pid>/status */
+func nsPid(pid int32) (int32, error) {
+	file, err := os.Open(procPath(pid, "status"))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "NSpid:") {
+			continue
+		}
+		fields := strings.Fields(line[len("NSpid:"):])
+		if len(fields) == 0 {
+			break
+		}
+		v, err := strconv.ParseInt(fields[len(fields)-1], 10, 32)
+		if err != nil {
+			return 0, err
+		}
+		return int32(v), nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errors.New("NSpid is not found")
+}
+
+func pidNamespace(pid int32) (uint64, error) {
+	info, err := os.Stat(procPath(pid, "ns/pid"))
+	if err != nil {
+		return 0, err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, errors.New("unsupported stat")
+	}
+	return stat.Ino, nil
+}
+
+/* starttime, the 22nd field of /proc/<pid>/stat, in clock ticks since boot */
+func StartTime(pid int32) (uint64, error) {
+	byteStat, err := os.ReadFile(procPath(pid, "stat"))
+	if err != nil {
+		return 0, err
+	}
+	stat := string(byteStat)
+	commEnd := strings.LastIndex(stat, ")")
+	if commEnd < 0 {
+		return 0, errors.New("malformed stat")
+	}
+	/* fields after comm start from the 3rd field */
+	fields := strings.Fields(stat[commEnd+1:])
+	if len(fields) < 20 {
+		return 0, fmt.Errorf("stat has only %d fields", len(fields)+2)
+	}
+	return strconv.ParseUint(fields[19], 10, 64)
+}
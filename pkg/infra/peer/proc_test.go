@@ -0,0 +1,61 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package peer
+
+import (
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestProcOfTestProcess(t *testing.T) {
+	pid := int32(os.Getpid())
+	/* the test runs in the pid namespace of its own /proc */
+	if got, err := nsPid(pid); err != nil || got != pid {
+		t.Fatalf("nsPid is %d (%v), want %d", got, err, pid)
+	}
+	var stat syscall.Stat_t
+	if err := syscall.Stat("/proc/self/ns/pid", &stat); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := pidNamespace(pid); err != nil || got != stat.Ino {
+		t.Fatalf("pidNamespace is %d (%v), want %d", got, err, stat.Ino)
+	}
+	startTime, err := StartTime(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	/* the test process started after pid 1 */
+	if init, err := StartTime(1); err == nil && startTime < init {
+		t.Fatalf("start time %d is before the one of pid 1 %d", startTime, init)
+	}
+	if again, err := StartTime(pid); err != nil || again != startTime {
+		t.Fatalf("start time changed from %d to %d (%v)", startTime, again, err)
+	}
+}
+
+func TestProcOfMissingProcess(t *testing.T) {
+	/* pids never reach the maximum of int32 */
+	const pid = 1<<31 - 1
+	if _, err := nsPid(pid); err == nil {
+		t.Fatal("nsPid of a missing process succeeded")
+	}
+	if _, err := pidNamespace(pid); err == nil {
+		t.Fatal("pidNamespace of a missing process succeeded")
+	}
+	if _, err := StartTime(pid); err == nil {
+		t.Fatal("StartTime of a missing process succeeded")
+	}
+}
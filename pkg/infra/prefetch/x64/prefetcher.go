@@ -0,0 +1,249 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"container/list"
+	"log"
+	"sync"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/prefetch"
+	prefetchrepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/prefetch"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+)
+
+/*
+ * pages a function touched and what is prefetched for its current invocation.
+ * Pulls name the function they fault for, so threads invoking other functions never mix.
+ */
+type history struct {
+	invokeFuncID uint64
+	addresses    map[uint64]*list.Element
+	order        *list.List
+	/* address -> refaulted, of pages prefetched during the current invocation */
+	prefetched map[uint64]bool
+}
+
+type clientState struct {
+	clientID string
+	/* the least recently invoked functions are forgotten first */
+	histories map[uint64]*list.Element
+	lru       *list.List
+}
+
+type Prefetcher struct {
+	mu       sync.Mutex
+	config   prefetch.Config
+	pageSize uint64
+	clients  map[string]*list.Element
+	lru      *list.List
+	stats    prefetch.Stats
+}
+
+func NewPrefetcher(config prefetch.Config, pageSize uint64) prefetchrepository.Prefetcher {
+	return &Prefetcher{
+		config:   config,
+		pageSize: pageSize,
+		clients:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+func (p *Prefetcher) client(clientID string) *clientState {
+	if elem, ok := p.clients[clientID]; ok {
+		p.lru.MoveToFront(elem)
+		return elem.Value.(*clientState)
+	}
+	for uint64(p.lru.Len()) >= p.config.MaxClients {
+		state := p.lru.Remove(p.lru.Back()).(*clientState)
+		for _, elem := range state.histories {
+			p.endInvocation(elem.Value.(*history))
+		}
+		delete(p.clients, state.clientID)
+	}
+	state := &clientState{
+		clientID:  clientID,
+		histories: make(map[uint64]*list.Element),
+		lru:       list.New(),
+	}
+	p.clients[clientID] = p.lru.PushFront(state)
+	return state
+}
+
+func (p *Prefetcher) endInvocation(h *history) {
+	for _, refaulted := range h.prefetched {
+		if !refaulted {
+			p.stats.NotRefaulted++
+		}
+	}
+	h.prefetched = make(map[uint64]bool)
+}
+
+func (p *Prefetcher) history(state *clientState, invokeFuncID uint64) *history {
+	if elem, ok := state.histories[invokeFuncID]; ok {
+		state.lru.MoveToFront(elem)
+		return elem.Value.(*history)
+	}
+	for uint64(state.lru.Len()) >= p.config.MaxHistories {
+		evicted := state.lru.Remove(state.lru.Back()).(*history)
+		p.endInvocation(evicted)
+		delete(state.histories, evicted.invokeFuncID)
+	}
+	h := &history{
+		invokeFuncID: invokeFuncID,
+		addresses:    make(map[uint64]*list.Element),
+		order:        list.New(),
+		prefetched:   make(map[uint64]bool),
+	}
+	state.histories[invokeFuncID] = state.lru.PushFront(h)
+	return h
+}
+
+func (p *Prefetcher) record(h *history, address uint64) {
+	if elem, ok := h.addresses[address]; ok {
+		h.order.MoveToBack(elem)
+		return
+	}
+	for uint64(h.order.Len()) >= p.config.MaxHistoryPages {
+		delete(h.addresses, h.order.Remove(h.order.Front()).(uint64))
+	}
+	h.addresses[address] = h.order.PushBack(address)
+}
+
+/* a request with resp_id 0 starts a new invocation of its function */
+func (p *Prefetcher) Invoke(invokeFunc *msg.InvokeFuncMsg) {
+	if invokeFunc.X64.RespId != 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state := p.client(invokeFunc.X64.Header.ClientId)
+	p.endInvocation(p.history(state, invokeFunc.X64.InvokefuncId))
+}
+
+func (p *Prefetcher) candidates(h *history, demand []*x64.Page) []uint64 {
+	requested := make(map[uint64]bool, len(demand))
+	for _, page := range demand {
+		requested[page.Address] = true
+	}
+	addresses := make([]uint64, 0, p.config.Window)
+	add := func(address uint64) bool {
+		if uint64(len(addresses)) >= p.config.Window {
+			return false
+		}
+		if !requested[address] {
+			if _, ok := h.prefetched[address]; !ok {
+				requested[address] = true
+				addresses = append(addresses, address)
+			}
+		}
+		return true
+	}
+	switch p.config.Policy {
+	case prefetch.POLICYSEQUENTIAL:
+		for _, page := range demand {
+			stride := page.Length
+			if stride == 0 {
+				stride = p.pageSize
+			}
+			for i := uint64(1); i <= p.config.Window; i++ {
+				next := page.Address + i*stride
+				if next < page.Address || !add(next) {
+					break
+				}
+			}
+		}
+	case prefetch.POLICYHISTORY:
+		for elem := h.order.Front(); elem != nil; elem = elem.Next() {
+			if !add(elem.Value.(uint64)) {
+				break
+			}
+		}
+	}
+	return addresses
+}
+
+func (p *Prefetcher) Pull(
+	req *msg.PullPageMsg,
+	fetch func(*msg.PullPageMsg) (*msg.PullPageMsg, error)) (*msg.PullPageMsg, error) {
+	if len(req.X64.Page) == 0 {
+		return fetch(req)
+	}
+	p.mu.Lock()
+	state := p.client(req.X64.Header.ClientId)
+	p.stats.Requests++
+	p.stats.DemandPages += uint64(len(req.X64.Page))
+	h := p.history(state, req.X64.InvokefuncId)
+	for _, page := range req.X64.Page {
+		if refaulted, ok := h.prefetched[page.Address]; ok && !refaulted {
+			h.prefetched[page.Address] = true
+			p.stats.Refaulted++
+		}
+		p.record(h, page.Address)
+	}
+	addresses := p.candidates(h, req.X64.Page)
+	p.mu.Unlock()
+	if len(addresses) == 0 {
+		return fetch(req)
+	}
+
+	/* prefetched pages share the revision and length of the page that faulted */
+	revision := req.X64.Page[0].RuntimeRevision
+	length := req.X64.Page[0].Length
+	pages := append(make([]*x64.Page, 0, len(req.X64.Page)+len(addresses)), req.X64.Page...)
+	for _, address := range addresses {
+		pages = append(pages, &x64.Page{
+			Address:         address,
+			RuntimeRevision: revision,
+			Length:          length,
+		})
+	}
+	resp, err := fetch(&msg.PullPageMsg{
+		X64: &x64.PullPageMsg{
+			Header:       req.X64.Header,
+			Page:         pages,
+			InvokefuncId: req.X64.InvokefuncId,
+		},
+	})
+	if err != nil || (resp.X64.Header != nil && resp.X64.Header.Status != msg.STATUSOK) {
+		/* a prefetched page may not be mapped by the stub, retry only what is demanded */
+		if err != nil {
+			log.Println("prefetch:", err)
+		}
+		p.mu.Lock()
+		p.stats.Failed++
+		p.mu.Unlock()
+		return fetch(req)
+	}
+	p.mu.Lock()
+	for _, page := range resp.X64.Page {
+		for _, address := range addresses {
+			if page.Address == address {
+				h.prefetched[address] = false
+				p.stats.Prefetched++
+				break
+			}
+		}
+	}
+	p.mu.Unlock()
+	return resp, nil
+}
+
+func (p *Prefetcher) Stats() prefetch.Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"testing"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/prefetch"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+)
+
+func TestPrefetcherBoundsHistories(t *testing.T) {
+	config := prefetch.NewDefaultConfig()
+	config.Policy = prefetch.POLICYHISTORY
+	config.MaxHistories = 2
+	p := NewPrefetcher(config, 0x1000).(*Prefetcher)
+	fetch := func(req *msg.PullPageMsg) (*msg.PullPageMsg, error) {
+		return &msg.PullPageMsg{X64: &x64.PullPageMsg{Header: req.X64.Header, Page: req.X64.Page}}, nil
+	}
+	header := &x64.RPCHeader{ClientId: "test"}
+	for id := uint64(1); id <= 8; id++ {
+		p.Invoke(&msg.InvokeFuncMsg{X64: &x64.InvokeFuncMsg{Header: header, InvokefuncId: id}})
+		if _, err := p.Pull(&msg.PullPageMsg{X64: &x64.PullPageMsg{
+			Header:       header,
+			InvokefuncId: id,
+			Page:         []*x64.Page{{Address: id << 12}},
+		}}, fetch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	state := p.clients["test"].Value.(*clientState)
+	if len(state.histories) != 2 || state.lru.Len() != 2 {
+		t.Fatalf("%d histories are kept, want 2", len(state.histories))
+	}
+	for _, id := range []uint64{7, 8} {
+		if _, ok := state.histories[id]; !ok {
+			t.Fatalf("history of function %d is forgotten", id)
+		}
+	}
+}
+
+func TestPrefetcherKeepsInvocationsApart(t *testing.T) {
+	config := prefetch.NewDefaultConfig()
+	config.Policy = prefetch.POLICYSEQUENTIAL
+	config.Window = 2
+	p := NewPrefetcher(config, 0x1000).(*Prefetcher)
+	fetch := func(req *msg.PullPageMsg) (*msg.PullPageMsg, error) {
+		return &msg.PullPageMsg{X64: &x64.PullPageMsg{Header: req.X64.Header, Page: req.X64.Page}}, nil
+	}
+	header := &x64.RPCHeader{ClientId: "test"}
+	pull := func(id uint64, address uint64) {
+		t.Helper()
+		if _, err := p.Pull(&msg.PullPageMsg{X64: &x64.PullPageMsg{
+			Header:       header,
+			InvokefuncId: id,
+			Page:         []*x64.Page{{Address: address}},
+		}}, fetch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	/* two threads of a client run functions 1 and 2 at once */
+	p.Invoke(&msg.InvokeFuncMsg{X64: &x64.InvokeFuncMsg{Header: header, InvokefuncId: 1}})
+	p.Invoke(&msg.InvokeFuncMsg{X64: &x64.InvokeFuncMsg{Header: header, InvokefuncId: 2}})
+	pull(1, 0x10000)
+	/* a fault of function 2 on a page prefetched for function 1 */
+	pull(2, 0x11000)
+	/* function 1 pulls its own prefetched page again */
+	pull(1, 0x12000)
+	if stats := p.Stats(); stats.Prefetched != 6 || stats.Refaulted != 1 {
+		t.Fatalf("stats %s", stats)
+	}
+	/* a continued invocation keeps what is prefetched for it */
+	p.Invoke(&msg.InvokeFuncMsg{X64: &x64.InvokeFuncMsg{Header: header, InvokefuncId: 1, RespId: 1}})
+	if stats := p.Stats(); stats.NotRefaulted != 0 {
+		t.Fatalf("stats %s", stats)
+	}
+	p.Invoke(&msg.InvokeFuncMsg{X64: &x64.InvokeFuncMsg{Header: header, InvokefuncId: 1}})
+	if stats := p.Stats(); stats.NotRefaulted != 3 {
+		t.Fatalf("stats %s", stats)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/profile"
+)
+
+/*
+ * written aside and renamed so that readers never see a partial profile.
+ * Symbols and addresses of the clients are for the owner alone.
+ */
+func WriteFile(path string, p *profile.Profile) error {
+	byteProfile, err := json.MarshalIndent(p, "", "    ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	/* a file left by a crash keeps its mode when it is written again */
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.WriteFile(tmp, byteProfile, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,43 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/profile"
+)
+
+func TestWriteFileOwnerOnly(t *testing.T) {
+	umask := syscall.Umask(0)
+	defer syscall.Umask(umask)
+	path := filepath.Join(t.TempDir(), "profile.json")
+	if err := os.WriteFile(path+".tmp", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, &profile.Profile{}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Fatalf("profile is written with mode %o", mode)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"container/list"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/profile"
+	profilerepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/profile"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+)
+
+/* clients whose symbols and current function are remembered */
+const MAXCLIENTS = 256
+
+type functionKey struct {
+	invokeFuncID uint64
+	symbol       string
+}
+
+type function struct {
+	profile.Function
+	pages map[uint64]*profile.Page
+}
+
+type clientState struct {
+	clientID string
+	symbols  map[uint64]string
+	current  *function
+}
+
+type Profiler struct {
+	mu                  sync.Mutex
+	maxFunctions        uint64
+	maxPagesPerFunction uint64
+	functions           map[functionKey]*function
+	droppedFunctions    uint64
+	clients             map[string]*list.Element
+	lru                 *list.List
+}
+
+func NewProfiler(maxFunctions uint64, maxPagesPerFunction uint64) profilerepository.Profiler {
+	return &Profiler{
+		maxFunctions:        maxFunctions,
+		maxPagesPerFunction: maxPagesPerFunction,
+		functions:           make(map[functionKey]*function),
+		clients:             make(map[string]*list.Element),
+		lru:                 list.New(),
+	}
+}
+
+func (p *Profiler) client(clientID string) *clientState {
+	if elem, ok := p.clients[clientID]; ok {
+		p.lru.MoveToFront(elem)
+		return elem.Value.(*clientState)
+	}
+	for p.lru.Len() >= MAXCLIENTS {
+		delete(p.clients, p.lru.Remove(p.lru.Back()).(*clientState).clientID)
+	}
+	state := &clientState{
+		clientID: clientID,
+		symbols:  make(map[uint64]string),
+	}
+	p.clients[clientID] = p.lru.PushFront(state)
+	return state
+}
+
+func (p *Profiler) function(key functionKey) *function {
+	if f, ok := p.functions[key]; ok {
+		return f
+	}
+	if uint64(len(p.functions)) >= p.maxFunctions {
+		p.droppedFunctions++
+		return nil
+	}
+	f := &function{
+		Function: profile.Function{
+			InvokeFuncID: key.invokeFuncID,
+			Symbol:       key.symbol,
+		},
+		pages: make(map[uint64]*profile.Page),
+	}
+	p.functions[key] = f
+	return f
+}
+
+func (p *Profiler) page(f *function, address uint64) *profile.Page {
+	if page, ok := f.pages[address]; ok {
+		return page
+	}
+	if uint64(len(f.pages)) >= p.maxPagesPerFunction {
+		f.DroppedPages++
+		return nil
+	}
+	page := &profile.Page{
+		Address: address,
+	}
+	f.pages[address] = page
+	return page
+}
+
+func (p *Profiler) LoadLib(clientID string, loadlib *msg.LoadLibMsg) {
+	if loadlib == nil || loadlib.X64 == nil || len(loadlib.X64.Addr2Sym) == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state := p.client(clientID)
+	for _, addr2sym := range loadlib.X64.Addr2Sym {
+		state.symbols[addr2sym.Address] = addr2sym.Name
+	}
+}
+
+/* a request with resp_id 0 or another function starts a new invocation */
+func (p *Profiler) Invoke(invokeFunc *msg.InvokeFuncMsg) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state := p.client(invokeFunc.X64.Header.ClientId)
+	if invokeFunc.X64.RespId == 0 || state.current == nil ||
+		state.current.InvokeFuncID != invokeFunc.X64.InvokefuncId {
+		state.current = p.function(functionKey{
+			invokeFuncID: invokeFunc.X64.InvokefuncId,
+			symbol:       state.symbols[invokeFunc.X64.InvokefuncId],
+		})
+		if state.current != nil {
+			state.current.Invocations++
+		}
+	}
+	if state.current == nil {
+		return
+	}
+	for _, x64page := range invokeFunc.X64.Page {
+		if page := p.page(state.current, x64page.Address); page != nil {
+			page.Sent++
+			page.SentBytes += contentSize(x64page)
+		}
+	}
+}
+
+func (p *Profiler) Return(clientID string, invokeFunc *msg.InvokeFuncMsg, elapsed time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state := p.client(clientID)
+	if state.current == nil {
+		return
+	}
+	state.current.Calls++
+	state.current.CallNanos += uint64(elapsed)
+	if invokeFunc == nil || invokeFunc.X64 == nil {
+		return
+	}
+	for _, x64page := range invokeFunc.X64.Page {
+		if page := p.page(state.current, x64page.Address); page != nil {
+			page.Returned++
+			page.ReturnedBytes += contentSize(x64page)
+		}
+	}
+}
+
+func (p *Profiler) Pull(clientID string, pullPage *msg.PullPageMsg, elapsed time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state := p.client(clientID)
+	if state.current == nil {
+		return
+	}
+	state.current.Pulls++
+	state.current.PullNanos += uint64(elapsed)
+	for _, x64page := range pullPage.X64.Page {
+		if page := p.page(state.current, x64page.Address); page != nil {
+			page.Pulled++
+			page.PulledBytes += contentSize(x64page)
+		}
+	}
+}
+
+func contentSize(page *x64.Page) uint64 {
+	if len(page.Content) > 0 {
+		return uint64(len(page.Content))
+	}
+	return uint64(page.ContentSize)
+}
+
+func (p *Profiler) Snapshot() *profile.Profile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	snapshot := profile.Profile{
+		Functions:        make([]profile.Function, 0, len(p.functions)),
+		DroppedFunctions: p.droppedFunctions,
+	}
+	for _, f := range p.functions {
+		function := f.Function
+		function.Pages = make([]profile.Page, 0, len(f.pages))
+		for _, page := range f.pages {
+			function.Pages = append(function.Pages, *page)
+		}
+		sort.Slice(function.Pages, func(i, j int) bool {
+			return function.Pages[i].Address < function.Pages[j].Address
+		})
+		snapshot.Functions = append(snapshot.Functions, function)
+	}
+	sort.Slice(snapshot.Functions, func(i, j int) bool {
+		if snapshot.Functions[i].InvokeFuncID != snapshot.Functions[j].InvokeFuncID {
+			return snapshot.Functions[i].InvokeFuncID < snapshot.Functions[j].InvokeFuncID
+		}
+		return snapshot.Functions[i].Symbol < snapshot.Functions[j].Symbol
+	})
+	return &snapshot
+}
@@ -0,0 +1,206 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shm
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/page"
+	shmrepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/shm"
+	"golang.org/x/sys/unix"
+)
+
+/* descriptors accepted along with one read */
+const MAXRIGHTS = 4
+
+/*
+ * keeps the descriptor passed last with SCM_RIGHTS and the region mapped from it.
+ * Descriptors are only accepted until HELLO is answered.
+ */
+type conn struct {
+	*net.UnixConn
+	oob     []byte
+	mu      sync.Mutex
+	fd      int
+	region  []byte
+	refused bool
+	closed  bool
+}
+
+func (c *conn) Read(b []byte) (int, error) {
+	n, oobn, _, _, err := c.ReadMsgUnix(b, c.oob)
+	if oobn > 0 {
+		c.receive(c.oob[:oobn])
+	}
+	return n, err
+}
+
+func (c *conn) receive(oob []byte) {
+	msgs, err := unix.ParseSocketControlMessage(oob)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range msgs {
+		fds, err := unix.ParseUnixRights(&msgs[i])
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			if c.closed || c.refused {
+				unix.Close(fd)
+				continue
+			}
+			if c.fd >= 0 {
+				unix.Close(c.fd)
+			}
+			c.fd = fd
+		}
+	}
+}
+
+func (c *conn) take() (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fd := c.fd
+	c.fd = -1
+	return fd, fd >= 0
+}
+
+func (c *conn) refuse() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refused = true
+	if c.fd >= 0 {
+		unix.Close(c.fd)
+		c.fd = -1
+	}
+}
+
+func (c *conn) unmap() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.region != nil {
+		unix.Munmap(c.region)
+		c.region = nil
+	}
+}
+
+func (c *conn) Close() error {
+	err := c.UnixConn.Close()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return err
+	}
+	c.closed = true
+	if c.fd >= 0 {
+		unix.Close(c.fd)
+		c.fd = -1
+	}
+	if c.region != nil {
+		unix.Munmap(c.region)
+		c.region = nil
+	}
+	return err
+}
+
+type SharedMemory struct {
+	limits limit.Limits
+}
+
+func NewSharedMemory(limits limit.Limits) shmrepository.SharedMemory {
+	return &SharedMemory{
+		limits: limits,
+	}
+}
+
+func (s *SharedMemory) Accept(sock net.Conn) net.Conn {
+	unixConn, ok := sock.(*net.UnixConn)
+	if !ok {
+		return sock
+	}
+	return &conn{
+		UnixConn: unixConn,
+		oob:      make([]byte, unix.CmsgSpace(MAXRIGHTS*4)),
+		fd:       -1,
+	}
+}
+
+/*
+ * maps the memfd passed last on the connection.
+ * It must be sealed against shrinking so that accessing it never faults.
+ */
+func (s *SharedMemory) Map(sock net.Conn) (*page.Shared, error) {
+	c, ok := sock.(*conn)
+	if !ok {
+		return nil, errors.New("shared memory needs a unix socket")
+	}
+	fd, ok := c.take()
+	if !ok {
+		return nil, errors.New("no memfd is passed")
+	}
+	defer unix.Close(fd)
+	seals, err := unix.FcntlInt(uintptr(fd), unix.F_GET_SEALS, 0)
+	if err != nil {
+		return nil, fmt.Errorf("getting seals of the shared memory: %w", err)
+	}
+	if seals&unix.F_SEAL_SHRINK == 0 {
+		return nil, errors.New("shared memory is not sealed against shrinking")
+	}
+	var stat unix.Stat_t
+	if err := unix.Fstat(fd, &stat); err != nil {
+		return nil, err
+	}
+	if stat.Size <= 0 || uint64(stat.Size) > s.limits.MaxFrameSize {
+		return nil, fmt.Errorf("shared memory size %d is out of 1-%d", stat.Size, s.limits.MaxFrameSize)
+	}
+	region, err := unix.Mmap(fd, 0, int(stat.Size), unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		unix.Munmap(region)
+		return nil, net.ErrClosed
+	}
+	if c.region != nil {
+		unix.Munmap(c.region)
+	}
+	c.region = region
+	return &page.Shared{
+		Region: region,
+	}, nil
+}
+
+/* releases the region mapped from the connection */
+func (s *SharedMemory) Unmap(sock net.Conn) {
+	if c, ok := sock.(*conn); ok {
+		c.unmap()
+	}
+}
+
+/* closes the descriptors passed so far and any passed later */
+func (s *SharedMemory) Refuse(sock net.Conn) {
+	if c, ok := sock.(*conn); ok {
+		c.refuse()
+	}
+}
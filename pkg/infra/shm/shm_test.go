@@ -0,0 +1,133 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shm
+
+import (
+	"net"
+	"os"
+	"testing"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
+	"golang.org/x/sys/unix"
+)
+
+func pair(t *testing.T) (*net.UnixConn, *net.UnixConn) {
+	t.Helper()
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conns [2]*net.UnixConn
+	for i, fd := range fds {
+		file := os.NewFile(uintptr(fd), "socketpair")
+		c, err := net.FileConn(file)
+		file.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conns[i] = c.(*net.UnixConn)
+		t.Cleanup(func() { c.Close() })
+	}
+	return conns[0], conns[1]
+}
+
+func memfd(t *testing.T, size int64) int {
+	t.Helper()
+	fd, err := unix.MemfdCreate("shm_test", unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		t.Skip(err)
+	}
+	t.Cleanup(func() { unix.Close(fd) })
+	if err := unix.Ftruncate(fd, size); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := unix.FcntlInt(uintptr(fd), unix.F_ADD_SEALS, unix.F_SEAL_SHRINK); err != nil {
+		t.Fatal(err)
+	}
+	return fd
+}
+
+func send(t *testing.T, c *net.UnixConn, fds ...int) {
+	t.Helper()
+	if _, _, err := c.WriteMsgUnix([]byte{0}, unix.UnixRights(fds...), nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func receive(t *testing.T, c net.Conn) {
+	t.Helper()
+	if _, err := c.Read(make([]byte, 1)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func openFds(t *testing.T) int {
+	t.Helper()
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skip(err)
+	}
+	return len(entries)
+}
+
+func TestOnlyLastDescriptorIsKept(t *testing.T) {
+	client, server := pair(t)
+	shm := NewSharedMemory(limit.NewDefaultLimits())
+	sock := shm.Accept(server)
+	fd := memfd(t, 4096)
+	before := openFds(t)
+	for i := 0; i < 8; i++ {
+		send(t, client, fd, fd)
+		receive(t, sock)
+	}
+	if n := openFds(t); n != before+1 {
+		t.Fatalf("%d descriptors are held, want 1", n-before)
+	}
+	shared, err := shm.Map(sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shared.Region) != 4096 {
+		t.Fatalf("mapped %d bytes, want 4096", len(shared.Region))
+	}
+	if n := openFds(t); n != before {
+		t.Fatalf("%d descriptors are held after mapping, want 0", n-before)
+	}
+	shm.Unmap(sock)
+	if sock.(*conn).region != nil {
+		t.Fatal("region is still mapped")
+	}
+}
+
+func TestRefusedDescriptorsAreClosed(t *testing.T) {
+	client, server := pair(t)
+	shm := NewSharedMemory(limit.NewDefaultLimits())
+	sock := shm.Accept(server)
+	fd := memfd(t, 4096)
+	before := openFds(t)
+	send(t, client, fd)
+	receive(t, sock)
+	shm.Refuse(sock)
+	for i := 0; i < 8; i++ {
+		send(t, client, fd, fd)
+		receive(t, sock)
+	}
+	if n := openFds(t); n != before {
+		t.Fatalf("%d descriptors are held after HELLO, want 0", n-before)
+	}
+	if _, err := shm.Map(sock); err == nil {
+		t.Fatal("mapped a descriptor passed after HELLO")
+	}
+}
@@ -0,0 +1,362 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/store"
+	storerepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/store"
+)
+
+/* hashes the stub reported are forgotten all at once beyond this count */
+const MAXREMOTEHASHES = 1 << 20
+
+/* contents waiting for the disk, more are only kept in memory */
+const DISKQUEUESIZE = 64
+
+type hash = [sha256.Size]byte
+
+type entry struct {
+	hash    hash
+	content []byte
+	size    uint64
+}
+
+type ContentStore struct {
+	mu             sync.Mutex
+	config         store.Config
+	memory         map[hash]*list.Element
+	memoryLRU      *list.List
+	disk           map[hash]*list.Element
+	diskLRU        *list.List
+	remote         map[hash]struct{}
+	remoteDisabled bool
+	stats          store.Stats
+	pending        map[hash]struct{}
+	writes         chan *entry
+	closed         bool
+	done           chan struct{}
+}
+
+func NewContentStore(config store.Config) (storerepository.Content, error) {
+	s := &ContentStore{
+		config:    config,
+		memory:    make(map[hash]*list.Element),
+		memoryLRU: list.New(),
+		disk:      make(map[hash]*list.Element),
+		diskLRU:   list.New(),
+		remote:    make(map[hash]struct{}),
+		pending:   make(map[hash]struct{}),
+	}
+	if len(config.Dir) > 0 {
+		if err := os.MkdirAll(config.Dir, 0700); err != nil {
+			return nil, err
+		}
+		if err := s.scan(); err != nil {
+			return nil, err
+		}
+		s.writes = make(chan *entry, DISKQUEUESIZE)
+		s.done = make(chan struct{})
+		go s.writeDisk()
+	}
+	return s, nil
+}
+
+func (s *ContentStore) path(h hash) string {
+	name := hex.EncodeToString(h[:])
+	return filepath.Join(s.config.Dir, name[:2], name[2:])
+}
+
+/* indexes contents left by a previous sigrpcd, oldest first */
+func (s *ContentStore) scan() error {
+	type found struct {
+		hash hash
+		size uint64
+		mod  int64
+	}
+	files := make([]found, 0)
+	err := filepath.WalkDir(s.config.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		/* a write cut short by a crash */
+		if strings.HasSuffix(path, ".tmp") {
+			return os.Remove(path)
+		}
+		rel, err := filepath.Rel(s.config.Dir, path)
+		if err != nil {
+			return err
+		}
+		name, err := hex.DecodeString(filepath.Dir(rel) + filepath.Base(rel))
+		if err != nil || len(name) != sha256.Size {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, found{hash(name), uint64(info.Size()), info.ModTime().UnixNano()})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].mod < files[j].mod })
+	for _, f := range files {
+		s.disk[f.hash] = s.diskLRU.PushFront(&entry{hash: f.hash, size: f.size})
+		s.stats.DiskEntries++
+		s.stats.DiskBytes += f.size
+	}
+	s.removeFiles(s.trimDisk())
+	return nil
+}
+
+func (s *ContentStore) trimMemory() {
+	for s.stats.MemoryBytes > s.config.MaxMemory && s.memoryLRU.Len() > 0 {
+		e := s.memoryLRU.Remove(s.memoryLRU.Back()).(*entry)
+		delete(s.memory, e.hash)
+		s.stats.MemoryEntries--
+		s.stats.MemoryBytes -= e.size
+	}
+}
+
+/* returns the contents whose files are to be removed outside the lock */
+func (s *ContentStore) trimDisk() []hash {
+	removed := make([]hash, 0)
+	for s.stats.DiskBytes > s.config.MaxDisk && s.diskLRU.Len() > 0 {
+		e := s.diskLRU.Remove(s.diskLRU.Back()).(*entry)
+		delete(s.disk, e.hash)
+		s.stats.DiskEntries--
+		s.stats.DiskBytes -= e.size
+		removed = append(removed, e.hash)
+	}
+	return removed
+}
+
+func (s *ContentStore) removeFiles(hashes []hash) {
+	for _, h := range hashes {
+		if err := os.Remove(s.path(h)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Println(err)
+		}
+	}
+}
+
+func (s *ContentStore) putMemory(h hash, content []byte) {
+	if elem, ok := s.memory[h]; ok {
+		s.memoryLRU.MoveToFront(elem)
+		return
+	}
+	size := uint64(len(content))
+	if size > s.config.MaxMemory {
+		return
+	}
+	s.memory[h] = s.memoryLRU.PushFront(&entry{hash: h, content: content, size: size})
+	s.stats.MemoryEntries++
+	s.stats.MemoryBytes += size
+	s.trimMemory()
+}
+
+/* queues the content for the disk writer, Put never waits for the disk */
+func (s *ContentStore) putDisk(h hash, content []byte) {
+	if s.writes == nil || s.closed {
+		return
+	}
+	if elem, ok := s.disk[h]; ok {
+		s.diskLRU.MoveToFront(elem)
+		return
+	}
+	if _, ok := s.pending[h]; ok {
+		return
+	}
+	size := uint64(len(content))
+	if size > s.config.MaxDisk {
+		return
+	}
+	select {
+	case s.writes <- &entry{hash: h, content: content, size: size}:
+		s.pending[h] = struct{}{}
+	default:
+	}
+}
+
+func (s *ContentStore) writeFile(e *entry) error {
+	path := s.path(e.hash)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	/* written aside and renamed so that a crash never leaves a partial content */
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, e.content, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+/* the only goroutine touching files of the store after it is scanned */
+func (s *ContentStore) writeDisk() {
+	defer close(s.done)
+	for e := range s.writes {
+		err := s.writeFile(e)
+		if err != nil {
+			log.Println(err)
+		}
+		s.mu.Lock()
+		delete(s.pending, e.hash)
+		removed := []hash(nil)
+		if err == nil {
+			e.content = nil
+			s.disk[e.hash] = s.diskLRU.PushFront(e)
+			s.stats.DiskEntries++
+			s.stats.DiskBytes += e.size
+			removed = s.trimDisk()
+		}
+		s.mu.Unlock()
+		s.removeFiles(removed)
+	}
+}
+
+func (s *ContentStore) Put(content []byte) []byte {
+	h := sha256.Sum256(content)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.memory[h]; !ok {
+		content = append([]byte(nil), content...)
+	}
+	s.putMemory(h, content)
+	s.putDisk(h, content)
+	return h[:]
+}
+
+func (s *ContentStore) Get(byteHash []byte) ([]byte, bool) {
+	if len(byteHash) != sha256.Size {
+		return nil, false
+	}
+	h := hash(byteHash)
+	s.mu.Lock()
+	if elem, ok := s.memory[h]; ok {
+		s.memoryLRU.MoveToFront(elem)
+		s.stats.Hits++
+		s.mu.Unlock()
+		return elem.Value.(*entry).content, true
+	}
+	elem, ok := s.disk[h]
+	if !ok {
+		s.stats.Misses++
+		s.mu.Unlock()
+		return nil, false
+	}
+	s.mu.Unlock()
+	/* the file may be trimmed meanwhile, only an entry still indexed counts */
+	content, err := os.ReadFile(s.path(h))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	indexed := s.disk[h] == elem
+	if err == nil && sha256.Sum256(content) == h {
+		if indexed {
+			s.diskLRU.MoveToFront(elem)
+		}
+		s.putMemory(h, content)
+		s.stats.Hits++
+		return content, true
+	}
+	if indexed {
+		if err != nil {
+			log.Println(err)
+		}
+		e := s.diskLRU.Remove(elem).(*entry)
+		delete(s.disk, h)
+		s.stats.DiskEntries--
+		s.stats.DiskBytes -= e.size
+	}
+	s.stats.Misses++
+	return nil, false
+}
+
+func (s *ContentStore) SetRemote(hashes [][]byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.remoteDisabled {
+		return
+	}
+	for _, byteHash := range hashes {
+		if len(byteHash) != sha256.Size {
+			continue
+		}
+		if len(s.remote) >= MAXREMOTEHASHES {
+			s.remote = make(map[hash]struct{})
+		}
+		s.remote[hash(byteHash)] = struct{}{}
+	}
+	s.stats.RemoteHashes = uint64(len(s.remote))
+}
+
+func (s *ContentStore) IsRemote(byteHash []byte) bool {
+	if len(byteHash) != sha256.Size {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.remote[hash(byteHash)]
+	return ok
+}
+
+/* the stub does not implement HasContent or lost what it reported */
+func (s *ContentStore) DisableRemote() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remoteDisabled = true
+	s.remote = make(map[hash]struct{})
+	s.stats.RemoteHashes = 0
+}
+
+func (s *ContentStore) RemoteEnabled() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.remoteDisabled
+}
+
+/* waits until the queued contents are on disk */
+func (s *ContentStore) Close() {
+	s.mu.Lock()
+	if s.writes == nil || s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.writes)
+	s.mu.Unlock()
+	<-s.done
+}
+
+func (s *ContentStore) Stats() store.Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/store"
+)
+
+func testContent(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 0x1000)
+}
+
+func TestContentStoreRemovesPartialWrites(t *testing.T) {
+	config := store.Config{Enabled: true, MaxMemory: 0x1000, Dir: t.TempDir(), MaxDisk: 0x2000}
+	partial := filepath.Join(config.Dir, "ab", "cdef.tmp")
+	if err := os.MkdirAll(filepath.Dir(partial), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(partial, testContent(1)[:0x10], 0600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewContentStore(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := os.Stat(partial); !os.IsNotExist(err) {
+		t.Fatalf("partial write is left: %v", err)
+	}
+	if stats := s.Stats(); stats.DiskEntries != 0 {
+		t.Fatalf("stats %s", stats)
+	}
+}
+
+func TestContentStoreWritesDisk(t *testing.T) {
+	config := store.Config{Enabled: true, MaxMemory: 0x1000, Dir: t.TempDir(), MaxDisk: 0x2000}
+	s, err := NewContentStore(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashes := [][]byte{s.Put(testContent(1)), s.Put(testContent(2)), s.Put(testContent(3))}
+	s.Close()
+	/* Put after Close keeps the content in memory only */
+	s.Put(testContent(4))
+	stats := s.Stats()
+	if stats.MemoryEntries != 1 || stats.DiskEntries != 2 || stats.DiskBytes != 0x2000 {
+		t.Fatalf("stats %s", stats)
+	}
+
+	reopened, err := NewContentStore(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	if _, ok := reopened.Get(hashes[0]); ok {
+		t.Fatal("a content trimmed from disk is found")
+	}
+	for i, h := range hashes[1:] {
+		content, ok := reopened.Get(h)
+		if !ok || !bytes.Equal(content, testContent(byte(i+2))) {
+			t.Fatalf("content %d is not read back from disk", i+2)
+		}
+	}
+}
+
+func TestContentStoreMemoryOnly(t *testing.T) {
+	s, err := NewContentStore(store.Config{Enabled: true, MaxMemory: 0x2000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	h := s.Put(testContent(1))
+	if content, ok := s.Get(h); !ok || !bytes.Equal(content, testContent(1)) {
+		t.Fatal("content is not found in memory")
+	}
+	if stats := s.Stats(); stats.DiskEntries != 0 || stats.Hits != 1 {
+		t.Fatalf("stats %s", stats)
+	}
+}
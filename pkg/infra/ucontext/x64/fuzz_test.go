@@ -0,0 +1,55 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"bytes"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/cpu"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/ucontext"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+	x64cpu "github.com/sigrpc/sigrpcd/pkg/infra/cpu/x64"
+)
+
+func FuzzUserContextDecode(f *testing.F) {
+	codec := NewCodec(x64cpu.NewCodec())
+	for _, size := range []uint32{0, cpu.XSTATEMINSIZE} {
+		f.Add(codec.Encode(&ucontext.UserContext{
+			CPU:         &cpu.CPU{X64: &x64.CPUState{}},
+			StackBottom: 0x7ffc0000,
+		}, size), size != 0)
+	}
+	f.Fuzz(func(t *testing.T, data []byte, xsave bool) {
+		size := uint32(0)
+		if xsave {
+			size = cpu.XSTATEMINSIZE
+		}
+		decoded, err := codec.Decode(bytes.NewReader(data), size)
+		if err != nil {
+			return
+		}
+		encoded := codec.Encode(decoded, size)
+		redecoded, err := codec.Decode(bytes.NewReader(encoded), size)
+		if err != nil {
+			t.Fatalf("decoding an encoded context: %v", err)
+		}
+		if decoded.StackBottom != redecoded.StackBottom || !proto.Equal(decoded.CPU.X64, redecoded.CPU.X64) {
+			t.Fatal("context changed through encoding")
+		}
+	})
+}
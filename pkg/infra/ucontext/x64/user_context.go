@@ -34,21 +34,28 @@ func NewCodec(cpucodec cpucodec.CPU) ucontextcodec.UserContext {
 	return &UserContextCodec{cpucodec}
 }
 
-func (h *UserContextCodec) Encode(ctx *ucontext.UserContext) []byte {
+func (h *UserContextCodec) Encode(ctx *ucontext.UserContext, xstateSize uint32) []byte {
 	byteCPU := h.CPU.Encode(&cpu.CPU{
 		X64: ctx.CPU.X64,
-	})
+	}, xstateSize)
 	byteStackBottom := make([]byte, unsafe.Sizeof(ctx.StackBottom))
 	binary.LittleEndian.PutUint64(byteStackBottom, ctx.StackBottom)
 
 	return append(byteCPU, byteStackBottom...)
 }
 
-func (h *UserContextCodec) Decode(reader io.Reader) *ucontext.UserContext {
+func (h *UserContextCodec) Decode(reader io.Reader, xstateSize uint32) (*ucontext.UserContext, error) {
 	ctx := ucontext.UserContext{}
-	ctx.CPU = h.CPU.Decode(reader)
-	if binary.Read(reader, binary.LittleEndian, &ctx.StackBottom) != nil {
-		return nil
+	cpu, err := h.CPU.Decode(reader, xstateSize)
+	if err != nil {
+		return nil, err
 	}
-	return &ctx
+	ctx.CPU = cpu
+	if err := binary.Read(reader, binary.LittleEndian, &ctx.StackBottom); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return &ctx, nil
 }
@@ -0,0 +1,72 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x64
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/cpu"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/ucontext"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+	x64cpu "github.com/sigrpc/sigrpcd/pkg/infra/cpu/x64"
+)
+
+func TestUserContextRoundTrip(t *testing.T) {
+	codec := NewCodec(x64cpu.NewCodec())
+	want := &ucontext.UserContext{
+		CPU: &cpu.CPU{X64: &x64.CPUState{
+			Gregs:  []uint64{1, 2, 3},
+			Fpregs: &x64.X64FPRegs{Mxcsr: 0x1f80},
+		}},
+		StackBottom: 0x7ffc00000000,
+	}
+	encoded := codec.Encode(want, 0)
+	if len(encoded) != x64cpu.CPUSIZE+8 {
+		t.Fatalf("encoded %d bytes", len(encoded))
+	}
+	got, err := codec.Decode(bytes.NewReader(encoded), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.StackBottom != want.StackBottom {
+		t.Fatalf("stack bottom %#x, want %#x", got.StackBottom, want.StackBottom)
+	}
+	if got.CPU.X64.Gregs[2] != 3 || got.CPU.X64.Fpregs.Mxcsr != 0x1f80 {
+		t.Fatalf("registers changed through encoding: %v", got.CPU.X64)
+	}
+	again, err := codec.Decode(bytes.NewReader(codec.Encode(got, 0)), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got.CPU.X64, again.CPU.X64) {
+		t.Fatal("decoded context changed through encoding")
+	}
+}
+
+func TestUserContextDecodeTruncated(t *testing.T) {
+	codec := NewCodec(x64cpu.NewCodec())
+	encoded := codec.Encode(&ucontext.UserContext{CPU: &cpu.CPU{X64: &x64.CPUState{}}}, 0)
+	for _, size := range []int{0, x64cpu.CPUSIZE - 1, x64cpu.CPUSIZE, x64cpu.CPUSIZE + 7} {
+		_, err := codec.Decode(bytes.NewReader(encoded[:size]), 0)
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("%d bytes: %v, want %v", size, err, io.ErrUnexpectedEOF)
+		}
+	}
+}
@@ -29,10 +29,10 @@ func NewCPUCodec(codec cpucodec.CPU) CPUCodec {
 	return CPUCodec{codec}
 }
 
-func (h *CPUCodec) Encode(cpu *cpu.CPU) []byte {
-	return h.CPU.Encode(cpu)
+func (h *CPUCodec) Encode(cpu *cpu.CPU, xstateSize uint32) []byte {
+	return h.CPU.Encode(cpu, xstateSize)
 }
 
-func (h *CPUCodec) Decode(reader io.Reader) *cpu.CPU {
-	return h.CPU.Decode(reader)
+func (h *CPUCodec) Decode(reader io.Reader, xstateSize uint32) (*cpu.CPU, error) {
+	return h.CPU.Decode(reader, xstateSize)
 }
@@ -15,90 +15,514 @@
 package usecase
 
 import (
-	"bytes"
+	"bufio"
 	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net"
+	"os"
+	"sync"
+	"time"
+	"unsafe"
 
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/cpu"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
 	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/page"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/peer"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/session"
 	grpcclient "github.com/sigrpc/sigrpcd/pkg/domain/repository/grpc"
+	memoryrepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/memory"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
 )
 
 type GRPCClient struct {
 	grpcclient.GRPCClient
 	*MsgCodec
+	Session  session.Session
+	Limits   limit.Limits
+	Shutdown *Shutdown
+	Peer     *peer.Peer
+	/* credentials of the peer could not be read, every request is refused with it */
+	PeerErr      error
+	Policy       *peer.Policy
+	PageCache    *PageCache
+	Prefetcher   *Prefetcher
+	Profiler     *Profiler
+	Mappings     *Mappings
+	Memory       *Memory
+	SharedMemory *SharedMemory
+	/* charged with everything the connection holds */
+	Budget      *limit.Budget
+	lastRPCType uint32
+	shared      *page.Shared
+	/* page contents of the request being served, released once its reply is written */
+	contents page.Contents
+	/* clients whose pages this connection put into the page cache */
+	cached map[string]bool
+	space  memoryrepository.AddressSpace
 }
 
-func NewGRPCClient(client grpcclient.GRPCClient, msgCodec *MsgCodec) *GRPCClient {
-	return &GRPCClient{client, msgCodec}
+func NewGRPCClient(client grpcclient.GRPCClient, msgCodec *MsgCodec, limits limit.Limits) *GRPCClient {
+	return &GRPCClient{
+		GRPCClient: client,
+		MsgCodec:   msgCodec,
+		Limits:     limits,
+	}
+}
+
+/* a message fails to decode either malformed or too large to hold */
+func decodeFailure(err error) error {
+	if errors.Is(err, limit.ErrMemoryExhausted) {
+		return msg.NewRPCError(msg.STATUSRESOURCEEXHAUSTED, err)
+	}
+	return msg.NewRPCError(msg.STATUSINVALIDARGUMENT, err)
+}
+
+/* replies from the stub are already received, charging them bounds what is held until they are written */
+func reservePages(budget *limit.Budget, pages []*x64.Page) error {
+	size := uint64(0)
+	for _, page := range pages {
+		size += uint64(unsafe.Sizeof(*page)) + uint64(len(page.Content))
+		for _, dirty := range page.Dirty {
+			size += uint64(unsafe.Sizeof(*dirty)) + uint64(len(dirty.Content))
+		}
+	}
+	if err := budget.Reserve(size); err != nil {
+		return msg.NewRPCError(msg.STATUSRESOURCEEXHAUSTED, err)
+	}
+	return nil
+}
+
+/* replies may refer to the contents of their request until they are written */
+func (c *GRPCClient) ReleaseContents() {
+	c.InvokeFuncCodec.Release(&c.contents)
+}
+
+func (c *GRPCClient) HasNext() bool {
+	return c.IsStreaming() || c.lastRPCType == msg.HELLO
+}
+
+func (c *GRPCClient) Hello(hello *msg.HelloMsg) (*msg.HelloMsg, error) {
+	if c.Session.Established {
+		return nil, msg.NewRPCError(msg.STATUSFAILEDPRECONDITION, errors.New("HELLO must be the first message"))
+	}
+	if hello.X64.Version < msg.MINPROTOCOLVERSION || hello.X64.Version > msg.PROTOCOLVERSION {
+		return nil, msg.NewRPCError(msg.STATUSFAILEDPRECONDITION, fmt.Errorf(
+			"protocol version %d is not supported (supported %d-%d)",
+			hello.X64.Version, msg.MINPROTOCOLVERSION, msg.PROTOCOLVERSION))
+	}
+	if hello.X64.Arch != c.GetArch() {
+		return nil, msg.NewRPCError(msg.STATUSFAILEDPRECONDITION, fmt.Errorf(
+			"architecture %d is not supported (supported %d)",
+			hello.X64.Arch, c.GetArch()))
+	}
+	/* huge pages are multiples of the base page size in powers of two */
+	pageSize := uint32(os.Getpagesize())
+	if hello.X64.PageSize < pageSize ||
+		hello.X64.PageSize&(hello.X64.PageSize-1) != 0 ||
+		hello.X64.PageSize > c.Limits.MaxPageContentSize {
+		return nil, msg.NewRPCError(msg.STATUSFAILEDPRECONDITION, fmt.Errorf(
+			"page size %d is not supported (supported powers of two in %d-%d)",
+			hello.X64.PageSize, pageSize, c.Limits.MaxPageContentSize))
+	}
+	hello.X64.Features &= msg.SUPPORTEDFEATURES
+	if hello.X64.Features&msg.FEATUREDIRECTMEMORY != 0 {
+		if c.Memory == nil {
+			hello.X64.Features &^= msg.FEATUREDIRECTMEMORY
+		} else if space, err := c.Memory.Open(c.Peer); err != nil {
+			log.Println(err)
+			hello.X64.Features &^= msg.FEATUREDIRECTMEMORY
+		} else {
+			c.space = space
+		}
+	}
+	if c.SharedMemory == nil {
+		hello.X64.Features &^= msg.FEATURESHAREDMEMORY
+	}
+	/* pulls are only answered with pages never asked for when the client expects them */
+	if c.Prefetcher == nil {
+		hello.X64.Features &^= msg.FEATUREPREFETCH
+	}
+	/* without a usable size the client falls back to the FXSAVE area */
+	if hello.X64.Features&msg.FEATUREXSAVE == 0 ||
+		hello.X64.XstateSize < cpu.XSTATEMINSIZE ||
+		hello.X64.XstateSize > cpu.XSTATEMAXSIZE {
+		hello.X64.Features &^= msg.FEATUREXSAVE
+		hello.X64.XstateSize = 0
+	}
+	c.Session = session.Session{
+		Version:     hello.X64.Version,
+		Arch:        hello.X64.Arch,
+		PageSize:    hello.X64.PageSize,
+		Features:    hello.X64.Features,
+		XStateSize:  hello.X64.XstateSize,
+		Legacy:      false,
+		Established: true,
+	}
+	c.SetFeatures(c.Session.Features)
+	return hello, nil
 }
 
 func (c *GRPCClient) LoadLib(loadlib *msg.LoadLibMsg) (*msg.LoadLibMsg, error) {
-	return c.GRPCClient.LoadLib(loadlib)
+	resp, err := c.GRPCClient.LoadLib(loadlib)
+	if err == nil && c.Profiler != nil {
+		c.Profiler.LoadLib(loadlib.X64.Header.ClientId, resp)
+	}
+	return resp, err
 }
 
 func (c *GRPCClient) InvokeFunc(invokeFunc *msg.InvokeFuncMsg) (*msg.InvokeFuncMsg, error) {
-	return c.GRPCClient.InvokeFunc(invokeFunc)
+	if c.prefetching() {
+		c.Prefetcher.Invoke(invokeFunc)
+	}
+	if c.Profiler != nil {
+		c.Profiler.Invoke(invokeFunc)
+	}
+	if c.Mappings != nil {
+		if err := c.Mappings.Invoke(c.Peer, invokeFunc); err != nil {
+			log.Println(err)
+		}
+	}
+	start := time.Now()
+	direct := c.Session.HasFeature(msg.FEATUREDIRECTMEMORY)
+	var resp *msg.InvokeFuncMsg
+	var err error
+	if direct {
+		resp, err = c.space.Invoke(invokeFunc, c.GRPCClient.InvokeFunc)
+	} else {
+		resp, err = c.GRPCClient.InvokeFunc(invokeFunc)
+	}
+	if c.Profiler != nil {
+		c.Profiler.Return(invokeFunc.X64.Header.ClientId, resp, time.Since(start))
+	}
+	if err == nil && c.PageCache != nil {
+		c.cacheClient(invokeFunc.X64.Header.ClientId)
+		if err := c.PageCache.Update(invokeFunc.X64.Header.ClientId, resp, c.fetchPage); err != nil {
+			return nil, err
+		}
+	}
+	/* pages written here are no longer returned, the client only resumes */
+	if err == nil && direct {
+		if err := c.space.WriteBack(resp); err != nil {
+			log.Println(err)
+		}
+	}
+	return resp, err
 }
 
 func (c *GRPCClient) PullPage(page *msg.PullPageMsg) (*msg.PullPageMsg, error) {
+	if c.Profiler == nil {
+		return c.prefetchPage(page)
+	}
+	start := time.Now()
+	resp, err := c.prefetchPage(page)
+	if err == nil {
+		c.Profiler.Pull(page.X64.Header.ClientId, resp, time.Since(start))
+	}
+	return resp, err
+}
+
+func (c *GRPCClient) prefetching() bool {
+	return c.Prefetcher != nil && c.Session.HasFeature(msg.FEATUREPREFETCH)
+}
+
+func (c *GRPCClient) prefetchPage(page *msg.PullPageMsg) (*msg.PullPageMsg, error) {
+	if !c.prefetching() {
+		return c.pullPage(page)
+	}
+	return c.Prefetcher.Pull(page, c.pullPage)
+}
+
+func (c *GRPCClient) pullPage(page *msg.PullPageMsg) (*msg.PullPageMsg, error) {
+	if c.PageCache == nil {
+		return c.fetchPage(page)
+	}
+	c.cacheClient(page.X64.Header.ClientId)
+	return c.PageCache.Pull(page, c.fetchPage)
+}
+
+func (c *GRPCClient) cacheClient(clientID string) {
+	if c.cached[clientID] {
+		return
+	}
+	if c.cached == nil {
+		c.cached = make(map[string]bool)
+	}
+	c.cached[clientID] = true
+	c.PageCache.Attach(clientID)
+}
+
+/*
+ * releases what a closed connection holds, the pages of a client
+ * are dropped when none of its connections is left
+ */
+func (c *GRPCClient) Close() {
+	for clientID := range c.cached {
+		c.PageCache.Detach(clientID)
+	}
+	c.cached = nil
+	if c.space != nil {
+		c.space.Close()
+		c.space = nil
+	}
+}
+
+/* every page the stub is asked for, prefetched or refetched ones too, carries its mapping */
+func (c *GRPCClient) fetchPage(page *msg.PullPageMsg) (*msg.PullPageMsg, error) {
+	if c.Mappings != nil {
+		if err := c.Mappings.Pull(c.Peer, page); err != nil {
+			log.Println(err)
+		}
+	}
 	return c.GRPCClient.PullPage(page)
 }
 
-func (c *GRPCClient) InvokeRPC(conn net.Conn) ([]byte, error) {
+func (c *GRPCClient) GetRPCStatus(err error) uint32 {
+	var rpcErr *msg.RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Status
+	}
+	return c.GRPCClient.GetRPCStatus(err)
+}
+
+func (c *GRPCClient) EncodeError(header *msg.RPCHeader, err error) []byte {
+	header.X64.Status = c.GetRPCStatus(err)
+	header.X64.ErrorMessage = err.Error()
+	return c.RPCHeaderCodec.EncodeError(header)
+}
+
+/*
+ * a client sending HELLO always learns why it is refused, later on only
+ * clients that negotiated error replies get them and legacy ones see the connection closed
+ */
+func (c *GRPCClient) repliesErrors(rpcType uint32) bool {
+	if rpcType == msg.HELLO {
+		return true
+	}
+	return !c.Session.Legacy && c.Session.HasFeature(msg.FEATUREERRORREPLY)
+}
+
+func (c *GRPCClient) errorReply(header *msg.RPCHeader, err error) net.Buffers {
+	if !c.repliesErrors(c.GetRPCType(header)) {
+		return nil
+	}
+	return net.Buffers{c.EncodeError(header, err)}
+}
+
+/* an error status of the stub is encoded as an error reply, which not every client parses */
+func (c *GRPCClient) stubError(rpcType uint32, header *x64.RPCHeader) error {
+	if header == nil || header.Status == msg.STATUSOK || c.repliesErrors(rpcType) {
+		return nil
+	}
+	return msg.NewRPCError(header.Status, errors.New(header.ErrorMessage))
+}
+
+func (c *GRPCClient) authorize(header *msg.RPCHeader) error {
+	if c.PeerErr != nil {
+		return c.PeerErr
+	}
+	if c.Policy != nil && !c.Policy.Allows(c.Peer) {
+		return msg.NewRPCError(msg.STATUSPERMISSIONDENIED, errors.New("peer is not allowed"))
+	}
+	if c.Peer != nil && header.Pid != uint32(c.Peer.ClientPid()) {
+		return msg.NewRPCError(msg.STATUSPERMISSIONDENIED, fmt.Errorf(
+			"pid %d in header does not match peer pid %d", header.Pid, c.Peer.ClientPid()))
+	}
+	c.BindPeer(header, c.Peer)
+	return nil
+}
+
+func (c *GRPCClient) InvokeRPC(conn net.Conn) (net.Buffers, error) {
 	header, err := c.RPCHeaderCodec.Decode(conn)
 	if err != nil {
 		return nil, err
 	}
-	payload := make([]byte, header.X64.PayloadSize)
-	readTotal := uint64(0)
-	for readTotal < header.X64.PayloadSize {
-		size, err := conn.Read(payload[readTotal:])
-		if err != nil && size == 0 {
-			return nil, err
+	if err := c.authorize(header); err != nil {
+		return c.errorReply(header, err), err
+	}
+	/* streams already started may run until the grace period is over */
+	if c.Shutdown != nil && c.Shutdown.Draining() && !c.IsStreaming() {
+		return c.errorReply(header, ErrShuttingDown), ErrShuttingDown
+	}
+	resp, err := c.invokeRPC(conn, header)
+	if err != nil && err != io.EOF {
+		if c.Shutdown != nil && c.Shutdown.Aborted() {
+			err = ErrShuttingDown
 		}
-		readTotal += uint64(size)
+		return c.errorReply(header, err), err
+	}
+	return resp, err
+}
+
+const PAYLOADREADERSIZE = 64 * 1024
+
+/* reads of a payload are buffered, pages are decoded as they arrive instead of after the whole payload */
+var payloadReaders = sync.Pool{
+	New: func() any {
+		return bufio.NewReaderSize(nil, PAYLOADREADERSIZE)
+	},
+}
+
+func (c *GRPCClient) invokeRPC(conn net.Conn, header *msg.RPCHeader) (net.Buffers, error) {
+	if header.X64.PayloadSize > c.Limits.MaxFrameSize {
+		return nil, msg.NewRPCError(msg.STATUSRESOURCEEXHAUSTED, fmt.Errorf(
+			"payload size %d exceeds limit %d", header.X64.PayloadSize, c.Limits.MaxFrameSize))
+	}
+	/*
+	 * the raw payload is never held, only what it decodes to,
+	 * which is charged as it is allocated and never exceeds the frame size
+	 */
+	budget := limit.NewBudget(c.Limits.MaxFrameSize, c.Budget)
+	defer budget.Close()
+	replyBudget := limit.NewBudget(c.Limits.MaxConnMemory, c.Budget)
+	defer replyBudget.Close()
+	payload := io.LimitReader(conn, int64(header.X64.PayloadSize))
+	reader := payloadReaders.Get().(*bufio.Reader)
+	reader.Reset(payload)
+	defer func() {
+		reader.Reset(nil)
+		payloadReaders.Put(reader)
+	}()
+	resp, err := c.dispatch(conn, reader, header, budget, replyBudget)
+	if err != nil {
+		return resp, err
+	}
+	/* a frame boundary is kept for the next message even if the decoder left bytes */
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *GRPCClient) dispatch(
+	conn net.Conn,
+	reader io.Reader,
+	header *msg.RPCHeader,
+	budget *limit.Budget,
+	replyBudget *limit.Budget) (net.Buffers, error) {
+	rpcType := c.GetRPCType(header)
+	if rpcType != msg.HELLO && !c.Session.Established {
+		c.Session.PageSize = uint32(os.Getpagesize())
+		c.Session.Legacy = true
+		c.Session.Established = true
 	}
-	reader := bytes.NewReader(payload)
-	switch c.GetRPCType(header) {
+	header.Layout = c.Session.PageLayout()
+	if c.shared != nil {
+		header.Layout.Shared = c.shared
+	}
+	header.Layout.Budget = budget
+	header.Layout.Contents = &c.contents
+	c.lastRPCType = rpcType
+	switch rpcType {
 	case msg.LOADLIB:
 		if c.IsStreaming() {
-			return nil, errors.New("LOADLIB does not support streaming")
+			return nil, msg.NewRPCError(msg.STATUSFAILEDPRECONDITION, errors.New("LOADLIB does not support streaming"))
+		}
+		/* names and symbols take about what they take on the wire */
+		if err := budget.Reserve(header.X64.PayloadSize); err != nil {
+			return nil, decodeFailure(err)
 		}
 		req, err := c.LoadLibCodec.Decode(reader, header)
 		if err != nil {
-			return nil, err
+			return nil, decodeFailure(err)
 		}
 		resp, err := c.LoadLib(req)
 		if err != nil {
 			return nil, err
 		}
-		return c.LoadLibCodec.Encode(resp), nil
+		if err := c.stubError(rpcType, resp.X64.Header); err != nil {
+			return nil, err
+		}
+		return net.Buffers{c.LoadLibCodec.Encode(resp)}, nil
 	case msg.INVOKEFUNC:
 		req, err := c.InvokeFuncCodec.Decode(reader, header)
 		if err != nil {
-			return nil, err
+			return nil, decodeFailure(err)
+		}
+		/* pages are sent on to the stub in other forms, keep what the client has */
+		var bases map[uint64][]byte
+		if c.Session.HasFeature(msg.FEATUREDIRTYRANGES) {
+			bases = c.InvokeFuncCodec.Contents(req)
 		}
 		resp, err := c.InvokeFunc(req)
 		if err != nil {
 			log.Println(err)
 			return nil, err
 		}
-		return c.InvokeFuncCodec.Encode(resp), err
+		if err := c.stubError(rpcType, resp.X64.Header); err != nil {
+			return nil, err
+		}
+		if err := reservePages(replyBudget, resp.X64.Page); err != nil {
+			return nil, err
+		}
+		if bases != nil {
+			c.InvokeFuncCodec.Diff(resp, bases)
+		}
+		if c.Session.HasFeature(msg.FEATUREPAGEELISION) {
+			c.InvokeFuncCodec.Elide(resp)
+		}
+		return c.InvokeFuncCodec.Encode(resp, header.Layout), err
 	case msg.PULLPAGE:
 		if c.IsStreaming() {
-			return nil, errors.New("PULLPAGE does not support streaming")
+			return nil, msg.NewRPCError(msg.STATUSFAILEDPRECONDITION, errors.New("PULLPAGE does not support streaming"))
 		}
 		req, err := c.PullPageCodec.Decode(reader, header)
 		if err != nil {
-			return nil, err
+			return nil, decodeFailure(err)
 		}
 		resp, err := c.PullPage(req)
 		if err != nil {
 			log.Println(err)
 			return nil, err
 		}
-		return c.PullPageCodec.Encode(resp), nil
+		if err := c.stubError(rpcType, resp.X64.Header); err != nil {
+			return nil, err
+		}
+		if err := reservePages(replyBudget, resp.X64.Page); err != nil {
+			return nil, err
+		}
+		resp.X64.InvokefuncId = req.X64.InvokefuncId
+		if c.Session.HasFeature(msg.FEATUREPAGEELISION) {
+			c.PullPageCodec.Elide(resp)
+		}
+		return c.PullPageCodec.Encode(resp, header.Layout), nil
+	case msg.HELLO:
+		/* the memfd is only accepted along with HELLO */
+		if c.SharedMemory != nil {
+			defer c.SharedMemory.Refuse(conn)
+		}
+		if err := budget.Reserve(header.X64.PayloadSize); err != nil {
+			return nil, decodeFailure(err)
+		}
+		req, err := c.HelloCodec.Decode(reader, header)
+		if err != nil {
+			return nil, decodeFailure(err)
+		}
+		resp, err := c.Hello(req)
+		if err != nil {
+			return nil, err
+		}
+		/* the memfd is passed along with HELLO, the feature is dropped if it is unusable */
+		if c.Session.HasFeature(msg.FEATURESHAREDMEMORY) {
+			shared, err := c.SharedMemory.Map(conn)
+			if err == nil {
+				/* mapped until the connection is closed */
+				if err = c.Budget.Reserve(uint64(len(shared.Region))); err != nil {
+					c.SharedMemory.Unmap(conn)
+				}
+			}
+			if err != nil {
+				log.Println(err)
+				c.Session.Features &^= msg.FEATURESHAREDMEMORY
+				c.SetFeatures(c.Session.Features)
+				resp.X64.Features = c.Session.Features
+			} else {
+				c.shared = shared
+			}
+		}
+		return net.Buffers{c.HelloCodec.Encode(resp)}, nil
 	}
-	return nil, errors.New("unsupported message")
+	return nil, msg.NewRPCError(msg.STATUSUNIMPLEMENTED, errors.New("unsupported message"))
 }
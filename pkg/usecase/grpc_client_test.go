@@ -0,0 +1,351 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usecase_test
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/cache"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/page"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/prefetch"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+	x64cache "github.com/sigrpc/sigrpcd/pkg/infra/cache/x64"
+	x64msg "github.com/sigrpc/sigrpcd/pkg/infra/msg/x64"
+	x64prefetch "github.com/sigrpc/sigrpcd/pkg/infra/prefetch/x64"
+	"github.com/sigrpc/sigrpcd/pkg/usecase"
+)
+
+/* pages of an invocation in the benchmarks */
+const BENCHPAGES = 256
+
+/* a stub answering every pulled page with its address as content */
+type pagingClient struct {
+	idleClient
+	pulls *int
+}
+
+func (c pagingClient) PullPage(req *msg.PullPageMsg) (*msg.PullPageMsg, error) {
+	if c.pulls != nil {
+		*c.pulls++
+	}
+	resp := &msg.PullPageMsg{X64: &x64.PullPageMsg{Header: req.X64.Header}}
+	for _, page := range req.X64.Page {
+		resp.X64.Page = append(resp.X64.Page, &x64.Page{
+			Address:         page.Address,
+			RuntimeRevision: page.RuntimeRevision,
+			Length:          page.Length,
+			Content:         []byte{byte(page.Address >> 12)},
+		})
+	}
+	return resp, nil
+}
+
+func TestPrefetchNeedsFeature(t *testing.T) {
+	pageSize := uint64(os.Getpagesize())
+	tests := []struct {
+		name     string
+		features uint64
+		pages    int
+	}{
+		{"without prefetch", msg.FEATUREERRORREPLY, 1},
+		{"with prefetch", msg.FEATUREERRORREPLY | msg.FEATUREPREFETCH, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := prefetch.NewDefaultConfig()
+			config.Policy = prefetch.POLICYSEQUENTIAL
+			config.Window = 2
+			client := usecase.NewGRPCClient(pagingClient{}, nil, limit.NewDefaultLimits())
+			client.Prefetcher = usecase.NewPrefetcher(x64prefetch.NewPrefetcher(config, pageSize))
+			hello, err := client.Hello(&msg.HelloMsg{
+				X64: &x64.HelloMsg{
+					Header:   &x64.RPCHeader{MsgType: msg.HELLO, ClientId: "test"},
+					Version:  msg.PROTOCOLVERSION,
+					Arch:     msg.ARCHX64,
+					PageSize: uint32(pageSize),
+					Features: tt.features,
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hello.X64.Features != tt.features {
+				t.Fatalf("negotiated features %#x, want %#x", hello.X64.Features, tt.features)
+			}
+			resp, err := client.PullPage(&msg.PullPageMsg{
+				X64: &x64.PullPageMsg{
+					Header: &x64.RPCHeader{MsgType: msg.PULLPAGE, ClientId: "test"},
+					Page:   []*x64.Page{{Address: 0x10000, RuntimeRevision: 1}},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.X64.Page) != tt.pages {
+				t.Fatalf("got %d pages, want %d", len(resp.X64.Page), tt.pages)
+			}
+		})
+	}
+}
+
+func TestPrefetchWithoutPrefetcher(t *testing.T) {
+	client := usecase.NewGRPCClient(pagingClient{}, nil, limit.NewDefaultLimits())
+	hello, err := client.Hello(&msg.HelloMsg{
+		X64: &x64.HelloMsg{
+			Header:   &x64.RPCHeader{MsgType: msg.HELLO, ClientId: "test"},
+			Version:  msg.PROTOCOLVERSION,
+			Arch:     msg.ARCHX64,
+			PageSize: uint32(os.Getpagesize()),
+			Features: msg.FEATUREPREFETCH,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hello.X64.Features&msg.FEATUREPREFETCH != 0 {
+		t.Fatal("prefetch is negotiated without a prefetcher")
+	}
+}
+
+/* PULLPAGE connections close after one message while the stream of the same client stays open */
+func TestPageCacheSharedByConnections(t *testing.T) {
+	pulls := 0
+	config := cache.NewDefaultConfig()
+	config.Enabled = true
+	pageCache := usecase.NewPageCache(x64cache.NewPageCache(config, nil))
+	connect := func() *usecase.GRPCClient {
+		client := usecase.NewGRPCClient(pagingClient{pulls: &pulls}, nil, limit.NewDefaultLimits())
+		client.PageCache = pageCache
+		return client
+	}
+	pull := func(client *usecase.GRPCClient) {
+		t.Helper()
+		resp, err := client.PullPage(&msg.PullPageMsg{
+			X64: &x64.PullPageMsg{
+				Header: &x64.RPCHeader{MsgType: msg.PULLPAGE, ClientId: "test"},
+				Page:   []*x64.Page{{Address: 0x10000, RuntimeRevision: 1, Length: 1}},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.X64.Page) != 1 {
+			t.Fatalf("got %d pages, want 1", len(resp.X64.Page))
+		}
+	}
+	stream := connect()
+	pull(stream)
+	for range 3 {
+		conn := connect()
+		pull(conn)
+		conn.Close()
+	}
+	if stats := pageCache.Stats(); pulls != 1 || stats.Hits != 3 {
+		t.Fatalf("%d pulls reached the stub, stats %s", pulls, stats)
+	}
+	stream.Close()
+	if stats := pageCache.Stats(); stats.Clients != 0 || stats.Pages != 0 {
+		t.Fatalf("stats %s after the last connection, want none", stats)
+	}
+}
+
+/* a stub returning every page it is invoked with */
+type echoClient struct {
+	idleClient
+}
+
+func (echoClient) InvokeFunc(req *msg.InvokeFuncMsg) (*msg.InvokeFuncMsg, error) {
+	return &msg.InvokeFuncMsg{X64: &x64.InvokeFuncMsg{
+		Header:       req.X64.Header,
+		InvokefuncId: req.X64.InvokefuncId,
+		RespId:       1,
+		Ctx:          req.X64.Ctx,
+		Page:         req.X64.Page,
+	}}, nil
+}
+
+type frameConn struct {
+	net.Conn
+	reader io.Reader
+}
+
+func (c frameConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+func benchLayout() page.Layout {
+	return page.Layout{PageSize: uint64(os.Getpagesize())}
+}
+
+/* an INVOKEFUNC frame of a legacy client carrying BENCHPAGES full pages */
+func benchInvokeFunc(b *testing.B, codec *usecase.MsgCodec) []byte {
+	b.Helper()
+	pageSize := uint64(os.Getpagesize())
+	pages := make([]*x64.Page, BENCHPAGES)
+	for i := range pages {
+		pages[i] = &x64.Page{
+			Address:         uint64(i+1) * pageSize,
+			RuntimeRevision: 1,
+			ContentSize:     uint32(pageSize),
+			Content:         bytes.Repeat([]byte{byte(i)}, int(pageSize)),
+		}
+	}
+	return bytes.Join(codec.InvokeFuncCodec.Encode(&msg.InvokeFuncMsg{X64: &x64.InvokeFuncMsg{
+		Header:       &x64.RPCHeader{MsgType: msg.INVOKEFUNC, ClientId: "bench-1"},
+		InvokefuncId: 1,
+		Ctx:          &x64.UserContext{Cpu: &x64.CPUState{}},
+		Page:         pages,
+	}}, benchLayout()), nil)
+}
+
+func newBenchCodec(b *testing.B) (*usecase.MsgCodec, limit.Limits) {
+	b.Helper()
+	limits := limit.NewDefaultLimits()
+	codec, err := x64msg.NewX64MsgCodec(limits)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return codec, limits
+}
+
+/*
+ * streaming decodes pages into pooled buffers as the payload is read and writes the reply with writev,
+ * buffered reads the whole payload first and joins the reply as InvokeRPC did before
+ */
+func BenchmarkInvokeRPC(b *testing.B) {
+	codec, limits := newBenchCodec(b)
+	frame := benchInvokeFunc(b, codec)
+	b.Run("streaming", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(frame)))
+		for i := 0; i < b.N; i++ {
+			client := usecase.NewGRPCClient(echoClient{}, codec, limits)
+			resp, err := client.InvokeRPC(frameConn{reader: bytes.NewReader(frame)})
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := resp.WriteTo(io.Discard); err != nil {
+				b.Fatal(err)
+			}
+			client.ReleaseContents()
+		}
+	})
+	b.Run("buffered", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(frame)))
+		for i := 0; i < b.N; i++ {
+			conn := frameConn{reader: bytes.NewReader(frame)}
+			header, err := codec.RPCHeaderCodec.Decode(conn)
+			if err != nil {
+				b.Fatal(err)
+			}
+			payload := make([]byte, header.X64.PayloadSize)
+			if _, err := io.ReadFull(conn, payload); err != nil {
+				b.Fatal(err)
+			}
+			header.Layout = benchLayout()
+			req, err := codec.InvokeFuncCodec.Decode(bytes.NewReader(payload), header)
+			if err != nil {
+				b.Fatal(err)
+			}
+			resp, err := echoClient{}.InvokeFunc(req)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := io.Discard.Write(bytes.Join(codec.InvokeFuncCodec.Encode(resp, header.Layout), nil)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+/* a connected pair of unix sockets whose reading end is drained until the benchmark ends */
+func socketPair(b *testing.B) net.Conn {
+	b.Helper()
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	conns := make([]net.Conn, len(fds))
+	for i, fd := range fds {
+		file := os.NewFile(uintptr(fd), "socketpair")
+		conns[i], err = net.FileConn(file)
+		file.Close()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	drained := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, conns[1])
+		close(drained)
+	}()
+	b.Cleanup(func() {
+		conns[0].Close()
+		<-drained
+		conns[1].Close()
+	})
+	return conns[0]
+}
+
+/* the reply alone, page contents are referenced by writev instead of copied into one frame */
+func BenchmarkInvokeFuncReply(b *testing.B) {
+	codec, _ := newBenchCodec(b)
+	frame := benchInvokeFunc(b, codec)
+	conn := frameConn{reader: bytes.NewReader(frame)}
+	header, err := codec.RPCHeaderCodec.Decode(conn)
+	if err != nil {
+		b.Fatal(err)
+	}
+	header.Layout = benchLayout()
+	req, err := codec.InvokeFuncCodec.Decode(conn, header)
+	if err != nil {
+		b.Fatal(err)
+	}
+	resp, err := echoClient{}.InvokeFunc(req)
+	if err != nil {
+		b.Fatal(err)
+	}
+	/* io.Discard takes net.Buffers one by one, a socket takes them in one writev */
+	b.Run("writev", func(b *testing.B) {
+		conn := socketPair(b)
+		b.ReportAllocs()
+		b.SetBytes(int64(len(frame)))
+		for i := 0; i < b.N; i++ {
+			reply := codec.InvokeFuncCodec.Encode(resp, header.Layout)
+			if _, err := reply.WriteTo(conn); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("joined", func(b *testing.B) {
+		conn := socketPair(b)
+		b.ReportAllocs()
+		b.SetBytes(int64(len(frame)))
+		for i := 0; i < b.N; i++ {
+			reply := bytes.Join(codec.InvokeFuncCodec.Encode(resp, header.Layout), nil)
+			if _, err := conn.Write(reply); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -16,8 +16,10 @@ package usecase
 
 import (
 	"io"
+	"net"
 
 	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/page"
 	msgcodec "github.com/sigrpc/sigrpcd/pkg/domain/repository/msg"
 )
 
@@ -29,10 +31,26 @@ func NewInvokeFuncCodec(codec msgcodec.InvokeFunc) InvokeFuncCodec {
 	return InvokeFuncCodec{codec}
 }
 
-func (h *InvokeFuncCodec) Encode(InvokeFunc *msg.InvokeFuncMsg) []byte {
-	return h.InvokeFunc.Encode(InvokeFunc)
+func (h *InvokeFuncCodec) Encode(InvokeFunc *msg.InvokeFuncMsg, layout page.Layout) net.Buffers {
+	return h.InvokeFunc.Encode(InvokeFunc, layout)
 }
 
 func (h *InvokeFuncCodec) Decode(reader io.Reader, header *msg.RPCHeader) (*msg.InvokeFuncMsg, error) {
 	return h.InvokeFunc.Decode(reader, header)
 }
+
+func (h *InvokeFuncCodec) Elide(invokeFunc *msg.InvokeFuncMsg) {
+	h.InvokeFunc.Elide(invokeFunc)
+}
+
+func (h *InvokeFuncCodec) Contents(invokeFunc *msg.InvokeFuncMsg) map[uint64][]byte {
+	return h.InvokeFunc.Contents(invokeFunc)
+}
+
+func (h *InvokeFuncCodec) Diff(invokeFunc *msg.InvokeFuncMsg, bases map[uint64][]byte) {
+	h.InvokeFunc.Diff(invokeFunc, bases)
+}
+
+func (h *InvokeFuncCodec) Release(contents *page.Contents) {
+	h.InvokeFunc.Release(contents)
+}
@@ -19,4 +19,5 @@ type MsgCodec struct {
 	LoadLibCodec
 	InvokeFuncCodec
 	PullPageCodec
+	HelloCodec
 }
@@ -16,6 +16,7 @@ package usecase
 
 import (
 	"io"
+	"net"
 
 	"github.com/sigrpc/sigrpcd/pkg/domain/model/page"
 	pagecodec "github.com/sigrpc/sigrpcd/pkg/domain/repository/page"
@@ -29,10 +30,10 @@ func NewPageCodec(codec pagecodec.Page) PageCodec {
 	return PageCodec{codec}
 }
 
-func (h *PageCodec) Encode(page *page.Page) []byte {
-	return h.Page.Encode(page)
+func (h *PageCodec) Encode(p *page.Page, layout page.Layout) net.Buffers {
+	return h.Page.Encode(p, layout)
 }
 
-func (h *PageCodec) Decode(reader io.Reader) *page.Page {
-	return h.Page.Decode(reader)
+func (h *PageCodec) Decode(reader io.Reader, layout page.Layout) (*page.Page, error) {
+	return h.Page.Decode(reader, layout)
 }
@@ -0,0 +1,58 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usecase
+
+import (
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/cache"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	cacherepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/cache"
+)
+
+type PageCache struct {
+	cacherepository.PageCache
+}
+
+func NewPageCache(pageCache cacherepository.PageCache) *PageCache {
+	return &PageCache{pageCache}
+}
+
+func (c *PageCache) Pull(
+	req *msg.PullPageMsg,
+	fetch func(*msg.PullPageMsg) (*msg.PullPageMsg, error)) (*msg.PullPageMsg, error) {
+	return c.PageCache.Pull(req, fetch)
+}
+
+func (c *PageCache) Update(
+	clientID string,
+	invokeFunc *msg.InvokeFuncMsg,
+	fetch func(*msg.PullPageMsg) (*msg.PullPageMsg, error)) error {
+	return c.PageCache.Update(clientID, invokeFunc, fetch)
+}
+
+func (c *PageCache) Base(clientID string, address uint64, revision uint64, length uint64) ([]byte, bool) {
+	return c.PageCache.Base(clientID, address, revision, length)
+}
+
+func (c *PageCache) Attach(clientID string) {
+	c.PageCache.Attach(clientID)
+}
+
+func (c *PageCache) Detach(clientID string) {
+	c.PageCache.Detach(clientID)
+}
+
+func (c *PageCache) Stats() cache.Stats {
+	return c.PageCache.Stats()
+}
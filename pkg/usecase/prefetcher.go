@@ -0,0 +1,43 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usecase
+
+import (
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/prefetch"
+	prefetchrepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/prefetch"
+)
+
+type Prefetcher struct {
+	prefetchrepository.Prefetcher
+}
+
+func NewPrefetcher(prefetcher prefetchrepository.Prefetcher) *Prefetcher {
+	return &Prefetcher{prefetcher}
+}
+
+func (p *Prefetcher) Invoke(invokeFunc *msg.InvokeFuncMsg) {
+	p.Prefetcher.Invoke(invokeFunc)
+}
+
+func (p *Prefetcher) Pull(
+	req *msg.PullPageMsg,
+	fetch func(*msg.PullPageMsg) (*msg.PullPageMsg, error)) (*msg.PullPageMsg, error) {
+	return p.Prefetcher.Pull(req, fetch)
+}
+
+func (p *Prefetcher) Stats() prefetch.Stats {
+	return p.Prefetcher.Stats()
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usecase
+
+import (
+	"time"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/profile"
+	profilerepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/profile"
+)
+
+type Profiler struct {
+	profilerepository.Profiler
+}
+
+func NewProfiler(profiler profilerepository.Profiler) *Profiler {
+	return &Profiler{profiler}
+}
+
+func (p *Profiler) LoadLib(clientID string, loadlib *msg.LoadLibMsg) {
+	p.Profiler.LoadLib(clientID, loadlib)
+}
+
+func (p *Profiler) Invoke(invokeFunc *msg.InvokeFuncMsg) {
+	p.Profiler.Invoke(invokeFunc)
+}
+
+func (p *Profiler) Return(clientID string, invokeFunc *msg.InvokeFuncMsg, elapsed time.Duration) {
+	p.Profiler.Return(clientID, invokeFunc, elapsed)
+}
+
+func (p *Profiler) Pull(clientID string, pullPage *msg.PullPageMsg, elapsed time.Duration) {
+	p.Profiler.Pull(clientID, pullPage, elapsed)
+}
+
+func (p *Profiler) Snapshot() *profile.Profile {
+	return p.Profiler.Snapshot()
+}
@@ -16,8 +16,10 @@ package usecase
 
 import (
 	"io"
+	"net"
 
 	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/page"
 	msgcodec "github.com/sigrpc/sigrpcd/pkg/domain/repository/msg"
 )
 
@@ -29,10 +31,14 @@ func NewPullPageCodec(codec msgcodec.PullPage) PullPageCodec {
 	return PullPageCodec{codec}
 }
 
-func (h *PullPageCodec) Encode(Page *msg.PullPageMsg) []byte {
-	return h.PullPage.Encode(Page)
+func (h *PullPageCodec) Encode(Page *msg.PullPageMsg, layout page.Layout) net.Buffers {
+	return h.PullPage.Encode(Page, layout)
 }
 
 func (h *PullPageCodec) Decode(reader io.Reader, header *msg.RPCHeader) (*msg.PullPageMsg, error) {
 	return h.PullPage.Decode(reader, header)
 }
+
+func (h *PullPageCodec) Elide(pullPage *msg.PullPageMsg) {
+	h.PullPage.Elide(pullPage)
+}
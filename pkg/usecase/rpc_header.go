@@ -18,6 +18,7 @@ import (
 	"net"
 
 	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/peer"
 	msgcodec "github.com/sigrpc/sigrpcd/pkg/domain/repository/msg"
 )
 
@@ -33,6 +34,14 @@ func (h *RPCHeaderCodec) Encode(header *msg.RPCHeader) []byte {
 	return h.RPCHeader.Encode(header)
 }
 
+func (h *RPCHeaderCodec) EncodeError(header *msg.RPCHeader) []byte {
+	return h.RPCHeader.EncodeError(header)
+}
+
 func (h *RPCHeaderCodec) Decode(conn net.Conn) (*msg.RPCHeader, error) {
 	return h.RPCHeader.Decode(conn)
 }
+
+func (h *RPCHeaderCodec) BindPeer(header *msg.RPCHeader, peer *peer.Peer) {
+	h.RPCHeader.BindPeer(header, peer)
+}
@@ -0,0 +1,231 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/peer"
+	grpcclient "github.com/sigrpc/sigrpcd/pkg/domain/repository/grpc"
+	peerrepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/peer"
+)
+
+var ErrShuttingDown = msg.NewRPCError(msg.STATUSUNAVAILABLE, errors.New("sigrpcd is shutting down"))
+
+type Shutdown struct {
+	draining atomic.Bool
+	ctx      context.Context
+	abort    context.CancelFunc
+}
+
+func newShutdown() *Shutdown {
+	ctx, abort := context.WithCancel(context.Background())
+	return &Shutdown{
+		ctx:   ctx,
+		abort: abort,
+	}
+}
+
+func (s *Shutdown) Draining() bool {
+	return s.draining.Load()
+}
+
+func (s *Shutdown) Aborted() bool {
+	return s.ctx.Err() != nil
+}
+
+type Server struct {
+	newClient    func(context.Context, *peer.Peer) grpcclient.GRPCClient
+	credentials  peerrepository.Credentials
+	policy       peer.Policy
+	pageCache    *PageCache
+	prefetcher   *Prefetcher
+	profiler     *Profiler
+	mappings     *Mappings
+	memory       *Memory
+	sharedMemory *SharedMemory
+	codec        *MsgCodec
+	limits       limit.Limits
+	timeout      time.Duration
+	budget       *limit.Budget
+	shutdown     *Shutdown
+	mu           sync.Mutex
+	listeners    map[net.Listener]struct{}
+	conns        map[net.Conn]struct{}
+	wg           sync.WaitGroup
+}
+
+func NewServer(
+	newClient func(context.Context, *peer.Peer) grpcclient.GRPCClient,
+	credentials peerrepository.Credentials,
+	policy peer.Policy,
+	pageCache *PageCache,
+	prefetcher *Prefetcher,
+	profiler *Profiler,
+	mappings *Mappings,
+	memory *Memory,
+	sharedMemory *SharedMemory,
+	codec *MsgCodec,
+	limits limit.Limits,
+	timeout time.Duration) *Server {
+	return &Server{
+		newClient:    newClient,
+		credentials:  credentials,
+		policy:       policy,
+		pageCache:    pageCache,
+		prefetcher:   prefetcher,
+		profiler:     profiler,
+		mappings:     mappings,
+		memory:       memory,
+		sharedMemory: sharedMemory,
+		codec:        codec,
+		limits:       limits,
+		timeout:      timeout,
+		budget:       limit.NewBudget(limits.MaxMemory, nil),
+		shutdown:     newShutdown(),
+		listeners:    make(map[net.Listener]struct{}),
+		conns:        make(map[net.Conn]struct{}),
+	}
+}
+
+func (s *Server) Serve(sock net.Listener) error {
+	s.mu.Lock()
+	if s.shutdown.Draining() {
+		s.mu.Unlock()
+		return net.ErrClosed
+	}
+	s.listeners[sock] = struct{}{}
+	s.mu.Unlock()
+	for {
+		conn, err := sock.Accept()
+		if err != nil {
+			if s.shutdown.Draining() || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			log.Println(err)
+			continue
+		}
+		s.mu.Lock()
+		if s.shutdown.Draining() {
+			s.mu.Unlock()
+			conn.Close()
+			continue
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+		go s.serveConn(conn)
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+	defer conn.Close()
+	/* a peer without credentials is still told why it is refused */
+	peer, peerErr := s.credentials.Get(conn)
+	if peerErr != nil {
+		log.Println(peerErr)
+	}
+	/* the conns entry stays the socket itself, only reads go through the wrapper */
+	rpcConn := conn
+	if s.sharedMemory != nil {
+		rpcConn = s.sharedMemory.Accept(conn)
+		defer rpcConn.Close()
+	}
+	ctx, cancel := context.WithTimeout(s.shutdown.ctx, s.timeout)
+	defer cancel()
+	sigRPCClient := NewGRPCClient(s.newClient(ctx, peer), s.codec, s.limits)
+	sigRPCClient.Shutdown = s.shutdown
+	sigRPCClient.Peer = peer
+	sigRPCClient.PeerErr = peerErr
+	sigRPCClient.Policy = &s.policy
+	sigRPCClient.PageCache = s.pageCache
+	defer sigRPCClient.Close()
+	sigRPCClient.Prefetcher = s.prefetcher
+	sigRPCClient.Profiler = s.profiler
+	sigRPCClient.Mappings = s.mappings
+	sigRPCClient.Memory = s.memory
+	sigRPCClient.SharedMemory = s.sharedMemory
+	sigRPCClient.Budget = limit.NewBudget(s.limits.MaxConnMemory, s.budget)
+	defer sigRPCClient.Budget.Close()
+	for {
+		resp, err := sigRPCClient.InvokeRPC(rpcConn)
+		/* the client closed the connection between messages */
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			log.Println(err)
+			if resp != nil {
+				resp.WriteTo(conn)
+			}
+			return
+		}
+		_, err = resp.WriteTo(conn)
+		sigRPCClient.ReleaseContents()
+		if err != nil {
+			return
+		}
+		if !sigRPCClient.HasNext() {
+			return
+		}
+	}
+}
+
+/* in-flight requests may finish until ctx is done, then the rest is aborted */
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.shutdown.draining.Store(true)
+	for sock := range s.listeners {
+		if err := sock.Close(); err != nil {
+			log.Println(err)
+		}
+	}
+	s.listeners = make(map[net.Listener]struct{})
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+	s.shutdown.abort()
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.SetReadDeadline(time.Now())
+	}
+	s.mu.Unlock()
+	<-done
+	return ctx.Err()
+}
@@ -0,0 +1,364 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/limit"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/msg"
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/peer"
+	grpcclient "github.com/sigrpc/sigrpcd/pkg/domain/repository/grpc"
+	peerrepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/peer"
+	"github.com/sigrpc/sigrpcd/pkg/grpc/x64"
+	x64msg "github.com/sigrpc/sigrpcd/pkg/infra/msg/x64"
+	"github.com/sigrpc/sigrpcd/pkg/usecase"
+)
+
+type anonymousCredentials struct{}
+
+func (anonymousCredentials) Get(net.Conn) (*peer.Peer, error) {
+	return nil, nil
+}
+
+/* a stub that is never reached, the connections under test only say HELLO */
+type idleClient struct{}
+
+func (idleClient) LoadLib(*msg.LoadLibMsg) (*msg.LoadLibMsg, error) { return nil, os.ErrInvalid }
+func (idleClient) InvokeFunc(*msg.InvokeFuncMsg) (*msg.InvokeFuncMsg, error) {
+	return nil, os.ErrInvalid
+}
+func (idleClient) PullPage(*msg.PullPageMsg) (*msg.PullPageMsg, error) { return nil, os.ErrInvalid }
+func (idleClient) GetRPCType(header *msg.RPCHeader) uint32             { return header.X64.MsgType }
+func (idleClient) GetRPCStatus(error) uint32                           { return msg.STATUSINTERNAL }
+func (idleClient) GetArch() uint32                                     { return msg.ARCHX64 }
+func (idleClient) IsStreaming() bool                                   { return false }
+func (idleClient) SetFeatures(uint64)                                  {}
+
+/* a peer the kernel reports with pid 0 from outside the pid namespace of the daemon */
+type invisibleCredentials struct{}
+
+func (invisibleCredentials) Get(net.Conn) (*peer.Peer, error) {
+	return nil, msg.NewRPCError(msg.STATUSPERMISSIONDENIED, errors.New("peer is not visible"))
+}
+
+func newTestServer(t *testing.T) (*usecase.Server, *usecase.MsgCodec, string) {
+	t.Helper()
+	return newTestServerWith(t, anonymousCredentials{}, idleClient{})
+}
+
+func newTestServerWith(
+	t *testing.T,
+	credentials peerrepository.Credentials,
+	client grpcclient.GRPCClient) (*usecase.Server, *usecase.MsgCodec, string) {
+	t.Helper()
+	limits := limit.NewDefaultLimits()
+	codec, err := x64msg.NewX64MsgCodec(limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := usecase.NewServer(
+		func(context.Context, *peer.Peer) grpcclient.GRPCClient { return client },
+		credentials,
+		peer.Policy{},
+		nil, nil, nil, nil, nil, nil,
+		codec,
+		limits,
+		time.Minute)
+	addr := filepath.Join(t.TempDir(), "sigrpcd.sock")
+	sock, err := net.Listen("unix", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(sock)
+	return server, codec, addr
+}
+
+func testHello() *msg.HelloMsg {
+	return &msg.HelloMsg{
+		X64: &x64.HelloMsg{
+			Header: &x64.RPCHeader{
+				MsgType:  msg.HELLO,
+				ClientId: "test-" + strconv.FormatInt(int64(os.Getpid()), 16),
+			},
+			Version:  msg.PROTOCOLVERSION,
+			Arch:     msg.ARCHX64,
+			PageSize: uint32(os.Getpagesize()),
+			Features: msg.FEATUREERRORREPLY,
+		},
+	}
+}
+
+/* the reply header and the error message it carries, if any */
+func roundTrip(t *testing.T, conn net.Conn, codec *usecase.MsgCodec, frame []byte) (*msg.RPCHeader, string) {
+	t.Helper()
+	if _, err := conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+	header, err := codec.RPCHeaderCodec.Decode(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := make([]byte, header.X64.PayloadSize)
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		t.Fatal(err)
+	}
+	return header, string(payload)
+}
+
+/* a frame of a message type no daemon knows */
+func unsupportedFrame(codec *usecase.MsgCodec) []byte {
+	return codec.RPCHeaderCodec.Encode(&msg.RPCHeader{
+		X64: &x64.RPCHeader{
+			MsgType:  0xff,
+			ClientId: "test-" + strconv.FormatInt(int64(os.Getpid()), 16),
+		},
+	})
+}
+
+func TestHelloRefusesIncompatiblePeers(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*x64.HelloMsg)
+	}{
+		{"version too old", func(hello *x64.HelloMsg) { hello.Version = msg.MINPROTOCOLVERSION - 1 }},
+		{"version too new", func(hello *x64.HelloMsg) { hello.Version = msg.PROTOCOLVERSION + 1 }},
+		{"other architecture", func(hello *x64.HelloMsg) { hello.Arch = msg.ARCHX64 + 1 }},
+		{"page size below the base one", func(hello *x64.HelloMsg) { hello.PageSize = uint32(os.Getpagesize()) / 2 }},
+		{"page size not a power of two", func(hello *x64.HelloMsg) { hello.PageSize = uint32(os.Getpagesize()) * 3 }},
+		{"page size over the content limit", func(hello *x64.HelloMsg) { hello.PageSize = 1 << 30 }},
+		{"refused even without error replies", func(hello *x64.HelloMsg) {
+			hello.Version = msg.PROTOCOLVERSION + 1
+			hello.Features = 0
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, codec, addr := newTestServer(t)
+			conn, err := net.Dial("unix", addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			hello := testHello()
+			tt.modify(hello.X64)
+			header, message := roundTrip(t, conn, codec, codec.HelloCodec.Encode(hello))
+			if header.X64.Status != msg.STATUSFAILEDPRECONDITION || len(message) == 0 {
+				t.Fatalf("HELLO answered status %d %q", header.X64.Status, message)
+			}
+		})
+	}
+}
+
+func TestHelloRefusesInvisiblePeers(t *testing.T) {
+	_, codec, addr := newTestServerWith(t, invisibleCredentials{}, idleClient{})
+	conn, err := net.Dial("unix", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	header, message := roundTrip(t, conn, codec, codec.HelloCodec.Encode(testHello()))
+	if header.X64.Status != msg.STATUSPERMISSIONDENIED || len(message) == 0 {
+		t.Fatalf("HELLO answered status %d %q", header.X64.Status, message)
+	}
+	/* the unread payload of HELLO may turn the close into a reset */
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if n, err := conn.Read(make([]byte, 1)); err == nil || errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("read %d bytes (%v), want the connection closed", n, err)
+	}
+}
+
+func TestErrorRepliesFollowSession(t *testing.T) {
+	tests := []struct {
+		name     string
+		hello    bool
+		features uint64
+		replied  bool
+	}{
+		{"legacy client", false, 0, false},
+		{"HELLO without error replies", true, 0, false},
+		{"HELLO with error replies", true, msg.FEATUREERRORREPLY, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, codec, addr := newTestServer(t)
+			conn, err := net.Dial("unix", addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			if tt.hello {
+				hello := testHello()
+				hello.X64.Features = tt.features
+				if header, message := roundTrip(t, conn, codec, codec.HelloCodec.Encode(hello)); header.X64.Status != msg.STATUSOK {
+					t.Fatalf("HELLO failed with status %d %q", header.X64.Status, message)
+				}
+			}
+			if !tt.replied {
+				if _, err := conn.Write(unsupportedFrame(codec)); err != nil {
+					t.Fatal(err)
+				}
+				conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+				if n, err := conn.Read(make([]byte, 1)); err != io.EOF {
+					t.Fatalf("read %d bytes (%v), want the connection closed", n, err)
+				}
+				return
+			}
+			header, message := roundTrip(t, conn, codec, unsupportedFrame(codec))
+			if header.X64.Status != msg.STATUSUNIMPLEMENTED || len(message) == 0 {
+				t.Fatalf("answered status %d %q", header.X64.Status, message)
+			}
+		})
+	}
+}
+
+func TestServerReturnsOnClose(t *testing.T) {
+	tests := []struct {
+		name  string
+		hello bool
+	}{
+		{"close before any message", false},
+		{"close after HELLO", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, codec, addr := newTestServer(t)
+			conn, err := net.Dial("unix", addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.hello {
+				header, _ := roundTrip(t, conn, codec, codec.HelloCodec.Encode(testHello()))
+				if header.X64.Status != msg.STATUSOK {
+					t.Fatalf("HELLO failed with status %d", header.X64.Status)
+				}
+			}
+			/* only the sending side is closed, so that replies to nothing still succeed and could loop */
+			if err := conn.(*net.UnixConn).CloseWrite(); err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			/* Shutdown only returns nil once every connection goroutine is gone */
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			if err := server.Shutdown(ctx); err != nil {
+				t.Fatalf("connection goroutine did not exit: %v", err)
+			}
+		})
+	}
+}
+
+/* a stub whose LOADLIB answers only once released */
+type slowClient struct {
+	idleClient
+	started  chan struct{}
+	released chan struct{}
+}
+
+func (c slowClient) LoadLib(req *msg.LoadLibMsg) (*msg.LoadLibMsg, error) {
+	close(c.started)
+	<-c.released
+	return &msg.LoadLibMsg{X64: &x64.LoadLibMsg{Header: req.X64.Header}}, nil
+}
+
+func loadLibFrame(codec *usecase.MsgCodec) []byte {
+	return codec.LoadLibCodec.Encode(&msg.LoadLibMsg{
+		X64: &x64.LoadLibMsg{
+			Header: &x64.RPCHeader{
+				MsgType:  msg.LOADLIB,
+				ClientId: "test-" + strconv.FormatInt(int64(os.Getpid()), 16),
+			},
+			LibraryName: "libtest.so",
+		},
+	})
+}
+
+func TestShutdownDrainsInFlightRequests(t *testing.T) {
+	client := slowClient{started: make(chan struct{}), released: make(chan struct{})}
+	server, codec, addr := newTestServerWith(t, anonymousCredentials{}, client)
+	hello := func() net.Conn {
+		conn, err := net.Dial("unix", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		if header, message := roundTrip(t, conn, codec, codec.HelloCodec.Encode(testHello())); header.X64.Status != msg.STATUSOK {
+			t.Fatalf("HELLO failed with status %d %q", header.X64.Status, message)
+		}
+		return conn
+	}
+	inFlight := hello()
+	idle := hello()
+	if _, err := inFlight.Write(loadLibFrame(codec)); err != nil {
+		t.Fatal(err)
+	}
+	<-client.started
+
+	done := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		done <- server.Shutdown(ctx)
+	}()
+	/* draining starts with the listeners closed */
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		conn, err := net.Dial("unix", addr)
+		if err != nil {
+			break
+		}
+		conn.Close()
+		if time.Now().After(deadline) {
+			t.Fatal("listener is still accepting")
+		}
+	}
+
+	header, message := roundTrip(t, idle, codec, loadLibFrame(codec))
+	if header.X64.Status != msg.STATUSUNAVAILABLE || !strings.Contains(message, "shutting down") {
+		t.Fatalf("request while draining answered status %d %q", header.X64.Status, message)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("Shutdown returned before the request in flight: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(client.released)
+	inFlight.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reply, err := codec.RPCHeaderCodec.Decode(inFlight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.X64.Status != msg.STATUSOK {
+		t.Fatalf("request in flight answered status %d", reply.X64.Status)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shutdown did not return after the request in flight")
+	}
+}
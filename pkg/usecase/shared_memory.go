@@ -0,0 +1,46 @@
+// Copyright 2025 Keita HAGIWARA. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usecase
+
+import (
+	"net"
+
+	"github.com/sigrpc/sigrpcd/pkg/domain/model/page"
+	shmrepository "github.com/sigrpc/sigrpcd/pkg/domain/repository/shm"
+)
+
+type SharedMemory struct {
+	shmrepository.SharedMemory
+}
+
+func NewSharedMemory(sharedMemory shmrepository.SharedMemory) *SharedMemory {
+	return &SharedMemory{sharedMemory}
+}
+
+func (s *SharedMemory) Accept(conn net.Conn) net.Conn {
+	return s.SharedMemory.Accept(conn)
+}
+
+func (s *SharedMemory) Map(conn net.Conn) (*page.Shared, error) {
+	return s.SharedMemory.Map(conn)
+}
+
+func (s *SharedMemory) Unmap(conn net.Conn) {
+	s.SharedMemory.Unmap(conn)
+}
+
+func (s *SharedMemory) Refuse(conn net.Conn) {
+	s.SharedMemory.Refuse(conn)
+}
@@ -29,10 +29,10 @@ func NewUserContextCodec(ctx ucontextcodec.UserContext) UserContextCodec {
 	return UserContextCodec{ctx}
 }
 
-func (h *UserContextCodec) Encode(ctx *ucontext.UserContext) []byte {
-	return h.UserContext.Encode(ctx)
+func (h *UserContextCodec) Encode(ctx *ucontext.UserContext, xstateSize uint32) []byte {
+	return h.UserContext.Encode(ctx, xstateSize)
 }
 
-func (h *UserContextCodec) Decode(reader io.Reader) *ucontext.UserContext {
-	return h.UserContext.Decode(reader)
+func (h *UserContextCodec) Decode(reader io.Reader, xstateSize uint32) (*ucontext.UserContext, error) {
+	return h.UserContext.Decode(reader, xstateSize)
 }